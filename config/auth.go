@@ -0,0 +1,162 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccessTokenTTL adalah masa berlaku access token (ACCESS_TOKEN_TTL, default 15 menit)
+func AccessTokenTTL() time.Duration {
+	return durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// RefreshTokenTTL adalah masa berlaku refresh token sejak terakhir dipakai
+// (REFRESH_TOKEN_TTL, default 7 hari)
+func RefreshTokenTTL() time.Duration {
+	return durationEnv("REFRESH_TOKEN_TTL", 7*24*time.Hour)
+}
+
+// ResetPasswordTTL adalah masa berlaku token reset password (RESET_PASSWORD_TTL, default 1 jam)
+func ResetPasswordTTL() time.Duration {
+	return durationEnv("RESET_PASSWORD_TTL", time.Hour)
+}
+
+// VerifikasiEmailTTL adalah masa berlaku token verifikasi email
+// (EMAIL_VERIFICATION_TTL, default 48 jam)
+func VerifikasiEmailTTL() time.Duration {
+	return durationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+}
+
+// FrontendURL adalah alamat aplikasi frontend untuk link di email (FRONTEND_URL).
+// Jika kosong, email hanya berisi token.
+func FrontendURL() string {
+	return strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")
+}
+
+// LoginMaksGagal adalah jumlah login gagal per akun sebelum akun dikunci
+// sementara (LOGIN_MAX_ATTEMPTS, default 5)
+func LoginMaksGagal() int {
+	return intEnv("LOGIN_MAX_ATTEMPTS", 5)
+}
+
+// LoginMaksGagalIP adalah jumlah login gagal per alamat IP sebelum IP dikunci
+// sementara (LOGIN_MAX_ATTEMPTS_IP, default 20). Lebih longgar dari batas per
+// akun karena satu IP bisa dipakai banyak user di balik NAT.
+func LoginMaksGagalIP() int {
+	return intEnv("LOGIN_MAX_ATTEMPTS_IP", 20)
+}
+
+// LoginJendela adalah rentang waktu login gagal dihitung; hitungan dimulai
+// ulang jika tidak ada login gagal selama rentang ini (LOGIN_ATTEMPT_WINDOW, default 15 menit)
+func LoginJendela() time.Duration {
+	return durationEnv("LOGIN_ATTEMPT_WINDOW", 15*time.Minute)
+}
+
+// LoginLamaKunci adalah lama akun atau IP dikunci setelah melewati batas
+// (LOGIN_LOCKOUT_DURATION, default 15 menit)
+func LoginLamaKunci() time.Duration {
+	return durationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+}
+
+// Wajib2FA bernilai true jika role ada di REQUIRE_2FA_ROLES (daftar nama role
+// dipisah koma, mis. "admin"). User dengan role tersebut tidak mendapat
+// permission apa pun sebelum mengaktifkan 2FA dan login dengan kode 2FA.
+func Wajib2FA(role string) bool {
+	for _, r := range strings.Split(os.Getenv("REQUIRE_2FA_ROLES"), ",") {
+		if r = strings.TrimSpace(r); r != "" && r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Tantangan2FATTL adalah masa berlaku challenge token antara cek password dan
+// cek kode 2FA (TWO_FACTOR_CHALLENGE_TTL, default 5 menit)
+func Tantangan2FATTL() time.Duration {
+	return durationEnv("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute)
+}
+
+// TOTPIssuer adalah nama aplikasi yang tampil di aplikasi authenticator
+// (TOTP_ISSUER, default "Inventory")
+func TOTPIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Inventory"
+}
+
+// intEnv membaca bilangan bulat positif dari environment
+func intEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: %s tidak valid (%q), memakai default %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// durationEnv membaca durasi format Go (mis. "15m", "168h") dari environment
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Warning: %s tidak valid (%q), memakai default %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// RegistrasiDibuka bernilai false jika REGISTRATION_ENABLED=false, sehingga
+// akun baru hanya bisa dibuat oleh admin
+func RegistrasiDibuka() bool {
+	return !strings.EqualFold(os.Getenv("REGISTRATION_ENABLED"), "false")
+}
+
+// OIDCRoleDariGroup memetakan grup dari identity provider ke role aplikasi
+// memakai OIDC_ROLE_MAPPING, daftar "grup:role" dipisah koma
+// (mis. "it-admin:admin,gudang:petugas_gudang"). Pasangan pertama yang grupnya dimiliki
+// user dipakai. Mengembalikan string kosong jika tidak ada yang cocok.
+func OIDCRoleDariGroup(groups []string) string {
+	for _, pasangan := range strings.Split(os.Getenv("OIDC_ROLE_MAPPING"), ",") {
+		group, role, ok := strings.Cut(pasangan, ":")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			continue
+		}
+		for _, g := range groups {
+			if g == group {
+				return role
+			}
+		}
+	}
+	return ""
+}
+
+// OIDCDefaultRole adalah role untuk user baru dari SSO yang grupnya tidak ada
+// di OIDC_ROLE_MAPPING (OIDC_DEFAULT_ROLE, default "user")
+func OIDCDefaultRole() string {
+	if role := strings.TrimSpace(os.Getenv("OIDC_DEFAULT_ROLE")); role != "" {
+		return role
+	}
+	return "user"
+}
+
+// OIDCAutoProvision menentukan apakah login SSO boleh membuat akun baru untuk
+// email yang belum terdaftar (OIDC_AUTO_PROVISION, default true)
+func OIDCAutoProvision() bool {
+	v, err := strconv.ParseBool(os.Getenv("OIDC_AUTO_PROVISION"))
+	if err != nil {
+		return true
+	}
+	return v
+}
@@ -2,9 +2,11 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,11 +15,17 @@ import (
 
 var DB *mongo.Database
 
-func ConnectDB() {
+// UseMemoryStorage bernilai true jika STORAGE_DRIVER=memory, sehingga API
+// berjalan tanpa MongoDB (untuk testing dan demo)
+func UseMemoryStorage() bool {
+	return strings.EqualFold(os.Getenv("STORAGE_DRIVER"), "memory")
+}
+
+func ConnectDB() error {
 	// Get MongoDB URI from environment variables
 	mongoURI := os.Getenv("MONGODB_URI")
 	if mongoURI == "" {
-		log.Fatal("MONGODB_URI environment variable is required")
+		return errors.New("MONGODB_URI environment variable is required (atau set STORAGE_DRIVER=memory)")
 	}
 
 	// Get database name from environment variables
@@ -46,18 +54,19 @@ func ConnectDB() {
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		log.Printf("Failed to create MongoDB client: %v", err)
-		log.Fatal("error parsing uri: ", err)
+		return fmt.Errorf("error parsing uri: %w", err)
 	}
 
 	// Test the connection
 	err = client.Ping(ctx, nil)
 	if err != nil {
 		log.Printf("Failed to ping MongoDB: %v", err)
-		log.Fatal("error connecting to MongoDB: ", err)
+		return fmt.Errorf("error connecting to MongoDB: %w", err)
 	}
 
 	DB = client.Database(dbName)
 	fmt.Printf("✅ MongoDB Atlas connected successfully! Database: %s\n", dbName)
+	return nil
 }
 
 // Helper function to mask password in URI for logging
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// ProxyHeader adalah header berisi IP asli client jika server berjalan di
+// belakang reverse proxy (PROXY_HEADER, mis. "X-Real-IP"). Hanya isi jika proxy
+// selalu menimpa header tersebut, karena client bisa mengirim header palsu.
+// Jika kosong, IP diambil dari koneksi TCP.
+func ProxyHeader() string {
+	return os.Getenv("PROXY_HEADER")
+}
+
+// CekProxyHeader menolak start di APP_ENV=production tanpa PROXY_HEADER. Di
+// production server berjalan di belakang proxy, sehingga tanpa header tersebut
+// semua request terlihat dari IP proxy dan batas login gagal per IP akan
+// mengunci login semua orang sekaligus.
+func CekProxyHeader() error {
+	if strings.EqualFold(os.Getenv("APP_ENV"), "production") && ProxyHeader() == "" {
+		return errors.New("PROXY_HEADER wajib diisi jika APP_ENV=production agar batas login per IP memakai IP client, bukan IP proxy")
+	}
+	return nil
+}
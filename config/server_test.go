@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestCekProxyHeader(t *testing.T) {
+	tests := []struct {
+		env   string
+		proxy string
+		gagal bool
+	}{
+		{"production", "", true},
+		{"Production", "", true},
+		{"production", "X-Real-IP", false},
+		{"development", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("APP_ENV", tt.env)
+		t.Setenv("PROXY_HEADER", tt.proxy)
+		if err := CekProxyHeader(); (err != nil) != tt.gagal {
+			t.Errorf("APP_ENV=%q PROXY_HEADER=%q: err = %v, ingin gagal %v", tt.env, tt.proxy, err, tt.gagal)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// TrashRetensi adalah lama data disimpan di tempat sampah sebelum dihapus
+// permanen (TRASH_RETENTION_DAYS, default 30 hari). Nilai 0 mematikan
+// pembersihan otomatis.
+func TrashRetensi() time.Duration {
+	const hari = 24 * time.Hour
+	value := os.Getenv("TRASH_RETENTION_DAYS")
+	if value == "" {
+		return 30 * hari
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("Warning: TRASH_RETENTION_DAYS tidak valid (%q), memakai default 30", value)
+		return 30 * hari
+	}
+	return time.Duration(n) * hari
+}
+
+// TrashIntervalPembersihan adalah jarak antar pembersihan tempat sampah
+// (TRASH_PURGE_INTERVAL, default 1 jam)
+func TrashIntervalPembersihan() time.Duration {
+	return durationEnv("TRASH_PURGE_INTERVAL", time.Hour)
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"inventory-backend/validators"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateAPIKey godoc
+// @Summary Buat API key
+// @Description Membuat API key untuk script dan integrasi, dipakai lewat header X-API-Key. Scope hanya boleh berisi permission yang dimiliki role user. Kunci hanya ditampilkan sekali di response ini.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.CreateAPIKeyRequest true "Nama, scope dan waktu kedaluwarsa"
+// @Success 201 {object} map[string]interface{} "API key berhasil dibuat"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Scope melebihi permission user"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/api-keys [post]
+func CreateAPIKey(c *fiber.Ctx) error {
+	var body models.CreateAPIKeyRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if err := validators.ValidateAPIKey(body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	scopes := normalisasiPermissions(body.Scopes)
+	for _, scope := range scopes {
+		if !middlewares.HasPermission(c, scope) {
+			return c.Status(403).JSON(fiber.Map{"error": "Anda tidak memiliki permission " + scope})
+		}
+	}
+
+	key := models.APIKey{
+		ID:        primitive.NewObjectID(),
+		UserID:    currentUserID(c),
+		Nama:      strings.TrimSpace(body.Nama),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: body.ExpiresAt,
+	}
+	rawKey, err := services.BuatAPIKey(key.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat API key"})
+	}
+	key.KeyHash = services.HashAPIKey(rawKey)
+
+	if err := apiKeyRepo.Create(context.Background(), &key); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := catatAudit(c, models.AuditBuatAPIKey, map[string]interface{}{
+		"api_key_id": key.ID,
+		"nama":       key.Nama,
+		"scopes":     key.Scopes,
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "API key berhasil dibuat. Simpan kunci ini, kunci tidak akan ditampilkan lagi",
+		"key":     rawKey,
+		"data":    key,
+	})
+}
+
+// GetAPIKeys godoc
+// @Summary Daftar API key
+// @Description Mengambil semua API key milik user yang sedang login beserta waktu pemakaian terakhir. Kunci tidak pernah ditampilkan ulang.
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/api-keys [get]
+func GetAPIKeys(c *fiber.Ctx) error {
+	keys, err := apiKeyRepo.FindByUser(context.Background(), currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary Cabut API key
+// @Description Mencabut API key milik user yang sedang login; request dengan kunci ini langsung ditolak
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} map[string]interface{} "API key dicabut"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "API key tidak ditemukan"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/api-keys/{id} [delete]
+func RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	err = apiKeyRepo.Revoke(context.Background(), id, currentUserID(c))
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "API key tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := catatAudit(c, models.AuditCabutAPIKey, map[string]interface{}{"api_key_id": id}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "API key berhasil dicabut"})
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestAPIKey membuat API key lewat endpoint lalu memakainya di header
+// X-API-Key. Permission kunci adalah scope yang masih dimiliki role user,
+// kunci disimpan dalam bentuk hash, dan kunci yang dicabut langsung ditolak.
+func TestAPIKey(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	SetRepositories(repos)
+	middlewares.SetRepositories(repos)
+	ctx := context.Background()
+
+	role := models.Role{ID: primitive.NewObjectID(), Nama: "petugas", Permissions: []string{models.PermBarangWrite, models.PermLaporanRead}}
+	if err := roleRepo.Create(ctx, &role); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: role.Nama}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	kelola := appPengguna(user.ID, role.Permissions...)
+	kelola.Post("/auth/api-keys", CreateAPIKey)
+	kelola.Get("/auth/api-keys", GetAPIKeys)
+	kelola.Delete("/auth/api-keys/:id", RevokeAPIKey)
+
+	if status, body := kirimJSON(t, kelola, "POST", "/auth/api-keys", `{"nama":"printer","scopes":["`+models.PermUsersManage+`"]}`); status != 403 {
+		t.Errorf("scope melebihi permission: status = %d, body = %v, ingin 403", status, body)
+	}
+	status, body := kirimJSON(t, kelola, "POST", "/auth/api-keys", `{"nama":"printer","scopes":["barang:write","laporan:read"]}`)
+	if status != 201 {
+		t.Fatalf("buat API key: status = %d, body = %v", status, body)
+	}
+	rawKey := body["key"].(string)
+	id := body["data"].(map[string]interface{})["id"].(string)
+
+	keyID, _ := primitive.ObjectIDFromHex(id)
+	tersimpan, err := apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tersimpan.KeyHash == rawKey || tersimpan.KeyHash != services.HashAPIKey(rawKey) {
+		t.Errorf("key_hash = %q, ingin hash dari kunci", tersimpan.KeyHash)
+	}
+
+	// Setelah kunci dibuat role kehilangan laporan:read
+	role.Permissions = []string{models.PermBarangWrite}
+	if err := roleRepo.Update(ctx, &role); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/whoami", middlewares.JWTMiddleware, func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"user_id": c.Locals("user_id"), "permissions": c.Locals("permissions")})
+	})
+	app.Post("/auth/logout", middlewares.JWTMiddleware, middlewares.RequireSesi, func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+	pakai := func(method, path, key string) (int, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hasil map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&hasil)
+		return resp.StatusCode, hasil
+	}
+
+	status, body = pakai("GET", "/whoami", rawKey)
+	if status != 200 {
+		t.Fatalf("pakai API key: status = %d, body = %v", status, body)
+	}
+	perms, _ := body["permissions"].([]interface{})
+	if body["user_id"] != user.ID.Hex() || len(perms) != 1 || perms[0] != models.PermBarangWrite {
+		t.Errorf("locals = %v, ingin user %s dengan permission barang:write saja", body, user.ID.Hex())
+	}
+	if k, _ := apiKeyRepo.FindByID(ctx, keyID); k.LastUsedAt == nil {
+		t.Error("last_used_at tidak dicatat")
+	}
+
+	if status, _ := pakai("POST", "/auth/logout", rawKey); status != 403 {
+		t.Errorf("endpoint sesi dengan API key: status = %d, ingin 403", status)
+	}
+	if status, _ := pakai("GET", "/whoami", rawKey+"x"); status != 401 {
+		t.Errorf("kunci salah: status = %d, ingin 401", status)
+	}
+
+	// Daftar kunci tidak pernah memuat kunci maupun hash-nya
+	resp, err := kelola.Test(httptest.NewRequest("GET", "/auth/api-keys", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var daftar []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&daftar); err != nil {
+		t.Fatal(err)
+	}
+	if len(daftar) != 1 || daftar[0]["id"] != id || daftar[0]["last_used_at"] == nil {
+		t.Errorf("daftar API key = %v, ingin satu kunci %s dengan last_used_at", daftar, id)
+	}
+	for field := range daftar[0] {
+		if field == "key" || field == "key_hash" {
+			t.Errorf("daftar API key memuat %s", field)
+		}
+	}
+
+	if status, body := kirimJSON(t, kelola, "DELETE", "/auth/api-keys/"+id, ""); status != 200 {
+		t.Fatalf("cabut API key: status = %d, body = %v", status, body)
+	}
+	if status, _ := pakai("GET", "/whoami", rawKey); status != 401 {
+		t.Errorf("kunci dicabut: status = %d, ingin 401", status)
+	}
+
+	// Kunci yang sudah kedaluwarsa juga ditolak
+	lewat := time.Now().Add(-time.Minute)
+	kedaluwarsa := models.APIKey{ID: primitive.NewObjectID(), UserID: user.ID, Nama: "lama", Scopes: []string{models.PermBarangWrite}, ExpiresAt: &lewat}
+	rawLama, err := services.BuatAPIKey(kedaluwarsa.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kedaluwarsa.KeyHash = services.HashAPIKey(rawLama)
+	if err := apiKeyRepo.Create(ctx, &kedaluwarsa); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := pakai("GET", "/whoami", rawLama); status != 401 {
+		t.Errorf("kunci kedaluwarsa: status = %d, ingin 401", status)
+	}
+
+	if keys, _ := apiKeyRepo.FindByUser(ctx, user.ID); !slices.ContainsFunc(keys, func(k models.APIKey) bool { return k.ID == keyID && k.RevokedAt != nil }) {
+		t.Errorf("API key %s tidak tercatat dicabut: %+v", id, keys)
+	}
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// auditBaru mengisi data pelaku dan request untuk audit log aksi
+func auditBaru(c *fiber.Ctx, aksi string) models.AuditLog {
+	log := models.AuditLog{
+		Aksi:      aksi,
+		IP:        strings.Clone(c.IP()),
+		UserAgent: strings.Clone(c.Get("User-Agent")),
+		Tanggal:   time.Now(),
+	}
+	if requestID, ok := c.Locals("requestid").(string); ok {
+		log.RequestID = strings.Clone(requestID)
+	}
+	if userID := currentUserID(c); !userID.IsZero() {
+		log.UserID = &userID
+	}
+	return log
+}
+
+// catatAudit menulis audit log untuk request yang sedang berjalan
+func catatAudit(c *fiber.Ctx, aksi string, detail map[string]interface{}) error {
+	log := auditBaru(c, aksi)
+	log.Detail = detail
+	return auditRepo.Create(context.Background(), &log)
+}
+
+// catatPerubahan menulis audit log perubahan satu resource. sebelum bernilai nil
+// untuk data baru dan sesudah bernilai nil untuk data yang dihapus. ctx harus
+// ctx transaksi jika dipanggil di dalam WithTransaction agar log ikut batal
+// bersama perubahannya.
+func catatPerubahan(ctx context.Context, c *fiber.Ctx, aksi, resource string, id primitive.ObjectID, sebelum, sesudah interface{}, detail map[string]interface{}) error {
+	log := auditBaru(c, aksi)
+	log.Resource = resource
+	log.ResourceID = &id
+	log.Detail = detail
+
+	var err error
+	if log.Sebelum, err = snapshot(sebelum); err != nil {
+		return err
+	}
+	if log.Sesudah, err = snapshot(sesudah); err != nil {
+		return err
+	}
+	if log.Sebelum != nil && log.Sesudah != nil {
+		log.Perubahan = bedaSnapshot(log.Sebelum, log.Sesudah)
+	}
+	return auditRepo.Create(ctx, &log)
+}
+
+// snapshot mengubah resource menjadi map dengan bentuk yang sama seperti
+// response API. Field yang disembunyikan dari JSON tidak ikut tercatat dan
+// hash password selalu dibuang.
+func snapshot(v interface{}) (map[string]interface{}, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var hasil map[string]interface{}
+	if err := json.Unmarshal(data, &hasil); err != nil {
+		return nil, err
+	}
+	delete(hasil, "password")
+	return hasil, nil
+}
+
+// bedaSnapshot mengembalikan field tingkat atas yang nilainya berbeda
+func bedaSnapshot(sebelum, sesudah map[string]interface{}) map[string]models.Perubahan {
+	beda := map[string]models.Perubahan{}
+	for field, dari := range sebelum {
+		if ke := sesudah[field]; !reflect.DeepEqual(dari, ke) {
+			beda[field] = models.Perubahan{Dari: dari, Ke: ke}
+		}
+	}
+	for field, ke := range sesudah {
+		if _, ada := sebelum[field]; !ada {
+			beda[field] = models.Perubahan{Dari: nil, Ke: ke}
+		}
+	}
+	return beda
+}
+
+// GetAuditLogs godoc
+// @Summary Daftar audit log
+// @Description Mengambil audit log, yang terbaru lebih dulu. Catatan perubahan data berisi snapshot sebelum dan sesudah serta field yang berubah. Semua catatan dari satu request bisa diambil dengan request_id (header X-Request-ID). Nama, email dan telepon peminjam di catatan peminjaman disamarkan tanpa permission laporan:pii.
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query string false "Filter pelaku"
+// @Param aksi query string false "Filter aksi, mis. barang.diubah"
+// @Param resource query string false "Filter jenis resource: barang, kategori, peminjaman, user atau role"
+// @Param resource_id query string false "Filter ID resource"
+// @Param request_id query string false "Filter request ID"
+// @Param dari query string false "Tanggal awal (YYYY-MM-DD, inklusif)"
+// @Param sampai query string false "Tanggal akhir (YYYY-MM-DD, inklusif)"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID audit log terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Daftar audit log"
+// @Failure 400 {object} map[string]interface{} "Parameter tidak valid"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /audit [get]
+func GetAuditLogs(c *fiber.Ctx) error {
+	var filter repository.AuditFilter
+	if err := isiFilterAudit(c, &filter); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return daftarAudit(c, filter)
+}
+
+// GetBarangHistory godoc
+// @Summary Riwayat perubahan barang
+// @Description Mengambil semua perubahan satu barang dari audit log, yang terbaru lebih dulu, termasuk barang yang sudah dihapus
+// @Tags Barang
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Barang ID"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID audit log terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Riwayat perubahan barang"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /barang/{id}/history [get]
+func GetBarangHistory(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	return daftarAudit(c, repository.AuditFilter{Resource: models.ResourceBarang, ResourceID: &id})
+}
+
+// isiFilterAudit membaca query filter GetAuditLogs
+func isiFilterAudit(c *fiber.Ctx, filter *repository.AuditFilter) error {
+	for _, q := range []struct {
+		nama  string
+		nilai **primitive.ObjectID
+	}{
+		{"user_id", &filter.UserID},
+		{"resource_id", &filter.ResourceID},
+	} {
+		v := c.Query(q.nama)
+		if v == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return errors.New(q.nama + " tidak valid")
+		}
+		*q.nilai = &id
+	}
+	filter.Aksi = strings.TrimSpace(c.Query("aksi"))
+	filter.Resource = strings.TrimSpace(c.Query("resource"))
+	filter.RequestID = strings.TrimSpace(c.Query("request_id"))
+
+	for _, q := range []struct {
+		nama  string
+		nilai *time.Time
+		hari  int
+	}{
+		{"dari", &filter.Dari, 0},
+		// sampai inklusif, jadi batasnya awal hari berikutnya
+		{"sampai", &filter.Sampai, 1},
+	} {
+		v := c.Query(q.nama)
+		if v == "" {
+			continue
+		}
+		tanggal, err := time.ParseInLocation(models.FormatTanggal, v, time.Local)
+		if err != nil {
+			return errors.New(q.nama + " harus berformat YYYY-MM-DD")
+		}
+		*q.nilai = tanggal.AddDate(0, 0, q.hari)
+	}
+	return nil
+}
+
+// daftarAudit mengirim audit log yang cocok dengan filter dengan pagination
+func daftarAudit(c *fiber.Ctx, filter repository.AuditFilter) error {
+	opts, err := parseListOptions(c, map[string]string{"tanggal": "tanggal"})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	logs, total, err := auditRepo.FindAll(context.Background(), filter, opts)
+	if err != nil {
+		return errorList(c, err, "")
+	}
+	if !middlewares.HasPermission(c, models.PermLaporanPII) {
+		for i := range logs {
+			samarkanAudit(&logs[i])
+		}
+	}
+	return c.JSON(paginated(c, logs, opts, total, func(l models.AuditLog) primitive.ObjectID { return l.ID }))
+}
+
+// samarkanAudit menyamarkan data pribadi peminjam di snapshot peminjaman
+// dengan cara yang sama seperti laporan. Map disalin dulu supaya data yang
+// tersimpan tidak ikut berubah.
+func samarkanAudit(log *models.AuditLog) {
+	if log.Resource != models.ResourcePeminjaman {
+		return
+	}
+	log.Sebelum = samarkanSnapshot(log.Sebelum)
+	log.Sesudah = samarkanSnapshot(log.Sesudah)
+	if log.Perubahan == nil {
+		return
+	}
+	perubahan := make(map[string]models.Perubahan, len(log.Perubahan))
+	for field, p := range log.Perubahan {
+		dari := samarkanSnapshot(bson.M{field: p.Dari})
+		ke := samarkanSnapshot(bson.M{field: p.Ke})
+		perubahan[field] = models.Perubahan{Dari: dari[field], Ke: ke[field]}
+	}
+	log.Perubahan = perubahan
+}
+
+// samarkanSnapshot mengembalikan salinan snapshot dengan data pribadi peminjam disamarkan
+func samarkanSnapshot(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	salinan := make(bson.M, len(data))
+	for k, v := range data {
+		salinan[k] = v
+	}
+	samarkanBaris(salinan)
+	return salinan
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestAuditPeminjamanDisamarkan memastikan data pribadi peminjam di snapshot
+// audit hanya terlihat utuh bagi pemegang laporan:pii, dan data tersimpan
+// tidak ikut tersamarkan.
+func TestAuditPeminjamanDisamarkan(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+
+	id := primitive.NewObjectID()
+	log := models.AuditLog{
+		Aksi:       "peminjaman.diubah",
+		Resource:   models.ResourcePeminjaman,
+		ResourceID: &id,
+		Sebelum:    map[string]interface{}{"nama_peminjam": "Budi Santoso", "email_peminjam": "budi@contoh.com", "telepon_peminjam": "081234567890", "status": "diajukan"},
+		Sesudah:    map[string]interface{}{"nama_peminjam": "Budi Santoso", "email_peminjam": "budi.s@contoh.com", "telepon_peminjam": "081234567890", "status": "disetujui"},
+		Perubahan: map[string]models.Perubahan{
+			"email_peminjam": {Dari: "budi@contoh.com", Ke: "budi.s@contoh.com"},
+			"status":         {Dari: "diajukan", Ke: "disetujui"},
+		},
+		Tanggal: time.Now(),
+	}
+	if err := auditRepo.Create(ctx, &log); err != nil {
+		t.Fatal(err)
+	}
+
+	ambil := func(perms ...string) map[string]interface{} {
+		t.Helper()
+		app := appPengguna(primitive.NewObjectID(), perms...)
+		app.Get("/audit", GetAuditLogs)
+		status, body := kirimJSON(t, app, "GET", "/audit?resource=peminjaman", "")
+		if status != 200 {
+			t.Fatalf("status = %d, ingin 200 (%v)", status, body)
+		}
+		data, _ := body["data"].([]interface{})
+		if len(data) != 1 {
+			t.Fatalf("jumlah log = %d, ingin 1", len(data))
+		}
+		return data[0].(map[string]interface{})
+	}
+
+	samar := ambil(models.PermAuditRead)
+	sebelum := samar["sebelum"].(map[string]interface{})
+	if sebelum["nama_peminjam"] != "B*** S***" || sebelum["email_peminjam"] != "b***@contoh.com" || sebelum["telepon_peminjam"] != "0812******90" {
+		t.Errorf("snapshot sebelum tidak disamarkan: %v", sebelum)
+	}
+	if sebelum["status"] != "diajukan" {
+		t.Errorf("status ikut berubah: %v", sebelum["status"])
+	}
+	email := samar["perubahan"].(map[string]interface{})["email_peminjam"].(map[string]interface{})
+	if email["dari"] != "b***@contoh.com" || email["ke"] != "b***@contoh.com" {
+		t.Errorf("perubahan email tidak disamarkan: %v", email)
+	}
+
+	utuh := ambil(models.PermAuditRead, models.PermLaporanPII)
+	sesudah := utuh["sesudah"].(map[string]interface{})
+	if sesudah["email_peminjam"] != "budi.s@contoh.com" || sesudah["telepon_peminjam"] != "081234567890" {
+		t.Errorf("snapshot dengan laporan:pii tersamarkan: %v", sesudah)
+	}
+}
+
+// TestRiwayatBarang mencatat pembuatan, perubahan dan penghapusan barang lewat
+// endpoint, lalu membaca riwayatnya dari /barang/:id/history dan /audit
+func TestRiwayatBarang(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	kategori := models.Kategori{ID: primitive.NewObjectID(), Nama: "Elektronik"}
+	if err := kategoriRepo.Create(ctx, &kategori); err != nil {
+		t.Fatal(err)
+	}
+	lain := siapkanBarang(t, "Kabel", 1)
+
+	petugas := primitive.NewObjectID()
+	app := appPengguna(petugas, models.PermBarangWrite, models.PermAuditRead)
+	app.Use(requestid.New())
+	app.Post("/barang", CreateBarang)
+	app.Put("/barang/:id", UpdateBarang)
+	app.Delete("/barang/:id", DeleteBarang)
+	app.Get("/barang/:id/history", GetBarangHistory)
+	app.Get("/audit", GetAuditLogs)
+	kirim := func(method, path, requestID, body string) map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "uji-audit")
+		req.Header.Set("X-Request-ID", requestID)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hasil map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&hasil)
+		if resp.StatusCode != 200 && resp.StatusCode != 201 {
+			t.Fatalf("%s %s: status = %d, body = %v", method, path, resp.StatusCode, hasil)
+		}
+		return hasil
+	}
+
+	body := kirim("POST", "/barang", "req-buat", `{"nama":"Proyektor","kategori_id":"`+kategori.ID.Hex()+`","stok":2}`)
+	id := body["id"].(string)
+	kirim("PUT", "/barang/"+id, "req-ubah", `{"nama":"Proyektor Epson","kategori_id":"`+kategori.ID.Hex()+`","stok":2}`)
+	kirim("DELETE", "/barang/"+id, "req-hapus", "")
+
+	data := kirim("GET", "/barang/"+id+"/history", "req-baca", "")["data"].([]interface{})
+	ingin := []struct {
+		aksi, requestID          string
+		sebelum, sesudah, diubah bool
+	}{
+		// Terbaru lebih dulu
+		{models.AuditHapusBarang, "req-hapus", true, false, false},
+		{models.AuditUbahBarang, "req-ubah", true, true, true},
+		{models.AuditBuatBarang, "req-buat", false, true, false},
+	}
+	if len(data) != len(ingin) {
+		t.Fatalf("jumlah riwayat = %d, ingin %d: %v", len(data), len(ingin), data)
+	}
+	for i, d := range data {
+		d := d.(map[string]interface{})
+		if d["aksi"] != ingin[i].aksi || d["request_id"] != ingin[i].requestID {
+			t.Errorf("riwayat[%d] = %v %v, ingin %s %s", i, d["aksi"], d["request_id"], ingin[i].aksi, ingin[i].requestID)
+		}
+		if d["user_id"] != petugas.Hex() || d["ip"] != "0.0.0.0" || d["user_agent"] != "uji-audit" || d["resource_id"] != id {
+			t.Errorf("riwayat[%d] pelaku = %v %v %v %v", i, d["user_id"], d["ip"], d["user_agent"], d["resource_id"])
+		}
+		if (d["sebelum"] != nil) != ingin[i].sebelum || (d["sesudah"] != nil) != ingin[i].sesudah || (d["perubahan"] != nil) != ingin[i].diubah {
+			t.Errorf("riwayat[%d] snapshot sebelum/sesudah/perubahan = %v/%v/%v", i, d["sebelum"], d["sesudah"], d["perubahan"])
+		}
+	}
+
+	ubah := data[1].(map[string]interface{})
+	perubahan := ubah["perubahan"].(map[string]interface{})
+	nama, _ := perubahan["nama"].(map[string]interface{})
+	if nama["dari"] != "Proyektor" || nama["ke"] != "Proyektor Epson" {
+		t.Errorf("perubahan nama = %v, ingin Proyektor ke Proyektor Epson", perubahan["nama"])
+	}
+	for _, field := range []string{"kategori_id", "stok"} {
+		if _, ada := perubahan[field]; ada {
+			t.Errorf("perubahan memuat %s yang tidak berubah: %v", field, perubahan[field])
+		}
+	}
+	if sesudah := ubah["sesudah"].(map[string]interface{}); sesudah["nama"] != "Proyektor Epson" || sesudah["stok"] != float64(2) {
+		t.Errorf("snapshot sesudah = %v", sesudah)
+	}
+
+	besok := time.Now().AddDate(0, 0, 1).Format(models.FormatTanggal)
+	hariIni := time.Now().Format(models.FormatTanggal)
+	tests := []struct {
+		query string
+		ingin int
+	}{
+		{"request_id=req-ubah", 1},
+		{"aksi=" + models.AuditHapusBarang, 1},
+		{"resource=barang&resource_id=" + id, 3},
+		{"resource_id=" + lain.ID.Hex(), 0},
+		{"user_id=" + petugas.Hex() + "&dari=" + hariIni + "&sampai=" + hariIni, 3},
+		{"user_id=" + primitive.NewObjectID().Hex(), 0},
+		{"dari=" + besok, 0},
+	}
+	for _, tt := range tests {
+		status, body := kirimJSON(t, app, "GET", "/audit?"+tt.query, "")
+		if data, _ := body["data"].([]interface{}); status != 200 || len(data) != tt.ingin {
+			t.Errorf("%s: status = %d, jumlah = %d, ingin %d", tt.query, status, len(data), tt.ingin)
+		}
+	}
+
+	for _, path := range []string{"/audit?user_id=x", "/audit?dari=17-10-2026", "/barang/x/history"} {
+		if status, body := kirimJSON(t, app, "GET", path, ""); status != 400 {
+			t.Errorf("%s: status = %d, body = %v, ingin 400", path, status, body)
+		}
+	}
+}
@@ -2,59 +2,40 @@ package controllers
 
 import (
 	"context"
-	"fmt"
+	"inventory-backend/config"
 	"inventory-backend/models"
-	"os"
+	"inventory-backend/repository"
+	"log"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var userCollection *mongo.Collection
-
-func SetUserCollection(db *mongo.Database) {
-	userCollection = db.Collection("users")
-}
-
-// JWT Secret Key - dalam production gunakan environment variable
-var jwtSecret = []byte("your-secret-key-change-this-in-production")
-
-func init() {
-	// Check if JWT_SECRET environment variable exists
-	if secret := os.Getenv("JWT_SECRET"); secret != "" {
-		jwtSecret = []byte(secret)
-	}
-}
-
 // Register godoc
 // @Summary Register user baru
-// @Description Mendaftarkan user baru dengan username, email, password, dan role
+// @Description Mendaftarkan user baru dengan username, email dan password. Registrasi publik selalu membuat akun dengan role 'user' dan bisa dinonaktifkan dengan REGISTRATION_ENABLED=false. Token verifikasi dikirim ke email; akun yang belum terverifikasi bisa login tetapi belum bisa mengajukan peminjaman.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param user body models.RegisterRequest true "Data user baru"
 // @Success 201 {object} map[string]interface{} "User berhasil didaftarkan"
 // @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Registrasi dinonaktifkan atau role tidak diizinkan"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /auth/register [post]
 func Register(c *fiber.Ctx) error {
-	// Get validated data from middleware
-	userData := c.Locals("userData").(models.RegisterRequest)
-
-	// Check if user already exists
-	var existingUser models.User
-	err := userCollection.FindOne(context.Background(), bson.M{"email": userData.Email}).Decode(&existingUser)
-	if err == nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Email sudah terdaftar",
+	if !config.RegistrasiDibuka() {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Registrasi publik dinonaktifkan, hubungi admin untuk dibuatkan akun",
 		})
 	}
 
+	// Get validated data from middleware
+	userData := c.Locals("userData").(models.RegisterRequest)
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userData.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -63,96 +44,154 @@ func Register(c *fiber.Ctx) error {
 		})
 	}
 
-	// Create new user
+	// Create new user; email harus diverifikasi sebelum bisa mengajukan peminjaman
 	newUser := models.User{
-		ID:        primitive.NewObjectID(),
-		Username:  userData.Username,
-		Email:     userData.Email,
-		Password:  string(hashedPassword),
-		Role:      userData.Role,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:              primitive.NewObjectID(),
+		Username:        userData.Username,
+		Email:           userData.Email,
+		Password:        string(hashedPassword),
+		Role:            userData.Role,
+		BelumVerifikasi: true,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
-	// Insert to database
-	_, err = userCollection.InsertOne(context.Background(), newUser)
+	// Cek username/email lalu simpan user beserta audit dalam satu transaksi
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		pesan, err := cekAkunUnik(ctx, newUser.Username, newUser.Email, primitive.NilObjectID)
+		if err != nil {
+			return err
+		}
+		if pesan != "" {
+			return fiber.NewError(400, pesan)
+		}
+		if err := userRepo.Create(ctx, &newUser); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditRegistrasi, models.ResourceUser, newUser.ID, nil, newUser, nil)
+	})
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Gagal menyimpan user ke database",
-		})
+		return respondUserError(c, err)
+	}
+
+	// Kegagalan kirim email tidak menggagalkan registrasi; user bisa meminta
+	// kirim ulang lewat /auth/verify-email/resend
+	if err := kirimVerifikasiEmail(context.Background(), &newUser); err != nil {
+		log.Printf("Gagal mengirim email verifikasi ke %s: %v", newUser.Email, err)
 	}
 
-	// Generate JWT token
-	token, err := generateJWTToken(newUser.ID, newUser.Email, newUser.Role)
+	// Buat sesi login beserta access token dan refresh token
+	response, err := buatSesi(context.Background(), c, &newUser, models.MetodePassword)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Gagal generate token",
 		})
 	}
 
-	// Prepare response
-	response := models.LoginResponse{
-		Token: token,
-	}
-	response.User.ID = newUser.ID
-	response.User.Username = newUser.Username
-	response.User.Email = newUser.Email
-	response.User.Role = newUser.Role
-
 	return c.Status(201).JSON(fiber.Map{
-		"message": "User berhasil didaftarkan",
+		"message": "User berhasil didaftarkan, cek email untuk verifikasi",
 		"data":    response,
 	})
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Login dengan email dan password, return JWT token
+// @Description Login dengan email dan password. Mengembalikan access token berumur pendek dan refresh token untuk /auth/refresh. Login gagal berulang per akun dan per IP diberi jeda progresif lalu dikunci sementara (429 dengan header Retry-After). Jika user mengaktifkan 2FA, response berisi challenge_token yang harus ditukar lewat /auth/2fa/verify.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param login body models.LoginRequest true "Data login"
 // @Success 200 {object} map[string]interface{} "Login berhasil"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Akun dinonaktifkan"
+// @Failure 429 {object} map[string]interface{} "Terlalu banyak login gagal"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /auth/login [post]
 func Login(c *fiber.Ctx) error {
 	// Get validated data from middleware
 	loginData := c.Locals("loginData").(models.LoginRequest)
 
-	// Find user by email
-	var user models.User
-	err := userCollection.FindOne(context.Background(), bson.M{"email": loginData.Email}).Decode(&user)
+	// Tolak sebelum cek password jika email atau IP sedang dikunci atau
+	// masih dalam jeda setelah login gagal
+	ctx := context.Background()
+	tunggu, err := tungguLogin(ctx, kunciEmail(loginData.Email), kunciIPRequest(c))
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{
-			"error": "Email atau password salah",
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
+	if tunggu > 0 {
+		return tolakLogin(c, tunggu)
+	}
 
-	// Verify password
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginData.Password))
-	if err != nil {
+	// Find user by email
+	user, err := userRepo.FindByEmail(ctx, loginData.Email)
+	if err != nil && err != repository.ErrNotFound {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Verify password; email tidak terdaftar juga dihitung sebagai login gagal
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginData.Password)) != nil {
+		if err := catatRiwayat(context.Background(), c, riwayatLoginGagal(loginData.Email, user, "password salah")); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		dikunci, err := catatLoginGagal(c, loginData.Email, user)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if dikunci > 0 {
+			return tolakLogin(c, dikunci)
+		}
 		return c.Status(401).JSON(fiber.Map{
 			"error": "Email atau password salah",
 		})
 	}
 
-	// Generate JWT token
-	token, err := generateJWTToken(user.ID, user.Email, user.Role)
-	if err != nil {
+	// Login berhasil menghapus hitungan gagal akun ini. Hitungan per IP tidak
+	// dihapus agar satu akun valid tidak bisa dipakai untuk mereset batas IP.
+	if err := loginAttemptRepo.Reset(ctx, kunciEmail(loginData.Email)); err != nil {
 		return c.Status(500).JSON(fiber.Map{
-			"error": "Gagal generate token",
+			"error": err.Error(),
+		})
+	}
+
+	if user.Nonaktif {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Akun dinonaktifkan, hubungi admin",
 		})
 	}
 
-	// Prepare response
-	response := models.LoginResponse{
-		Token: token,
+	// User dengan 2FA mendapat challenge token dan harus melanjutkan ke /auth/2fa/verify
+	if user.TOTPAktif {
+		challenge, err := buatTantangan2FA(user, models.MetodePassword)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Gagal generate token",
+			})
+		}
+		return c.JSON(fiber.Map{
+			"message": "Masukkan kode 2FA",
+			"data": fiber.Map{
+				"two_factor_required": true,
+				"challenge_token":     challenge,
+				"expires_in":          int64(config.Tantangan2FATTL().Seconds()),
+			},
+		})
+	}
+
+	// Buat sesi login beserta access token dan refresh token
+	response, err := buatSesi(context.Background(), c, user, models.MetodePassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Gagal generate token",
+		})
 	}
-	response.User.ID = user.ID
-	response.User.Username = user.Username
-	response.User.Email = user.Email
-	response.User.Role = user.Role
 
 	return c.JSON(fiber.Map{
 		"message": "Login berhasil",
@@ -161,32 +200,20 @@ func Login(c *fiber.Ctx) error {
 }
 
 
-func generateJWTToken(userID primitive.ObjectID, email, role string) (string, error) {
-	// Ambil lokasi WIB (Asia/Jakarta)
-	loc, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil {
-		// fallback ke UTC jika gagal ambil lokasi
-		loc = time.UTC
-	}
-
-	now := time.Now().In(loc)
-	expirationTime := now.Add(24 * time.Hour)
-
-	// Logging waktu saat token dibuat dan kadaluarsa (dalam WIB)
-	fmt.Println("🔐 JWT Token Generated:")
-	fmt.Println("   Sekarang :", now)
-	fmt.Println("   Expired  :", expirationTime.Unix())
+func generateJWTToken(userID primitive.ObjectID, email, role string, sessionID primitive.ObjectID) (string, error) {
+	now := time.Now()
+	expirationTime := now.Add(config.AccessTokenTTL())
 
 	claims := jwt.MapClaims{
 		"user_id": userID.Hex(),
 		"email":   email,
 		"role":    role,
+		"sid":     sessionID.Hex(),
 		"exp":     expirationTime.Unix(),
 		"iat":     now.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return tokenService.Sign(claims)
 }
 
 
@@ -212,8 +239,7 @@ func GetProfile(c *fiber.Ctx) error {
 		})
 	}
 
-	var user models.User
-	err = userCollection.FindOne(context.Background(), bson.M{"_id": objID}).Decode(&user)
+	user, err := userRepo.FindByID(context.Background(), objID)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{
 			"error": "User tidak ditemukan",
@@ -228,3 +254,10 @@ func GetProfile(c *fiber.Ctx) error {
 		"data":    user,
 	})
 }
+
+// currentUserID mengambil ID user yang sedang login dari locals JWT middleware
+func currentUserID(c *fiber.Ctx) primitive.ObjectID {
+	userID, _ := c.Locals("user_id").(string)
+	id, _ := primitive.ObjectIDFromHex(userID)
+	return id
+}
@@ -3,43 +3,92 @@ package controllers
 import (
 	"context"
 	"inventory-backend/models"
+	"inventory-backend/repository"
 	"inventory-backend/validators"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var barangCollection *mongo.Collection
-
-
-func SetBarangCollection(db *mongo.Database) {
-	barangCollection = db.Collection("barang")
-}
-
 // GetAllBarang godoc
 // @Summary Get all barang
-// @Description Mengambil semua data barang
+// @Description Mengambil data barang dengan filter, pencarian, pengurutan dan pagination. Response berisi data, meta (total) dan links ke halaman berikutnya. Dengan q dan tanpa sort, hasil diurutkan dari yang paling relevan.
 // @Tags Barang
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Barang "List semua barang"
+// @Param kategori_id query string false "Filter kategori"
+// @Param stok_min query int false "Stok minimal (inklusif)"
+// @Param stok_max query int false "Stok maksimal (inklusif)"
+// @Param search query string false "Cari sebagian nama barang (case-insensitive)"
+// @Param match query string false "Cara mencocokkan search: contains (bawaan) atau prefix"
+// @Param q query string false "Pencarian kata kunci atas nama barang (text index)"
+// @Param sort query string false "Urutan: id, nama, stok atau tanggal_buat; awali dengan - untuk menurun"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID barang terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Daftar barang"
+// @Failure 400 {object} map[string]interface{} "Parameter tidak valid"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /barang [get]
 func GetAllBarang(c *fiber.Ctx) error {
-	cursor, err := barangCollection.Find(context.Background(), bson.M{})
+	var filter repository.BarangFilter
+	if v := c.Query("kategori_id"); v != "" {
+		id, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "kategori_id tidak valid"})
+		}
+		filter.KategoriID = &id
+	}
+	for _, q := range []struct {
+		nama  string
+		nilai **int
+	}{
+		{"stok_min", &filter.StokMin},
+		{"stok_max", &filter.StokMax},
+	} {
+		v := c.Query(q.nama)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": q.nama + " harus berupa angka"})
+		}
+		*q.nilai = &n
+	}
+	cari, err := parsePencarian(c, "search")
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	filter.Nama = cari
+	filter.Teks = strings.TrimSpace(c.Query("q"))
 
-	var barang []models.Barang
-	if err := cursor.All(context.Background(), &barang); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	opts, err := parseListOptions(c, map[string]string{
+		"id":           "_id",
+		"nama":         "nama",
+		"stok":         "stok",
+		"tanggal_buat": "tanggal_buat",
+	})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(barang)
+	if filter.Teks != "" && opts.Sort == "" {
+		// Urutan relevansi hanya bisa dipakai dengan page
+		if opts.After != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "after dengan q hanya bisa dipakai bersama sort"})
+		}
+		opts.Sort = repository.UrutRelevansi
+	}
+
+	barang, total, err := barangRepo.FindAll(context.Background(), filter, opts)
+	if err != nil {
+		return errorList(c, err, "")
+	}
+	return c.JSON(paginated(c, barang, opts, total, func(b models.Barang) primitive.ObjectID { return b.ID }))
 }
 
 // GetBarangByID godoc
@@ -61,8 +110,7 @@ func GetBarangByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	var barang models.Barang
-	err = barangCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&barang)
+	barang, err := barangRepo.FindByID(context.Background(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan"})
 	}
@@ -95,20 +143,35 @@ func CreateBarang(c *fiber.Ctx) error {
 	}
 
 	// Cek apakah kategori id ada
-	var kategori models.Kategori
-	err := kategoriCollection.FindOne(context.Background(), bson.M{"_id": barang.KategoriID}).Decode(&kategori)
+	_, err := kategoriRepo.FindByID(context.Background(), barang.KategoriID)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Kategori tidak ditemukan"})
 	}
 	
 	// INSERT DATA BARU
 	barang.ID = primitive.NewObjectID()
+	barang.Terhapus = models.Terhapus{}
 	barang.TanggalBuat = time.Now().Format("2006-01-02 15:04:05")
 
-	_, err = barangCollection.InsertOne(context.Background(), barang)
+	// Stok awal dicatat sebagai mutasi pertama di ledger
+	stokAwal := barang.Stok
+	barang.Stok = 0
+
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := barangRepo.Create(ctx, &barang); err != nil {
+			return err
+		}
+		if err := ubahStok(ctx, barang.ID, stokAwal, models.MutasiAwal, currentUserID(c), nil); err != nil {
+			return err
+		}
+		dibuat := barang
+		dibuat.Stok = stokAwal
+		return catatPerubahan(ctx, c, models.AuditBuatBarang, models.ResourceBarang, barang.ID, nil, dibuat, nil)
+	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	barang.Stok = stokAwal
 
 	return c.Status(201).JSON(barang)
 }
@@ -124,6 +187,7 @@ func CreateBarang(c *fiber.Ctx) error {
 // @Param barang body models.Barang true "Data barang yang akan diupdate"
 // @Success 200 {object} map[string]interface{} "Barang berhasil diupdate"
 // @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Barang tidak ditemukan"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /barang/{id} [put]
 func UpdateBarang(c *fiber.Ctx) error {
@@ -143,22 +207,36 @@ func UpdateBarang(c *fiber.Ctx) error {
 	}
 
 	// Pastikan KategoriID valid
-	var kategori models.Kategori
-	err = kategoriCollection.FindOne(context.Background(), bson.M{"_id": data.KategoriID}).Decode(&kategori)
+	_, err = kategoriRepo.FindByID(context.Background(), data.KategoriID)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Kategori tidak ditemukan"})
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"nama":         data.Nama,
-			"kategori_id":  data.KategoriID,
-			"stok":         data.Stok,
-			"tanggal_buat": time.Now().Format("2006-01-02 15:04:05"),
-		},
-	}
+	data.ID = id
+	data.TanggalBuat = time.Now().Format("2006-01-02 15:04:05")
+
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		existing, err := barangRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := barangRepo.Update(ctx, &data); err != nil {
+			return err
+		}
 
-	_, err = barangCollection.UpdateOne(context.Background(), bson.M{"_id": id}, update)
+		// Perubahan stok manual dicatat sebagai penyesuaian di ledger
+		if err := ubahStok(ctx, id, data.Stok-existing.Stok, models.MutasiPenyesuaian, currentUserID(c), nil); err != nil {
+			return err
+		}
+		sesudah, err := barangRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditUbahBarang, models.ResourceBarang, id, existing, sesudah, nil)
+	})
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan"})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -168,7 +246,7 @@ func UpdateBarang(c *fiber.Ctx) error {
 
 // DeleteBarang godoc
 // @Summary Delete barang
-// @Description Menghapus data barang berdasarkan ID
+// @Description Memindahkan barang ke tempat sampah (soft delete). Barang yang masih dipakai peminjaman aktif tidak bisa dihapus; response 409 mencantumkan peminjaman tersebut. Riwayat peminjaman dan laporan tetap menampilkan barang yang dihapus.
 // @Tags Barang
 // @Accept json
 // @Produce json
@@ -176,6 +254,8 @@ func UpdateBarang(c *fiber.Ctx) error {
 // @Param id path string true "Barang ID"
 // @Success 200 {object} map[string]interface{} "Barang berhasil dihapus"
 // @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 404 {object} map[string]interface{} "Barang tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Barang masih dipakai peminjaman aktif"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /barang/{id} [delete]
 func DeleteBarang(c *fiber.Ctx) error {
@@ -185,9 +265,25 @@ func DeleteBarang(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	_, err = barangCollection.DeleteOne(context.Background(), bson.M{"_id": id})
+	// Pengecekan dan penghapusan dalam satu transaksi agar tidak ada peminjaman
+	// baru yang masuk di antaranya
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := cekPeminjamanBarang(ctx, []primitive.ObjectID{id}); err != nil {
+			return err
+		}
+		barang, err := barangRepo.FindByID(ctx, id)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(404, "Barang tidak ditemukan")
+		} else if err != nil {
+			return err
+		}
+		if err := barangRepo.Delete(ctx, id, currentUserID(c)); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditHapusBarang, models.ResourceBarang, id, barang, nil, nil)
+	})
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return respondHapusError(c, err)
 	}
 
 	return c.JSON(fiber.Map{"message": "Barang berhasil dihapus"})
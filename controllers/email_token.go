@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"inventory-backend/config"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"inventory-backend/validators"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ForgotPasswordRequest adalah body untuk meminta email reset password
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest adalah body untuk mengganti password dengan token dari email
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// VerifyEmailRequest adalah body untuk memverifikasi email dengan token dari email
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// buatTokenEmail membatalkan token lama user dengan jenis yang sama lalu
+// membuat token sekali pakai baru. Yang dikembalikan adalah token mentah untuk
+// dikirim lewat email; yang disimpan hanya hash-nya.
+func buatTokenEmail(ctx context.Context, user *models.User, jenis string, ttl time.Duration) (string, error) {
+	if err := authTokenRepo.BatalkanMilikUser(ctx, user.ID, jenis); err != nil {
+		return "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	now := time.Now()
+	err := authTokenRepo.Create(ctx, &models.AuthToken{
+		UserID:    user.ID,
+		Jenis:     jenis,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// isiEmailToken menyusun isi email berisi link ke FRONTEND_URL, atau token
+// saja jika FRONTEND_URL tidak diset
+func isiEmailToken(pembuka, path, token string, ttl time.Duration) string {
+	var b strings.Builder
+	b.WriteString(pembuka + "\n\n")
+	if base := config.FrontendURL(); base != "" {
+		fmt.Fprintf(&b, "%s%s?token=%s\n\n", base, path, token)
+	} else {
+		fmt.Fprintf(&b, "Token: %s\n\n", token)
+	}
+	fmt.Fprintf(&b, "Token berlaku selama %s dan hanya bisa dipakai sekali.\n", ttl)
+	b.WriteString("Abaikan email ini jika Anda tidak merasa memintanya.\n")
+	return b.String()
+}
+
+// kirimVerifikasiEmail membuat token verifikasi dan mengirimkannya ke email user
+func kirimVerifikasiEmail(ctx context.Context, user *models.User) error {
+	ttl := config.VerifikasiEmailTTL()
+	token, err := buatTokenEmail(ctx, user, models.TokenVerifikasiEmail, ttl)
+	if err != nil {
+		return err
+	}
+	return mailer.Kirim(ctx, services.Email{
+		Ke:     user.Email,
+		Subjek: "Verifikasi email akun inventory",
+		Isi: isiEmailToken(
+			"Halo "+user.Username+", verifikasi email Anda dengan membuka link atau memakai token berikut:",
+			"/verify-email", token, ttl),
+	})
+}
+
+// ForgotPassword godoc
+// @Summary Lupa password
+// @Description Mengirim token reset password ke email jika email terdaftar. Response selalu sama agar tidak bisa dipakai untuk menebak email yang terdaftar.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordRequest true "Email akun"
+// @Success 200 {object} map[string]interface{} "Permintaan diterima"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /auth/forgot-password [post]
+func ForgotPassword(c *fiber.Ctx) error {
+	var body ForgotPasswordRequest
+	if err := c.BodyParser(&body); err != nil || strings.TrimSpace(body.Email) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "email wajib diisi"})
+	}
+
+	ctx := context.Background()
+	user, err := userRepo.FindByEmail(ctx, strings.TrimSpace(body.Email))
+	if err == nil && !user.Nonaktif {
+		ttl := config.ResetPasswordTTL()
+		token, err := buatTokenEmail(ctx, user, models.TokenResetPassword, ttl)
+		if err == nil {
+			err = mailer.Kirim(ctx, services.Email{
+				Ke:     user.Email,
+				Subjek: "Reset password akun inventory",
+				Isi: isiEmailToken(
+					"Halo "+user.Username+", kami menerima permintaan reset password untuk akun Anda. Buka link atau pakai token berikut untuk membuat password baru:",
+					"/reset-password", token, ttl),
+			})
+		}
+		if err != nil {
+			log.Printf("Gagal mengirim email reset password ke %s: %v", user.Email, err)
+		}
+	} else if err != nil && err != repository.ErrNotFound {
+		log.Printf("Gagal mencari user untuk reset password: %v", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Jika email terdaftar, instruksi reset password sudah dikirim",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Mengganti password memakai token dari email lupa password. Token hanya bisa dipakai sekali dan semua sesi login user dicabut.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param body body ResetPasswordRequest true "Token dan password baru"
+// @Success 200 {object} map[string]interface{} "Password berhasil direset"
+// @Failure 400 {object} map[string]interface{} "Token tidak valid atau password tidak memenuhi syarat"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/reset-password [post]
+func ResetPassword(c *fiber.Ctx) error {
+	var body ResetPasswordRequest
+	if err := c.BodyParser(&body); err != nil || body.Token == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "token wajib diisi"})
+	}
+	if err := validators.ValidatePassword(body.Password); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengenkripsi password"})
+	}
+	password, tidak := string(hashedPassword), false
+
+	// Token, password, audit dan pencabutan sesi disimpan bersama agar token
+	// tidak terpakai tanpa password berubah dan sesi lama tidak tertinggal hidup
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		sebelum, err := pakaiTokenEmail(ctx, models.TokenResetPassword, body.Token)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(400, "Token reset password tidak valid atau sudah kedaluwarsa")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Token diterima lewat email, jadi kepemilikan email sekaligus terbukti
+		user, err := userRepo.Update(ctx, sebelum.ID, repository.UserUpdate{
+			Password:           &password,
+			HarusGantiPassword: &tidak,
+			BelumVerifikasi:    &tidak,
+		})
+		if err != nil {
+			return err
+		}
+		if err := catatPerubahan(ctx, c, models.AuditLupaPassword, models.ResourceUser, user.ID, sebelum, user, nil); err != nil {
+			return err
+		}
+		_, err = sessionRepo.RevokeByUser(ctx, user.ID, nil, models.SesiResetEmail)
+		return err
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Password berhasil direset, silakan login kembali"})
+}
+
+// VerifyEmail godoc
+// @Summary Verifikasi email
+// @Description Menandai email akun sebagai terverifikasi memakai token yang dikirim saat registrasi
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param body body VerifyEmailRequest true "Token verifikasi"
+// @Success 200 {object} map[string]interface{} "Email berhasil diverifikasi"
+// @Failure 400 {object} map[string]interface{} "Token tidak valid"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/verify-email [post]
+func VerifyEmail(c *fiber.Ctx) error {
+	var body VerifyEmailRequest
+	if err := c.BodyParser(&body); err != nil || body.Token == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "token wajib diisi"})
+	}
+
+	err := txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		sebelum, err := pakaiTokenEmail(ctx, models.TokenVerifikasiEmail, body.Token)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(400, "Token verifikasi tidak valid atau sudah kedaluwarsa")
+		}
+		if err != nil {
+			return err
+		}
+		if !sebelum.BelumVerifikasi {
+			return nil
+		}
+
+		terverifikasi := false
+		user, err := userRepo.Update(ctx, sebelum.ID, repository.UserUpdate{BelumVerifikasi: &terverifikasi})
+		if err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditVerifikasiEmail, models.ResourceUser, user.ID, sebelum, user, nil)
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Email berhasil diverifikasi"})
+}
+
+// ResendVerifyEmail godoc
+// @Summary Kirim ulang email verifikasi
+// @Description Mengirim ulang token verifikasi ke email user yang sedang login. Token sebelumnya tidak berlaku lagi.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Email verifikasi dikirim"
+// @Failure 400 {object} map[string]interface{} "Email sudah terverifikasi"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Gagal mengirim email"
+// @Router /auth/verify-email/resend [post]
+func ResendVerifyEmail(c *fiber.Ctx) error {
+	ctx := context.Background()
+	user, err := userRepo.FindByID(ctx, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !user.BelumVerifikasi {
+		return c.Status(400).JSON(fiber.Map{"error": "Email sudah terverifikasi"})
+	}
+
+	if err := kirimVerifikasiEmail(ctx, user); err != nil {
+		log.Printf("Gagal mengirim email verifikasi ke %s: %v", user.Email, err)
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengirim email verifikasi"})
+	}
+	return c.JSON(fiber.Map{"message": "Email verifikasi sudah dikirim ke " + user.Email})
+}
+
+// pakaiTokenEmail memakai token sekali pakai dan mengembalikan pemiliknya.
+// Token dianggap tidak valid jika user sudah dihapus, dinonaktifkan atau
+// emailnya berubah sejak token dikirim. ctx harus ctx transaksi yang juga
+// menyimpan perubahan user agar token ikut batal jika perubahannya gagal.
+func pakaiTokenEmail(ctx context.Context, jenis, token string) (*models.User, error) {
+	authToken, err := authTokenRepo.Pakai(ctx, jenis, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := userRepo.FindByID(ctx, authToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Nonaktif || !strings.EqualFold(user.Email, authToken.Email) {
+		return nil, repository.ErrNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestResetPasswordAtomik memastikan token reset tidak ikut terpakai jika
+// pencabutan sesi gagal, sehingga user bisa mengulang dengan token yang sama
+func TestResetPasswordAtomik(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	SetRepositories(repos)
+	ctx := context.Background()
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Password: "hash-lama", Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	session := models.Session{ID: primitive.NewObjectID(), UserID: user.ID, RefreshTokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := sessionRepo.Create(ctx, &session); err != nil {
+		t.Fatal(err)
+	}
+	token, err := buatTokenEmail(ctx, &user, models.TokenResetPassword, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reset := func() int {
+		app := fiber.New()
+		app.Post("/auth/reset-password", ResetPassword)
+		req := httptest.NewRequest("POST", "/auth/reset-password", strings.NewReader(`{"token":"`+token+`","password":"rahasiaBaru456"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	gagal := *repos
+	gagal.Session = sessionGagal{repos.Session}
+	SetRepositories(&gagal)
+	if status := reset(); status != 500 {
+		t.Fatalf("status = %d, ingin 500", status)
+	}
+	if tersimpan, _ := userRepo.FindByID(ctx, user.ID); tersimpan.Password != user.Password {
+		t.Error("password berubah walau sesi gagal dicabut")
+	}
+
+	SetRepositories(repos)
+	if status := reset(); status != 200 {
+		t.Fatalf("status = %d, ingin 200 (token seharusnya belum terpakai)", status)
+	}
+	if aktif, _ := sessionRepo.FindAktifByUser(ctx, user.ID); len(aktif) != 0 {
+		t.Errorf("sesi aktif = %d, ingin 0", len(aktif))
+	}
+	if status := reset(); status != 400 {
+		t.Errorf("token dipakai ulang: status = %d, ingin 400", status)
+	}
+}
+
+// TestLupaPasswordEmailBedaKapital memastikan email yang hanya berbeda huruf
+// besar/kecil tetap menemukan akunnya, sama seperti aturan keunikan email
+func TestLupaPasswordEmailBedaKapital(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	kotak := &services.MemoryMailer{}
+	SetMailer(kotak)
+	t.Cleanup(func() { SetMailer(nil) })
+	ctx := context.Background()
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Post("/auth/forgot-password", ForgotPassword)
+	req := httptest.NewRequest("POST", "/auth/forgot-password", strings.NewReader(`{"email":"Budi@Example.COM"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := app.Test(req, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	terkirim := kotak.Terkirim()
+	if len(terkirim) != 1 || terkirim[0].Ke != user.Email {
+		t.Errorf("email terkirim = %+v, ingin satu email ke %s", terkirim, user.Email)
+	}
+}
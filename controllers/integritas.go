@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// batasDependensi adalah jumlah maksimal data perujuk yang dicantumkan di response 409
+const batasDependensi = 20
+
+// statusAktif adalah status peminjaman yang belum selesai
+var statusAktif = []string{models.StatusDiajukan, models.StatusDisetujui, models.StatusDipinjam}
+
+// errMasihDipakai dikembalikan ketika data tidak bisa dihapus karena masih
+// dirujuk data lain. dependensi berisi jumlah dan contoh data perujuknya.
+type errMasihDipakai struct {
+	pesan      string
+	dependensi fiber.Map
+}
+
+func (e *errMasihDipakai) Error() string {
+	return e.pesan
+}
+
+// respondHapusError memetakan error dari transaksi penghapusan ke response HTTP
+func respondHapusError(c *fiber.Ctx, err error) error {
+	var dipakai *errMasihDipakai
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &dipakai):
+		return c.Status(409).JSON(fiber.Map{"error": dipakai.pesan, "dependensi": dipakai.dependensi})
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": fiberErr.Message})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+// cekPeminjamanBarang mengembalikan errMasihDipakai jika ada peminjaman aktif
+// yang memuat salah satu barang, karena barang yang sedang diajukan atau
+// dipinjam tidak boleh hilang. Riwayat peminjaman yang sudah selesai tidak
+// menghalangi soft delete karena barangnya tetap tersimpan.
+func cekPeminjamanBarang(ctx context.Context, barangIDs []primitive.ObjectID) error {
+	if len(barangIDs) == 0 {
+		return nil
+	}
+
+	peminjaman, aktif, err := peminjamanRepo.FindAll(ctx,
+		repository.PeminjamanFilter{BarangID: barangIDs, Status: statusAktif},
+		repository.ListOptions{Limit: batasDependensi},
+	)
+	if err != nil {
+		return err
+	}
+	if aktif == 0 {
+		return nil
+	}
+
+	contoh := make([]fiber.Map, 0, len(peminjaman))
+	for _, p := range peminjaman {
+		contoh = append(contoh, fiber.Map{
+			"id":             p.ID,
+			"nama_peminjam":  p.NamaPeminjam,
+			"status":         p.Status,
+			"tanggal_pinjam": p.TanggalPinjam,
+		})
+	}
+
+	return &errMasihDipakai{
+		pesan: fmt.Sprintf("Barang masih dipakai oleh %d peminjaman aktif", aktif),
+		dependensi: fiber.Map{
+			"peminjaman_aktif": aktif,
+			"contoh":           contoh,
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// siapkanKategori membuat kategori dengan barang-barangnya langsung lewat repository
+func siapkanKategori(t *testing.T, nama string, barang ...string) (models.Kategori, []models.Barang) {
+	t.Helper()
+	ctx := context.Background()
+	kategori := models.Kategori{ID: primitive.NewObjectID(), Nama: nama}
+	if err := kategoriRepo.Create(ctx, &kategori); err != nil {
+		t.Fatal(err)
+	}
+	semua := []models.Barang{}
+	for _, n := range barang {
+		b := models.Barang{ID: primitive.NewObjectID(), Nama: n, KategoriID: kategori.ID, Stok: 1}
+		if err := barangRepo.Create(ctx, &b); err != nil {
+			t.Fatal(err)
+		}
+		semua = append(semua, b)
+	}
+	return kategori, semua
+}
+
+// TestDeleteKategoriDipakai memastikan kategori yang masih dipakai barang
+// ditolak dengan daftar barangnya, dan tidak ada yang berubah
+func TestDeleteKategoriDipakai(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	kategori, barang := siapkanKategori(t, "Elektronik", "Proyektor", "Laptop")
+
+	app := appPengguna(primitive.NewObjectID(), models.PermKategoriWrite)
+	app.Delete("/kategori/:id", DeleteKategori)
+
+	status, body := kirimJSON(t, app, "DELETE", "/kategori/"+kategori.ID.Hex(), "")
+	if status != 409 {
+		t.Fatalf("status = %d, body = %v, ingin 409", status, body)
+	}
+	dependensi := body["dependensi"].(map[string]interface{})
+	contoh := dependensi["contoh"].([]interface{})
+	if dependensi["barang"] != float64(2) || len(contoh) != 2 {
+		t.Fatalf("dependensi = %v, ingin 2 barang", dependensi)
+	}
+	for i, c := range contoh {
+		c := c.(map[string]interface{})
+		if c["id"] != barang[i].ID.Hex() || c["nama"] != barang[i].Nama {
+			t.Errorf("contoh[%d] = %v, ingin %s %s", i, c, barang[i].ID.Hex(), barang[i].Nama)
+		}
+	}
+	if _, err := kategoriRepo.FindByID(ctx, kategori.ID); err != nil {
+		t.Errorf("kategori ikut terhapus: %v", err)
+	}
+
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"cascade=true&reassign_to=" + primitive.NewObjectID().Hex(), 400},
+		{"reassign_to=bukan-id", 400},
+		{"reassign_to=" + kategori.ID.Hex(), 400},
+		{"reassign_to=" + primitive.NewObjectID().Hex(), 400},
+	}
+	for _, tt := range tests {
+		if status, body := kirimJSON(t, app, "DELETE", "/kategori/"+kategori.ID.Hex()+"?"+tt.query, ""); status != tt.status {
+			t.Errorf("%s: status = %d, body = %v, ingin %d", tt.query, status, body, tt.status)
+		}
+	}
+	if status, _ := kirimJSON(t, app, "DELETE", "/kategori/"+primitive.NewObjectID().Hex(), ""); status != 404 {
+		t.Errorf("kategori tidak ada: status = %d, ingin 404", status)
+	}
+}
+
+func TestDeleteKategoriReassign(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	lama, barang := siapkanKategori(t, "Elektronik", "Proyektor", "Laptop")
+	baru, _ := siapkanKategori(t, "Multimedia")
+
+	app := appPengguna(primitive.NewObjectID(), models.PermKategoriWrite)
+	app.Delete("/kategori/:id", DeleteKategori)
+
+	status, body := kirimJSON(t, app, "DELETE", "/kategori/"+lama.ID.Hex()+"?reassign_to="+baru.ID.Hex(), "")
+	if status != 200 || body["barang_dipindah"] != float64(2) {
+		t.Fatalf("status = %d, body = %v, ingin 200 dengan 2 barang dipindah", status, body)
+	}
+	if _, err := kategoriRepo.FindByID(ctx, lama.ID); err != repository.ErrNotFound {
+		t.Errorf("kategori lama: err = %v, ingin ErrNotFound", err)
+	}
+	for _, b := range barang {
+		pindah, err := barangRepo.FindByID(ctx, b.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pindah.KategoriID != baru.ID {
+			t.Errorf("barang %s kategori = %s, ingin %s", b.Nama, pindah.KategoriID.Hex(), baru.ID.Hex())
+		}
+		logs, _, err := auditRepo.FindAll(ctx, repository.AuditFilter{Resource: models.ResourceBarang, ResourceID: &b.ID}, repository.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != 1 || logs[0].Aksi != models.AuditUbahBarang {
+			t.Errorf("audit barang %s = %+v, ingin satu perubahan", b.Nama, logs)
+		}
+	}
+}
+
+// TestDeleteKategoriCascade memastikan cascade ditolak selama ada barang yang
+// dipinjam, lalu ikut memindahkan barangnya ke tempat sampah setelah selesai
+func TestDeleteKategoriCascade(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	kategori, barang := siapkanKategori(t, "Elektronik", "Proyektor", "Laptop")
+	p := models.Peminjaman{
+		ID:           primitive.NewObjectID(),
+		NamaPeminjam: "Budi",
+		Items:        []models.ItemPeminjaman{{BarangID: barang[1].ID, Jumlah: 1}},
+		Status:       models.StatusDipinjam,
+	}
+	if err := peminjamanRepo.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermKategoriWrite)
+	app.Delete("/kategori/:id", DeleteKategori)
+	hapus := func() (int, map[string]interface{}) {
+		return kirimJSON(t, app, "DELETE", "/kategori/"+kategori.ID.Hex()+"?cascade=true", "")
+	}
+
+	status, body := hapus()
+	if status != 409 {
+		t.Fatalf("cascade dengan peminjaman aktif: status = %d, body = %v, ingin 409", status, body)
+	}
+	if dependensi := body["dependensi"].(map[string]interface{}); dependensi["peminjaman_aktif"] != float64(1) {
+		t.Errorf("dependensi = %v, ingin 1 peminjaman aktif", dependensi)
+	}
+	if _, err := barangRepo.FindByID(ctx, barang[0].ID); err != nil {
+		t.Errorf("barang terhapus walau cascade ditolak: %v", err)
+	}
+
+	p.Status = models.StatusDikembalikan
+	if err := peminjamanRepo.Update(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+	status, body = hapus()
+	if status != 200 || body["barang_dihapus"] != float64(2) {
+		t.Fatalf("cascade: status = %d, body = %v, ingin 200 dengan 2 barang dihapus", status, body)
+	}
+	for _, b := range barang {
+		if _, err := barangRepo.FindByID(ctx, b.ID); err != repository.ErrNotFound {
+			t.Errorf("barang %s: err = %v, ingin ErrNotFound", b.Nama, err)
+		}
+	}
+	terhapus, total, err := barangRepo.FindTerhapus(ctx, time.Now(), repository.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(terhapus) != 2 {
+		t.Errorf("barang di tempat sampah = %d, ingin 2", total)
+	}
+}
+
+// TestDeleteBarangDipinjam memastikan barang dengan peminjaman aktif tidak bisa
+// dihapus, sedangkan riwayat peminjaman yang sudah selesai tidak menghalangi
+func TestDeleteBarangDipinjam(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	barang := siapkanBarang(t, "Proyektor", 2)
+	for _, s := range []string{models.StatusDiajukan, models.StatusDikembalikan} {
+		p := models.Peminjaman{
+			ID:           primitive.NewObjectID(),
+			NamaPeminjam: "Peminjam " + s,
+			Items:        []models.ItemPeminjaman{{BarangID: barang.ID, Jumlah: 1}},
+			Status:       s,
+		}
+		if err := peminjamanRepo.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermBarangWrite)
+	app.Delete("/barang/:id", DeleteBarang)
+
+	status, body := kirimJSON(t, app, "DELETE", "/barang/"+barang.ID.Hex(), "")
+	if status != 409 {
+		t.Fatalf("status = %d, body = %v, ingin 409", status, body)
+	}
+	dependensi := body["dependensi"].(map[string]interface{})
+	contoh := dependensi["contoh"].([]interface{})
+	if dependensi["peminjaman_aktif"] != float64(1) || len(contoh) != 1 {
+		t.Fatalf("dependensi = %v, ingin satu peminjaman aktif", dependensi)
+	}
+	if c := contoh[0].(map[string]interface{}); c["status"] != models.StatusDiajukan {
+		t.Errorf("contoh = %v, ingin peminjaman diajukan", c)
+	}
+
+	aktif, _, err := peminjamanRepo.FindAll(ctx, repository.PeminjamanFilter{Status: []string{models.StatusDiajukan}}, repository.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	aktif[0].Status = models.StatusDitolak
+	if err := peminjamanRepo.Update(ctx, &aktif[0]); err != nil {
+		t.Fatal(err)
+	}
+	if status, body := kirimJSON(t, app, "DELETE", "/barang/"+barang.ID.Hex(), ""); status != 200 {
+		t.Errorf("setelah peminjaman selesai: status = %d, body = %v, ingin 200", status, body)
+	}
+}
@@ -2,43 +2,46 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"inventory-backend/models"
+	"inventory-backend/repository"
 	"inventory-backend/validators"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var kategoriCollection *mongo.Collection
-
-func SetKategoriCollection(db *mongo.Database) {
-	kategoriCollection = db.Collection("kategori")
-}
-
 // GetAllKategori godoc
 // @Summary Get all kategori
-// @Description Mengambil semua data kategori
+// @Description Mengambil data kategori dengan pengurutan dan pagination. Response berisi data, meta (total) dan links ke halaman berikutnya.
 // @Tags Kategori
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Kategori "List semua kategori"
+// @Param sort query string false "Urutan: id, nama atau tanggal_buat; awali dengan - untuk menurun"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID kategori terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Daftar kategori"
+// @Failure 400 {object} map[string]interface{} "Parameter tidak valid"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /kategori [get]
 func GetAllKategori(c *fiber.Ctx) error {
-	cursor, err := kategoriCollection.Find(context.Background(), bson.M{})
+	opts, err := parseListOptions(c, map[string]string{
+		"id":           "_id",
+		"nama":         "nama",
+		"tanggal_buat": "tanggal_buat",
+	})
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	var kategori []models.Kategori
-	if err := cursor.All(context.Background(), &kategori); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	kategori, total, err := kategoriRepo.FindAll(context.Background(), opts)
+	if err != nil {
+		return errorList(c, err, "")
 	}
-	return c.JSON(kategori)
+	return c.JSON(paginated(c, kategori, opts, total, func(k models.Kategori) primitive.ObjectID { return k.ID }))
 }
 
 // GetKategoriByID godoc
@@ -60,8 +63,7 @@ func GetKategoriByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	var kategori models.Kategori
-	err = kategoriCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&kategori)
+	kategori, err := kategoriRepo.FindByID(context.Background(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Kategori tidak ditemukan"})
 	}
@@ -92,9 +94,15 @@ func CreateKategori(c *fiber.Ctx) error {
 	}
 
 	kategori.ID = primitive.NewObjectID()
+	kategori.Terhapus = models.Terhapus{}
 	kategori.TanggalBuat = time.Now().Format("2006-01-02 15:04:05")
 
-	_, err := kategoriCollection.InsertOne(context.Background(), kategori)
+	err := txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := kategoriRepo.Create(ctx, &kategori); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditBuatKategori, models.ResourceKategori, kategori.ID, nil, kategori, nil)
+	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -113,6 +121,7 @@ func CreateKategori(c *fiber.Ctx) error {
 // @Param kategori body models.Kategori true "Data kategori yang akan diupdate"
 // @Success 200 {object} map[string]interface{} "Kategori berhasil diupdate"
 // @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Kategori tidak ditemukan"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /kategori/{id} [put]
 func UpdateKategori(c *fiber.Ctx) error {
@@ -131,15 +140,26 @@ func UpdateKategori(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"nama":         data.Nama,
-			"deskripsi":    data.Deskripsi,
-			"tanggal_buat": time.Now().Format("2006-01-02 15:04:05"),
-		},
-	}
+	data.ID = id
+	data.TanggalBuat = time.Now().Format("2006-01-02 15:04:05")
 
-	_, err = kategoriCollection.UpdateOne(context.Background(), bson.M{"_id": id}, update)
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		existing, err := kategoriRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := kategoriRepo.Update(ctx, &data); err != nil {
+			return err
+		}
+		sesudah, err := kategoriRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditUbahKategori, models.ResourceKategori, id, existing, sesudah, nil)
+	})
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Kategori tidak ditemukan"})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -149,14 +169,18 @@ func UpdateKategori(c *fiber.Ctx) error {
 
 // DeleteKategori godoc
 // @Summary Delete kategori
-// @Description Menghapus data kategori berdasarkan ID
+// @Description Memindahkan kategori ke tempat sampah (soft delete). Kategori yang masih dipakai barang tidak bisa dihapus (409 beserta daftar barangnya) kecuali dengan cascade=true, yang ikut memindahkan barangnya ke tempat sampah, atau reassign_to, yang memindahkan barangnya ke kategori lain. Cascade ditolak jika ada barang yang masih dipakai peminjaman aktif.
 // @Tags Kategori
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Kategori ID"
+// @Param cascade query bool false "Ikut hapus semua barang di kategori ini"
+// @Param reassign_to query string false "ID kategori tujuan untuk barang di kategori ini"
 // @Success 200 {object} map[string]interface{} "Kategori berhasil dihapus"
-// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 400 {object} map[string]interface{} "ID atau parameter tidak valid"
+// @Failure 404 {object} map[string]interface{} "Kategori tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Kategori masih dipakai barang"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /kategori/{id} [delete]
 func DeleteKategori(c *fiber.Ctx) error {
@@ -166,10 +190,103 @@ func DeleteKategori(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	_, err = kategoriCollection.DeleteOne(context.Background(), bson.M{"_id": id})
+	cascade := c.QueryBool("cascade")
+	var tujuan *primitive.ObjectID
+	if v := c.Query("reassign_to"); v != "" {
+		tujuanID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "reassign_to tidak valid"})
+		}
+		if tujuanID == id {
+			return c.Status(400).JSON(fiber.Map{"error": "reassign_to tidak boleh kategori yang sama"})
+		}
+		tujuan = &tujuanID
+	}
+	if cascade && tujuan != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "cascade dan reassign_to tidak bisa dipakai bersamaan"})
+	}
+
+	response := fiber.Map{"message": "Kategori berhasil dihapus"}
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		kategori, err := kategoriRepo.FindByID(ctx, id)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(404, "Kategori tidak ditemukan")
+		} else if err != nil {
+			return err
+		}
+		barang, total, err := barangRepo.FindAll(ctx, repository.BarangFilter{KategoriID: &id}, repository.ListOptions{})
+		if err != nil {
+			return err
+		}
+		// Perubahan setiap barang ikut dicatat di riwayatnya masing-masing
+		detail := map[string]interface{}{"kategori_id": id}
+
+		switch {
+		case total == 0:
+		case tujuan != nil:
+			if _, err := kategoriRepo.FindByID(ctx, *tujuan); err == repository.ErrNotFound {
+				return fiber.NewError(400, "Kategori tujuan tidak ditemukan")
+			} else if err != nil {
+				return err
+			}
+			jumlah, err := barangRepo.PindahKategori(ctx, id, *tujuan)
+			if err != nil {
+				return err
+			}
+			for _, b := range barang {
+				sesudah := b
+				sesudah.KategoriID = *tujuan
+				if err := catatPerubahan(ctx, c, models.AuditUbahBarang, models.ResourceBarang, b.ID, b, sesudah, detail); err != nil {
+					return err
+				}
+			}
+			response["barang_dipindah"] = jumlah
+		case cascade:
+			ids := make([]primitive.ObjectID, 0, len(barang))
+			for _, b := range barang {
+				ids = append(ids, b.ID)
+			}
+			if err := cekPeminjamanBarang(ctx, ids); err != nil {
+				return err
+			}
+			jumlah, err := barangRepo.DeleteByKategori(ctx, id, currentUserID(c))
+			if err != nil {
+				return err
+			}
+			for _, b := range barang {
+				if err := catatPerubahan(ctx, c, models.AuditHapusBarang, models.ResourceBarang, b.ID, b, nil, detail); err != nil {
+					return err
+				}
+			}
+			response["barang_dihapus"] = jumlah
+		default:
+			contoh := make([]fiber.Map, 0, min(len(barang), batasDependensi))
+			for _, b := range barang[:min(len(barang), batasDependensi)] {
+				contoh = append(contoh, fiber.Map{"id": b.ID, "nama": b.Nama})
+			}
+			return &errMasihDipakai{
+				pesan: fmt.Sprintf("Kategori masih dipakai oleh %d barang; gunakan cascade=true atau reassign_to", total),
+				dependensi: fiber.Map{
+					"barang": total,
+					"contoh": contoh,
+				},
+			}
+		}
+		if err := kategoriRepo.Delete(ctx, id, currentUserID(c)); err != nil {
+			return err
+		}
+		detailHapus := map[string]interface{}{}
+		if tujuan != nil {
+			detailHapus["reassign_to"] = *tujuan
+		}
+		if cascade {
+			detailHapus["cascade"] = true
+		}
+		return catatPerubahan(ctx, c, models.AuditHapusKategori, models.ResourceKategori, id, kategori, nil, detailHapus)
+	})
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return respondHapusError(c, err)
 	}
 
-	return c.JSON(fiber.Map{"message": "Kategori berhasil dihapus"})
+	return c.JSON(response)
 }
@@ -2,69 +2,169 @@ package controllers
 
 import (
 	"context"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-
-var dbRef *mongo.Database // Simpan referensi ke database
-
-func SetLaporanCollection(db *mongo.Database) {
-	dbRef = db
-}
-
 // GetLaporanPeminjaman godoc
 // @Summary Get laporan peminjaman
-// @Description Mengambil laporan peminjaman dengan detail barang dan kategori
+// @Description Mengambil laporan peminjaman dengan detail barang dan kategori. Butuh permission laporan:read; nama, email dan telepon peminjam disamarkan kecuali pemanggil memiliki laporan:pii. Setiap pengambilan laporan dicatat di audit log.
 // @Tags Laporan
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {array} object "Laporan peminjaman lengkap"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Tidak memiliki permission laporan:read"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /laporan/peminjaman [get]
 func GetLaporanPeminjaman(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Referensi koleksi
-	peminjamanCollection := dbRef.Collection("peminjaman")
-
-	// Pipeline aggregation untuk join ke barang dan kategori
-	pipeline := mongo.Pipeline{
-		// Join dengan koleksi barang
-		{{Key: "$lookup", Value: bson.M{
-			"from":         "barang",
-			"localField":   "barang_id",
-			"foreignField": "_id",
-			"as":           "barang_info",
-		}}},
-		// Unwind array barang_info jadi objek tunggal
-		{{Key: "$unwind", Value: "$barang_info"}},
-		// Join dengan kategori
-		{{Key: "$lookup", Value: bson.M{
-			"from":         "kategori",
-			"localField":   "barang_info.kategori_id",
-			"foreignField": "_id",
-			"as":           "kategori_info",
-		}}},
-		// Unwind kategori
-		{{Key: "$unwind", Value: "$kategori_info"}},
+	// Join peminjaman ke barang dan kategori
+	hasil, err := peminjamanRepo.Laporan(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Data pribadi peminjam hanya terlihat dengan permission laporan:pii
+	pii := middlewares.HasPermission(c, models.PermLaporanPII)
+	if !pii {
+		for _, baris := range hasil {
+			samarkanBaris(baris)
+		}
+	}
+
+	// Laporan tidak dikirim jika pengambilannya gagal dicatat
+	if err := catatAudit(c, models.AuditLaporanPeminjaman, map[string]interface{}{
+		"jumlah_baris": len(hasil),
+		"pii":          pii,
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencatat audit log"})
 	}
 
-	cursor, err := peminjamanCollection.Aggregate(ctx, pipeline)
+	// Return data gabungan lengkap
+	return c.JSON(hasil)
+}
+
+// peminjamanTerlambat adalah satu baris laporan keterlambatan
+type peminjamanTerlambat struct {
+	models.Peminjaman
+	NamaBarang []string `json:"nama_barang"`
+}
+
+// GetLaporanTerlambat godoc
+// @Summary Get laporan peminjaman terlambat
+// @Description Mengambil peminjaman yang masih dipinjam dan sudah melewati tanggal jatuh tempo, paling lama terlambat lebih dulu. Butuh permission laporan:read; data pribadi peminjam disamarkan tanpa laporan:pii. Setiap pengambilan laporan dicatat di audit log.
+// @Tags Laporan
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Daftar peminjaman terlambat"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Tidak memiliki permission laporan:read"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /laporan/terlambat [get]
+func GetLaporanTerlambat(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	peminjaman, err := peminjamanRepo.FindTerlambat(ctx, now.Format(models.FormatTanggal))
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	var hasil []bson.M
-	if err := cursor.All(ctx, &hasil); err != nil {
+	// Nama barang untuk setiap peminjaman, termasuk barang yang sudah dihapus
+	barang, _, err := barangRepo.FindAll(ctx, repository.BarangFilter{TermasukTerhapus: true}, repository.ListOptions{})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	namaBarang := map[primitive.ObjectID]string{}
+	for _, b := range barang {
+		namaBarang[b.ID] = b.Nama
+	}
 
-	// Return data gabungan lengkap
-	return c.JSON(hasil)
+	pii := middlewares.HasPermission(c, models.PermLaporanPII)
+	hasil := []peminjamanTerlambat{}
+	for _, p := range peminjaman {
+		p.HitungKeterlambatan(now)
+		if !pii {
+			p.NamaPeminjam = samarkanNama(p.NamaPeminjam)
+			p.EmailPeminjam = samarkanEmail(p.EmailPeminjam)
+			p.TeleponPeminjam = samarkanTelepon(p.TeleponPeminjam)
+		}
+
+		// Hanya barang yang belum dikembalikan
+		nama := []string{}
+		for _, item := range p.Items {
+			if item.SisaPinjam() > 0 {
+				nama = append(nama, namaBarang[item.BarangID])
+			}
+		}
+
+		hasil = append(hasil, peminjamanTerlambat{
+			Peminjaman: p,
+			NamaBarang: nama,
+		})
+	}
+
+	if err := catatAudit(c, models.AuditLaporanTerlambat, map[string]interface{}{
+		"jumlah_baris": len(hasil),
+		"pii":          pii,
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencatat audit log"})
+	}
+
+	return c.JSON(fiber.Map{
+		"total": len(hasil),
+		"data":  hasil,
+	})
+}
+
+// samarkanBaris menyamarkan data pribadi peminjam di satu baris laporan
+func samarkanBaris(baris bson.M) {
+	if nama, ok := baris["nama_peminjam"].(string); ok {
+		baris["nama_peminjam"] = samarkanNama(nama)
+	}
+	if email, ok := baris["email_peminjam"].(string); ok {
+		baris["email_peminjam"] = samarkanEmail(email)
+	}
+	if telepon, ok := baris["telepon_peminjam"].(string); ok {
+		baris["telepon_peminjam"] = samarkanTelepon(telepon)
+	}
+}
+
+// samarkanNama menyisakan huruf pertama setiap kata, mis. "Budi Santoso" menjadi "B*** S***"
+func samarkanNama(nama string) string {
+	kata := strings.Fields(nama)
+	for i, k := range kata {
+		kata[i] = string([]rune(k)[:1]) + "***"
+	}
+	return strings.Join(kata, " ")
+}
+
+// samarkanEmail menyisakan huruf pertama dan domain, mis. "budi@x.com" menjadi "b***@x.com"
+func samarkanEmail(email string) string {
+	lokal, domain, ok := strings.Cut(email, "@")
+	if !ok || lokal == "" {
+		return "***"
+	}
+	return string([]rune(lokal)[:1]) + "***@" + domain
+}
+
+// samarkanTelepon menyisakan 4 digit awal dan 2 digit akhir, mis. "081234567890" menjadi "0812******90"
+func samarkanTelepon(telepon string) string {
+	if len(telepon) < 8 {
+		return strings.Repeat("*", len(telepon))
+	}
+	return telepon[:4] + strings.Repeat("*", len(telepon)-6) + telepon[len(telepon)-2:]
 }
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// hariLalu mengembalikan tanggal n hari sebelum hari ini
+func hariLalu(n int) string {
+	return time.Now().AddDate(0, 0, -n).Format(models.FormatTanggal)
+}
+
+func TestCreatePeminjamanJatuhTempo(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	barang := siapkanBarang(t, "Proyektor", 2)
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanCreate, models.PermPeminjamanCreateForOthers)
+	app.Post("/peminjaman", CreatePeminjaman)
+
+	tests := []struct {
+		tanggal string
+		status  int
+	}{
+		{"", 400},
+		{"17-10-2026", 400},
+		{hariLalu(1), 400},
+		{hariLalu(0), 201},
+	}
+	for _, tt := range tests {
+		status, body := kirimJSON(t, app, "POST", "/peminjaman",
+			`{"nama_peminjam":"Tamu","email_peminjam":"tamu@example.com","telepon_peminjam":"081234567890",`+
+				`"items":[{"barang_id":"`+barang.ID.Hex()+`","jumlah":1}],"tanggal_jatuh_tempo":"`+tt.tanggal+`"}`)
+		if status != tt.status {
+			t.Errorf("jatuh tempo %q: status = %d, body = %v, ingin %d", tt.tanggal, status, body, tt.status)
+		}
+	}
+}
+
+// TestLaporanTerlambat memastikan laporan hanya berisi peminjaman yang masih
+// dipinjam dan sudah lewat jatuh tempo, paling lama terlambat lebih dulu,
+// dengan nama barang yang belum dikembalikan saja
+func TestLaporanTerlambat(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	proyektor := siapkanBarang(t, "Proyektor", 5)
+	kabel := siapkanBarang(t, "Kabel", 5)
+
+	buat := func(nama, status, jatuhTempo string, items ...models.ItemPeminjaman) {
+		t.Helper()
+		p := models.Peminjaman{ID: primitive.NewObjectID(), NamaPeminjam: nama, Status: status, TanggalJatuhTempo: jatuhTempo, Items: items}
+		if err := peminjamanRepo.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buat("Terlambat Dua Hari", models.StatusDipinjam, hariLalu(2),
+		models.ItemPeminjaman{BarangID: proyektor.ID, Jumlah: 1})
+	buat("Terlambat Lima Hari", models.StatusDipinjam, hariLalu(5),
+		models.ItemPeminjaman{BarangID: proyektor.ID, Jumlah: 1, JumlahKembali: 1},
+		models.ItemPeminjaman{BarangID: kabel.ID, Jumlah: 2})
+	buat("Jatuh Tempo Hari Ini", models.StatusDipinjam, hariLalu(0),
+		models.ItemPeminjaman{BarangID: proyektor.ID, Jumlah: 1})
+	buat("Sudah Kembali", models.StatusDikembalikan, hariLalu(3),
+		models.ItemPeminjaman{BarangID: proyektor.ID, Jumlah: 1, JumlahKembali: 1})
+	buat("Belum Disetujui", models.StatusDiajukan, hariLalu(3),
+		models.ItemPeminjaman{BarangID: proyektor.ID, Jumlah: 1})
+
+	app := appPengguna(primitive.NewObjectID(), models.PermLaporanRead, models.PermLaporanPII)
+	app.Get("/laporan/terlambat", GetLaporanTerlambat)
+
+	status, body := kirimJSON(t, app, "GET", "/laporan/terlambat", "")
+	if status != 200 {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	data := body["data"].([]interface{})
+	if body["total"] != float64(2) || len(data) != 2 {
+		t.Fatalf("laporan = %v, ingin 2 peminjaman terlambat", body)
+	}
+
+	ingin := []struct {
+		nama   string
+		hari   float64
+		barang []interface{}
+	}{
+		{"Terlambat Lima Hari", 5, []interface{}{"Kabel"}},
+		{"Terlambat Dua Hari", 2, []interface{}{"Proyektor"}},
+	}
+	for i, baris := range data {
+		baris := baris.(map[string]interface{})
+		if baris["nama_peminjam"] != ingin[i].nama || baris["terlambat"] != true || baris["hari_terlambat"] != ingin[i].hari {
+			t.Errorf("baris %d = %v terlambat %v hari %v, ingin %s %v hari",
+				i, baris["nama_peminjam"], baris["terlambat"], baris["hari_terlambat"], ingin[i].nama, ingin[i].hari)
+		}
+		nama, _ := baris["nama_barang"].([]interface{})
+		if len(nama) != len(ingin[i].barang) || nama[0] != ingin[i].barang[0] {
+			t.Errorf("baris %d nama_barang = %v, ingin %v", i, nama, ingin[i].barang)
+		}
+	}
+}
+
+// TestLaporanPeminjamanDilindungi memastikan laporan butuh laporan:read, data
+// pribadi peminjam hanya utuh dengan laporan:pii, setiap pengambilan dicatat di
+// audit log, dan laporan tidak dikirim jika audit gagal dicatat
+func TestLaporanPeminjamanDilindungi(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	SetRepositories(repos)
+	ctx := context.Background()
+	barang := siapkanBarang(t, "Proyektor", 2)
+	p := models.Peminjaman{
+		ID:              primitive.NewObjectID(),
+		NamaPeminjam:    "Budi Santoso",
+		EmailPeminjam:   "budi@contoh.com",
+		TeleponPeminjam: "081234567890",
+		Items:           []models.ItemPeminjaman{{BarangID: barang.ID, Jumlah: 1}},
+		Status:          models.StatusDiajukan,
+	}
+	if err := peminjamanRepo.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	ambil := func(perms ...string) (int, []map[string]interface{}) {
+		t.Helper()
+		app := appPengguna(primitive.NewObjectID(), perms...)
+		app.Get("/laporan/peminjaman", middlewares.RequirePermission(models.PermLaporanRead), GetLaporanPeminjaman)
+		resp, err := app.Test(httptest.NewRequest("GET", "/laporan/peminjaman", nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var baris []map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&baris)
+		return resp.StatusCode, baris
+	}
+
+	if status, _ := ambil(models.PermPeminjamanCreate); status != 403 {
+		t.Errorf("tanpa laporan:read: status = %d, ingin 403", status)
+	}
+
+	tests := []struct {
+		perms                []string
+		nama, email, telepon string
+	}{
+		{[]string{models.PermLaporanRead}, "B*** S***", "b***@contoh.com", "0812******90"},
+		{[]string{models.PermLaporanRead, models.PermLaporanPII}, "Budi Santoso", "budi@contoh.com", "081234567890"},
+	}
+	for _, tt := range tests {
+		status, baris := ambil(tt.perms...)
+		if status != 200 || len(baris) != 1 {
+			t.Fatalf("%v: status = %d, baris = %v", tt.perms, status, baris)
+		}
+		b := baris[0]
+		if b["nama_peminjam"] != tt.nama || b["email_peminjam"] != tt.email || b["telepon_peminjam"] != tt.telepon {
+			t.Errorf("%v: peminjam = %v/%v/%v, ingin %s/%s/%s", tt.perms,
+				b["nama_peminjam"], b["email_peminjam"], b["telepon_peminjam"], tt.nama, tt.email, tt.telepon)
+		}
+	}
+
+	logs, _, err := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: models.AuditLaporanPeminjaman}, repository.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("audit laporan = %d, ingin 2 (tanpa permission tidak dicatat)", len(logs))
+	}
+	pii := map[interface{}]bool{}
+	for _, l := range logs {
+		pii[l.Detail["pii"]] = true
+	}
+	if !pii[true] || !pii[false] {
+		t.Errorf("detail pii audit = %v, ingin satu true dan satu false", pii)
+	}
+
+	gagal := *repos
+	gagal.AuditLog = auditGagal{repos.AuditLog}
+	SetRepositories(&gagal)
+	if status, baris := ambil(models.PermLaporanRead, models.PermLaporanPII); status != 500 || len(baris) != 0 {
+		t.Errorf("audit gagal: status = %d, baris = %v, ingin 500 tanpa data", status, baris)
+	}
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/config"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// jedaLoginMaks membatasi jeda progresif antar percobaan login gagal
+const jedaLoginMaks = 30 * time.Second
+
+// kunciEmail dan kunciIP adalah kunci hitungan login gagal. Email dihitung
+// walau tidak terdaftar agar response tidak membocorkan email mana yang ada.
+func kunciEmail(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func kunciIP(ip string) string {
+	return "ip:" + ip
+}
+
+// kunciIPRequest adalah kunci IP client request. c.IP() membaca PROXY_HEADER
+// jika diatur di fiber.Config, sehingga di belakang proxy yang dipakai IP
+// client, bukan IP proxy; config.CekProxyHeader memastikan header itu diisi
+// di production.
+func kunciIPRequest(c *fiber.Ctx) string {
+	return kunciIP(c.IP())
+}
+
+// jedaLogin adalah waktu tunggu minimal setelah login gagal ke-n: dua kali
+// gagal pertama tanpa jeda, lalu 1 detik dan terus berlipat dua sampai jedaLoginMaks
+func jedaLogin(gagal int) time.Duration {
+	if gagal < 3 {
+		return 0
+	}
+	if gagal-3 >= 5 {
+		return jedaLoginMaks
+	}
+	return min(time.Second<<(gagal-3), jedaLoginMaks)
+}
+
+// tungguLogin mengembalikan berapa lama client harus menunggu sebelum boleh
+// mencoba login lagi, dilihat dari kunci sementara dan jeda progresif
+func tungguLogin(ctx context.Context, kunci ...string) (time.Duration, error) {
+	now := time.Now()
+	var tunggu time.Duration
+	for _, k := range kunci {
+		attempt, err := loginAttemptRepo.Find(ctx, k)
+		if err == repository.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if attempt.Terkunci(now) {
+			tunggu = max(tunggu, attempt.TerkunciSampai.Sub(now))
+		}
+		tunggu = max(tunggu, attempt.TerakhirGagal.Add(jedaLogin(attempt.Gagal)).Sub(now))
+	}
+	return tunggu, nil
+}
+
+// catatLoginGagal menambah hitungan gagal untuk email dan IP request, lalu
+// mengunci yang melewati batas dan mencatatnya di audit log. user boleh nil
+// jika email tidak terdaftar. Mengembalikan lama kunci jika ada yang baru dikunci.
+func catatLoginGagal(c *fiber.Ctx, email string, user *models.User) (time.Duration, error) {
+	ctx := context.Background()
+	jendela := config.LoginJendela()
+	lamaKunci := config.LoginLamaKunci()
+	now := time.Now()
+
+	batas := []struct {
+		kunci string
+		maks  int
+	}{
+		{kunciEmail(email), config.LoginMaksGagal()},
+		{kunciIPRequest(c), config.LoginMaksGagalIP()},
+	}
+
+	var dikunci time.Duration
+	for _, b := range batas {
+		attempt, err := loginAttemptRepo.CatatGagal(ctx, b.kunci, jendela, now.Add(jendela))
+		if err != nil {
+			return 0, err
+		}
+		if attempt.Gagal < b.maks || attempt.Terkunci(now) {
+			continue
+		}
+
+		sampai := now.Add(lamaKunci)
+		if err := loginAttemptRepo.Kunci(ctx, b.kunci, sampai); err != nil {
+			return 0, err
+		}
+		detail := map[string]interface{}{
+			"kunci":           b.kunci,
+			"gagal":           attempt.Gagal,
+			"terkunci_sampai": sampai,
+		}
+		if user != nil && b.kunci == kunciEmail(email) {
+			detail["user_id"] = user.ID
+		}
+		if err := catatAudit(c, models.AuditLoginDikunci, detail); err != nil {
+			return 0, err
+		}
+		dikunci = lamaKunci
+	}
+	return dikunci, nil
+}
+
+// tolakLogin membalas 429 dengan header Retry-After
+func tolakLogin(c *fiber.Ctx, tunggu time.Duration) error {
+	detik := int(math.Ceil(tunggu.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(detik))
+	return c.Status(429).JSON(fiber.Map{
+		"error":       "Terlalu banyak percobaan login gagal. Coba lagi dalam " + strconv.Itoa(detik) + " detik",
+		"retry_after": detik,
+	})
+}
+
+// UnlockUser godoc
+// @Summary Buka kunci login user
+// @Description Menghapus hitungan login gagal dan kunci sementara pada akun user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "Kunci login dibuka"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 403 {object} map[string]interface{} "Role user melebihi permission pemanggil"
+// @Failure 404 {object} map[string]interface{} "User tidak ditemukan"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/unlock [post]
+func UnlockUser(c *fiber.Ctx) error {
+	user, err := cariUserDikelola(c)
+	if user == nil {
+		return err
+	}
+
+	if err := loginAttemptRepo.Reset(context.Background(), kunciEmail(user.Email)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := catatAudit(c, models.AuditBukaKunciLogin, map[string]interface{}{
+		"kunci":   kunciEmail(user.Email),
+		"user_id": user.ID,
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Kunci login user " + user.Email + " berhasil dibuka"})
+}
+
+// UnlockIP godoc
+// @Summary Buka kunci login IP
+// @Description Menghapus hitungan login gagal dan kunci sementara pada satu alamat IP
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body object{ip=string} true "Alamat IP"
+// @Success 200 {object} map[string]interface{} "Kunci login dibuka"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/unlock-ip [post]
+func UnlockIP(c *fiber.Ctx) error {
+	var body struct {
+		IP string `json:"ip"`
+	}
+	if err := c.BodyParser(&body); err != nil || strings.TrimSpace(body.IP) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ip wajib diisi"})
+	}
+
+	kunci := kunciIP(strings.TrimSpace(body.IP))
+	if err := loginAttemptRepo.Reset(context.Background(), kunci); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := catatAudit(c, models.AuditBukaKunciLogin, map[string]interface{}{"kunci": kunci}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Kunci login IP " + strings.TrimSpace(body.IP) + " berhasil dibuka"})
+}
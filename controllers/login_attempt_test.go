@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"inventory-backend/validators"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestKunciLogin(t *testing.T) {
+	if got := kunciEmail("  Budi@Example.COM "); got != "email:budi@example.com" {
+		t.Errorf("kunciEmail = %q, ingin email:budi@example.com", got)
+	}
+
+	tests := []struct {
+		nama   string
+		proxy  string
+		header string
+		ingin  string
+	}{
+		// Tanpa PROXY_HEADER header dari client diabaikan dan IP koneksi dipakai
+		{"tanpa proxy", "", "203.0.113.7", "ip:0.0.0.0"},
+		{"di belakang proxy", "X-Real-IP", "203.0.113.7", "ip:203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ProxyHeader: tt.proxy})
+			app.Get("/", func(c *fiber.Ctx) error {
+				return c.SendString(kunciIPRequest(c))
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-Real-IP", tt.header)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if got := string(body); got != tt.ingin {
+				t.Errorf("kunciIPRequest = %q, ingin %q", got, tt.ingin)
+			}
+		})
+	}
+}
+
+func TestJedaLogin(t *testing.T) {
+	tests := []struct {
+		gagal int
+		ingin time.Duration
+	}{
+		{1, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{7, 16 * time.Second},
+		{8, jedaLoginMaks},
+		{50, jedaLoginMaks},
+	}
+	for _, tt := range tests {
+		if got := jedaLogin(tt.gagal); got != tt.ingin {
+			t.Errorf("jedaLogin(%d) = %v, ingin %v", tt.gagal, got, tt.ingin)
+		}
+	}
+}
+
+// TestLoginDikunci memastikan akun dikunci setelah batas login gagal, login
+// dengan password benar tetap ditolak selama terkunci, kunci tercatat di audit
+// log, dan login kembali bisa dipakai setelah kunci habis atau dibuka admin
+func TestLoginDikunci(t *testing.T) {
+	t.Setenv("JWT_SECRET", "rahasia-uji-kunci-login-yang-cukup-panjang")
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "2")
+	t.Setenv("LOGIN_LOCKOUT_DURATION", "300ms")
+	tokens, err := services.NewTokenServiceFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTokenService(tokens)
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Password: string(hash), Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(primitive.NewObjectID())
+	app.Post("/auth/login", validators.ValidateLogin, Login)
+	app.Post("/users/:id/unlock", UnlockUser)
+	app.Post("/users/unlock-ip", UnlockIP)
+	login := func(password string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(`{"email":"Budi@Example.com","password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter)
+	}
+
+	if status, _ := login("salah"); status != 401 {
+		t.Fatalf("gagal pertama: status = %d, ingin 401", status)
+	}
+	if status, retry := login("salah"); status != 429 || retry != "1" {
+		t.Fatalf("gagal kedua: status = %d, Retry-After = %q, ingin 429 dan 1", status, retry)
+	}
+	if status, _ := login("rahasia123"); status != 429 {
+		t.Fatalf("password benar saat terkunci: status = %d, ingin 429", status)
+	}
+
+	logs, _, err := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: models.AuditLoginDikunci}, repository.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].Detail["kunci"] != kunciEmail(user.Email) || logs[0].Detail["user_id"] != user.ID {
+		t.Fatalf("audit kunci = %+v, ingin satu catatan untuk %s", logs, user.Email)
+	}
+
+	// Kunci habis dengan sendirinya
+	time.Sleep(350 * time.Millisecond)
+	if status, _ := login("rahasia123"); status != 200 {
+		t.Fatalf("setelah kunci habis: status = %d, ingin 200", status)
+	}
+
+	// Login berhasil mereset hitungan, jadi dua kali gagal lagi baru dikunci
+	if status, _ := login("salah"); status != 401 {
+		t.Fatalf("gagal setelah reset: status = %d, ingin 401", status)
+	}
+	if status, _ := login("salah"); status != 429 {
+		t.Fatalf("gagal kedua setelah reset: status = %d, ingin 429", status)
+	}
+	if status, body := kirimJSON(t, app, "POST", "/users/"+user.ID.Hex()+"/unlock", ""); status != 200 {
+		t.Fatalf("unlock: status = %d, body = %v", status, body)
+	}
+	// Hitungan IP tidak direset login berhasil, jadi IP masih dalam jeda
+	if status, _ := login("rahasia123"); status != 429 {
+		t.Fatalf("IP masih dalam jeda: status = %d, ingin 429", status)
+	}
+	if status, body := kirimJSON(t, app, "POST", "/users/unlock-ip", `{"ip":"0.0.0.0"}`); status != 200 {
+		t.Fatalf("unlock ip: status = %d, body = %v", status, body)
+	}
+	if status, _ := login("rahasia123"); status != 200 {
+		t.Errorf("setelah dibuka admin: status = %d, ingin 200", status)
+	}
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SesiResponse adalah sesi aktif beserta penanda sesi yang sedang dipakai
+type SesiResponse struct {
+	models.Session
+	SaatIni bool `json:"saat_ini"`
+}
+
+// catatRiwayat menyimpan satu kejadian login, refresh atau logout beserta
+// IP dan user agent request
+func catatRiwayat(ctx context.Context, c *fiber.Ctx, riwayat models.LoginHistory) error {
+	// Nilai dari fiber.Ctx dipakai ulang setelah request selesai, jadi disalin
+	riwayat.IP = strings.Clone(c.IP())
+	riwayat.UserAgent = strings.Clone(c.Get("User-Agent"))
+	riwayat.Tanggal = time.Now()
+	return loginHistoryRepo.Create(ctx, &riwayat)
+}
+
+// riwayatSesi mengisi user dan sesi pada riwayat dari data sesi
+func riwayatSesi(jenis string, session *models.Session) models.LoginHistory {
+	return models.LoginHistory{
+		Jenis:     jenis,
+		UserID:    &session.UserID,
+		SessionID: &session.ID,
+		Metode:    session.Metode,
+		Dengan2FA: session.Dengan2FA,
+	}
+}
+
+// riwayatLoginGagal menyiapkan riwayat login gagal; user boleh nil jika email
+// tidak terdaftar
+func riwayatLoginGagal(email string, user *models.User, keterangan string) models.LoginHistory {
+	riwayat := models.LoginHistory{
+		Jenis:      models.RiwayatLoginGagal,
+		Email:      email,
+		Keterangan: keterangan,
+	}
+	if user != nil {
+		riwayat.UserID = &user.ID
+	}
+	return riwayat
+}
+
+// GetSessions godoc
+// @Summary Daftar sesi aktif
+// @Description Mengambil semua sesi login user yang masih aktif beserta IP, user agent dan waktu terakhir dipakai. Sesi yang sedang dipakai ditandai saat_ini.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Daftar sesi aktif"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/sessions [get]
+func GetSessions(c *fiber.Ctx) error {
+	sessions, err := sessionRepo.FindAktifByUser(context.Background(), currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	saatIni, _ := c.Locals("session_id").(primitive.ObjectID)
+	data := make([]SesiResponse, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, SesiResponse{Session: session, SaatIni: session.ID == saatIni})
+	}
+	return c.JSON(fiber.Map{"data": data})
+}
+
+// RevokeSession godoc
+// @Summary Cabut satu sesi
+// @Description Mencabut satu sesi milik user, mis. login di perangkat yang hilang. Mencabut sesi yang sedang dipakai sama dengan logout.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{} "Sesi dicabut"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Sesi tidak ditemukan"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/sessions/{id} [delete]
+func RevokeSession(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	ctx := context.Background()
+	session, err := sessionRepo.FindByID(ctx, id)
+	// Sesi milik user lain diperlakukan seperti tidak ada
+	if err == repository.ErrNotFound || (err == nil && session.UserID != currentUserID(c)) {
+		return c.Status(404).JSON(fiber.Map{"error": "Sesi tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !session.Aktif(time.Now()) {
+		return c.Status(404).JSON(fiber.Map{"error": "Sesi sudah berakhir"})
+	}
+
+	if err := sessionRepo.Revoke(ctx, session.ID, models.SesiDicabutUser); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := catatRiwayat(context.Background(), c, riwayatSesi(models.RiwayatSesiDicabut, session)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Sesi berhasil dicabut"})
+}
+
+// GetLoginHistory godoc
+// @Summary Riwayat login sendiri
+// @Description Mengambil riwayat login, login gagal, refresh dan logout user yang sedang login, yang terbaru lebih dulu
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID riwayat terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Riwayat login"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/login-history [get]
+func GetLoginHistory(c *fiber.Ctx) error {
+	return daftarRiwayat(c, currentUserID(c))
+}
+
+// GetLoginHistoryUser godoc
+// @Summary Riwayat login user
+// @Description Mengambil riwayat login, login gagal, refresh dan logout satu user untuk review keamanan, yang terbaru lebih dulu
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID riwayat terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Riwayat login"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 404 {object} map[string]interface{} "User tidak ditemukan"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/login-history [get]
+func GetLoginHistoryUser(c *fiber.Ctx) error {
+	user, err := cariUser(c)
+	if user == nil {
+		return err
+	}
+
+	return daftarRiwayat(c, user.ID)
+}
+
+// daftarRiwayat mengirim riwayat login userID dengan pagination
+func daftarRiwayat(c *fiber.Ctx, userID primitive.ObjectID) error {
+	opts, err := parseListOptions(c, map[string]string{"tanggal": "tanggal"})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	riwayat, total, err := loginHistoryRepo.FindByUser(context.Background(), userID, opts)
+	if err != nil {
+		return errorList(c, err, "")
+	}
+	return c.JSON(paginated(c, riwayat, opts, total, func(h models.LoginHistory) primitive.ObjectID { return h.ID }))
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ubahStok mengubah stok barang dan mencatatnya di ledger mutasi stok.
+// Harus dipanggil di dalam transaksi agar stok dan ledger selalu sinkron.
+func ubahStok(ctx context.Context, barangID primitive.ObjectID, delta int, alasan string, userID primitive.ObjectID, peminjamanID *primitive.ObjectID) error {
+	if delta == 0 {
+		return nil
+	}
+
+	if err := barangRepo.IncrementStok(ctx, barangID, delta); err != nil {
+		return err
+	}
+
+	return mutasiRepo.Create(ctx, &models.MutasiStok{
+		BarangID:     barangID,
+		Delta:        delta,
+		Alasan:       alasan,
+		PeminjamanID: peminjamanID,
+		UserID:       userID,
+		Tanggal:      time.Now(),
+	})
+}
+
+// GetMutasiBarang godoc
+// @Summary Get mutasi stok barang
+// @Description Mengambil riwayat mutasi stok sebuah barang, terbaru lebih dulu
+// @Tags Barang
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Barang ID"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID mutasi terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Riwayat mutasi stok"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 404 {object} map[string]interface{} "Barang tidak ditemukan"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /barang/{id}/mutasi [get]
+func GetMutasiBarang(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	if _, err := barangRepo.FindByID(context.Background(), id); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan"})
+	}
+
+	opts, err := parseListOptions(c, map[string]string{"tanggal": "tanggal"})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	mutasi, total, err := mutasiRepo.FindByBarang(context.Background(), id, opts)
+	if err != nil {
+		return errorList(c, err, "")
+	}
+
+	return c.JSON(paginated(c, mutasi, opts, total, func(m models.MutasiStok) primitive.ObjectID { return m.ID }))
+}
+
+// selisihStok adalah barang yang stoknya tidak sama dengan jumlah ledger
+type selisihStok struct {
+	BarangID    primitive.ObjectID `json:"barang_id"`
+	Nama        string             `json:"nama"`
+	Stok        int                `json:"stok"`
+	TotalMutasi int                `json:"total_mutasi"`
+	Selisih     int                `json:"selisih"`
+}
+
+func cariSelisihStok(ctx context.Context) ([]selisihStok, error) {
+	barang, _, err := barangRepo.FindAll(ctx, repository.BarangFilter{}, repository.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := mutasiRepo.SumPerBarang(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hasil := []selisihStok{}
+	for _, b := range barang {
+		if b.Stok != total[b.ID] {
+			hasil = append(hasil, selisihStok{
+				BarangID:    b.ID,
+				Nama:        b.Nama,
+				Stok:        b.Stok,
+				TotalMutasi: total[b.ID],
+				Selisih:     b.Stok - total[b.ID],
+			})
+		}
+	}
+	return hasil, nil
+}
+
+// GetRekonsiliasiStok godoc
+// @Summary Rekonsiliasi stok
+// @Description Menampilkan barang yang field stok-nya tidak sama dengan jumlah ledger mutasi stok
+// @Tags Stok
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Daftar barang yang tidak sinkron"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /stok/rekonsiliasi [get]
+func GetRekonsiliasiStok(c *fiber.Ctx) error {
+	hasil, err := cariSelisihStok(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"sinkron": len(hasil) == 0,
+		"data":    hasil,
+	})
+}
+
+// KoreksiStok godoc
+// @Summary Koreksi ledger stok
+// @Description Menambahkan mutasi 'correction' agar ledger sama dengan stok saat ini (misalnya untuk barang lama sebelum ada ledger). Stok barang tidak diubah.
+// @Tags Stok
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Ledger berhasil dikoreksi"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /stok/rekonsiliasi [post]
+func KoreksiStok(c *fiber.Ctx) error {
+	userID := currentUserID(c)
+
+	var dikoreksi []selisihStok
+	err := txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		hasil, err := cariSelisihStok(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range hasil {
+			err := mutasiRepo.Create(ctx, &models.MutasiStok{
+				BarangID: s.BarangID,
+				Delta:    s.Selisih,
+				Alasan:   models.MutasiKoreksi,
+				UserID:   userID,
+				Tanggal:  time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+			err = catatPerubahan(ctx, c, models.AuditKoreksiStok, models.ResourceBarang, s.BarangID, nil, nil, map[string]interface{}{
+				"stok":         s.Stok,
+				"total_mutasi": s.TotalMutasi,
+				"selisih":      s.Selisih,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		dikoreksi = hasil
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Ledger stok berhasil dikoreksi",
+		"data":    dikoreksi,
+	})
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestMutasiStokBarang memastikan stok awal dan perubahan stok manual tercatat
+// di ledger, dan jumlah ledger selalu sama dengan stok barang
+func TestMutasiStokBarang(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	kategori := models.Kategori{ID: primitive.NewObjectID(), Nama: "Elektronik"}
+	if err := kategoriRepo.Create(ctx, &kategori); err != nil {
+		t.Fatal(err)
+	}
+
+	userID := primitive.NewObjectID()
+	app := appPengguna(userID, models.PermBarangWrite)
+	app.Post("/barang", CreateBarang)
+	app.Put("/barang/:id", UpdateBarang)
+	app.Get("/barang/:id/mutasi", GetMutasiBarang)
+
+	status, body := kirimJSON(t, app, "POST", "/barang", `{"nama":"Proyektor","kategori_id":"`+kategori.ID.Hex()+`","stok":5}`)
+	if status != 201 {
+		t.Fatalf("buat barang: status = %d, body = %v", status, body)
+	}
+	id := body["id"].(string)
+
+	status, body = kirimJSON(t, app, "PUT", "/barang/"+id, `{"nama":"Proyektor","kategori_id":"`+kategori.ID.Hex()+`","stok":3}`)
+	if status != 200 {
+		t.Fatalf("ubah barang: status = %d, body = %v", status, body)
+	}
+
+	status, body = kirimJSON(t, app, "GET", "/barang/"+id+"/mutasi", "")
+	if status != 200 {
+		t.Fatalf("mutasi: status = %d, body = %v", status, body)
+	}
+	data := body["data"].([]interface{})
+	ingin := []struct {
+		alasan string
+		delta  float64
+	}{
+		// Terbaru lebih dulu
+		{models.MutasiPenyesuaian, -2},
+		{models.MutasiAwal, 5},
+	}
+	if len(data) != len(ingin) {
+		t.Fatalf("jumlah mutasi = %d, ingin %d: %v", len(data), len(ingin), data)
+	}
+	for i, m := range data {
+		m := m.(map[string]interface{})
+		if m["alasan"] != ingin[i].alasan || m["delta"] != ingin[i].delta {
+			t.Errorf("mutasi[%d] = %v %v, ingin %s %v", i, m["alasan"], m["delta"], ingin[i].alasan, ingin[i].delta)
+		}
+		if m["user_id"] != userID.Hex() {
+			t.Errorf("mutasi[%d] user_id = %v, ingin %s", i, m["user_id"], userID.Hex())
+		}
+	}
+
+	status, _ = kirimJSON(t, app, "GET", "/barang/"+primitive.NewObjectID().Hex()+"/mutasi", "")
+	if status != 404 {
+		t.Errorf("mutasi barang tidak ada: status = %d, ingin 404", status)
+	}
+}
+
+// TestRekonsiliasiStok memastikan barang yang stoknya berubah tanpa ledger
+// dilaporkan, lalu koreksi menyamakan ledger tanpa mengubah stok
+func TestRekonsiliasiStok(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+
+	sinkron := siapkanBarang(t, "Kabel", 0)
+	if err := ubahStok(ctx, sinkron.ID, 4, models.MutasiAwal, primitive.NewObjectID(), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Barang lama yang stoknya belum pernah dicatat di ledger
+	lama := siapkanBarang(t, "Proyektor", 3)
+
+	app := appPengguna(primitive.NewObjectID(), models.PermStokRead, models.PermStokWrite)
+	app.Get("/stok/rekonsiliasi", GetRekonsiliasiStok)
+	app.Post("/stok/rekonsiliasi", KoreksiStok)
+
+	status, body := kirimJSON(t, app, "GET", "/stok/rekonsiliasi", "")
+	if status != 200 {
+		t.Fatalf("rekonsiliasi: status = %d, body = %v", status, body)
+	}
+	data := body["data"].([]interface{})
+	if body["sinkron"] != false || len(data) != 1 {
+		t.Fatalf("rekonsiliasi = %v, ingin satu barang tidak sinkron", body)
+	}
+	selisih := data[0].(map[string]interface{})
+	if selisih["barang_id"] != lama.ID.Hex() || selisih["stok"] != float64(3) || selisih["total_mutasi"] != float64(0) || selisih["selisih"] != float64(3) {
+		t.Errorf("selisih = %v, ingin %s stok 3 mutasi 0", selisih, lama.ID.Hex())
+	}
+
+	status, body = kirimJSON(t, app, "POST", "/stok/rekonsiliasi", "")
+	if status != 200 {
+		t.Fatalf("koreksi: status = %d, body = %v", status, body)
+	}
+
+	status, body = kirimJSON(t, app, "GET", "/stok/rekonsiliasi", "")
+	if status != 200 || body["sinkron"] != true {
+		t.Errorf("rekonsiliasi setelah koreksi = %d %v, ingin sinkron", status, body)
+	}
+	b, err := barangRepo.FindByID(ctx, lama.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Stok != 3 {
+		t.Errorf("stok setelah koreksi = %d, ingin tetap 3", b.Stok)
+	}
+	mutasi, _, err := mutasiRepo.FindByBarang(ctx, lama.ID, repository.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mutasi) != 1 || mutasi[0].Alasan != models.MutasiKoreksi || mutasi[0].Delta != 3 {
+		t.Errorf("mutasi koreksi = %+v, ingin satu correction +3", mutasi)
+	}
+}
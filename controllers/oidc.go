@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"inventory-backend/config"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// cookieOIDC menyimpan state, nonce dan PKCE verifier selama user berada di provider
+	cookieOIDC = "oidc_state"
+	tujuanOIDC = "oidc"
+	lamaOIDC   = 10 * time.Minute
+)
+
+// OIDCLogin godoc
+// @Summary Login SSO
+// @Description Mengarahkan browser ke identity provider OpenID Connect. Setelah login di provider, browser kembali ke /auth/oidc/callback.
+// @Tags Authentication
+// @Success 302 "Redirect ke identity provider"
+// @Failure 404 {object} map[string]interface{} "SSO tidak dikonfigurasi"
+// @Failure 502 {object} map[string]interface{} "Identity provider tidak bisa dihubungi"
+// @Router /auth/oidc/login [get]
+func OIDCLogin(c *fiber.Ctx) error {
+	if oidcProvider == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "SSO tidak dikonfigurasi"})
+	}
+
+	state, err := services.BuatState()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	nonce, err := services.BuatState()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	verifier, challenge, err := services.BuatPKCE()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	authURL, err := oidcProvider.AuthURL(context.Background(), state, nonce, challenge)
+	if err != nil {
+		log.Printf("Warning: login SSO gagal: %v", err)
+		return c.Status(502).JSON(fiber.Map{"error": "Identity provider tidak bisa dihubungi"})
+	}
+
+	// Data flow disimpan di cookie bertanda tangan, jadi server tidak perlu menyimpan state
+	now := time.Now()
+	cookie, err := tokenService.Sign(jwt.MapClaims{
+		"tujuan":   tujuanOIDC,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      now.Add(lamaOIDC).Unix(),
+		"iat":      now.Unix(),
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     cookieOIDC,
+		Value:    cookie,
+		Path:     "/api/auth/oidc",
+		Expires:  now.Add(lamaOIDC),
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// OIDCCallback godoc
+// @Summary Callback login SSO
+// @Description Menyelesaikan login SSO: menukar authorization code, memverifikasi ID token, lalu menghubungkan akun berdasarkan email atau membuat akun baru. Role diambil dari grup provider sesuai OIDC_ROLE_MAPPING. Jika FRONTEND_URL diisi, browser diarahkan ke FRONTEND_URL/oidc/callback dengan token di fragment URL; jika tidak, response sama seperti /auth/login.
+// @Tags Authentication
+// @Produce json
+// @Param code query string true "Authorization code dari provider"
+// @Param state query string true "State dari /auth/oidc/login"
+// @Success 200 {object} map[string]interface{} "Login berhasil atau perlu kode 2FA"
+// @Success 302 "Redirect ke frontend"
+// @Failure 400 {object} map[string]interface{} "State tidak valid atau login dibatalkan"
+// @Failure 401 {object} map[string]interface{} "ID token tidak valid"
+// @Failure 403 {object} map[string]interface{} "Email belum diverifikasi provider, akun nonaktif, atau akun belum terdaftar"
+// @Failure 404 {object} map[string]interface{} "SSO tidak dikonfigurasi"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/oidc/callback [get]
+func OIDCCallback(c *fiber.Ctx) error {
+	if oidcProvider == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "SSO tidak dikonfigurasi"})
+	}
+
+	// Cookie state hanya berlaku untuk satu kali callback
+	cookie := c.Cookies(cookieOIDC)
+	c.Cookie(&fiber.Cookie{
+		Name:     cookieOIDC,
+		Path:     "/api/auth/oidc",
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	if e := c.Query("error"); e != "" {
+		pesan := e
+		if desc := c.Query("error_description"); desc != "" {
+			pesan += ": " + desc
+		}
+		return c.Status(400).JSON(fiber.Map{"error": "Login SSO dibatalkan (" + pesan + ")"})
+	}
+
+	flow, err := tokenService.Parse(cookie)
+	if err != nil || flow["tujuan"] != tujuanOIDC {
+		return c.Status(400).JSON(fiber.Map{"error": "Sesi login SSO tidak ditemukan atau kedaluwarsa, silakan ulangi login"})
+	}
+	state, _ := flow["state"].(string)
+	if state == "" || state != c.Query("state") {
+		return c.Status(400).JSON(fiber.Map{"error": "State login SSO tidak cocok"})
+	}
+	code := c.Query("code")
+	if code == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Authorization code tidak ada"})
+	}
+
+	verifier, _ := flow["verifier"].(string)
+	nonce, _ := flow["nonce"].(string)
+	ctx := context.Background()
+	claims, err := oidcProvider.TukarKode(ctx, code, verifier, nonce)
+	if err != nil {
+		log.Printf("Warning: callback SSO gagal: %v", err)
+		return c.Status(401).JSON(fiber.Map{"error": "Login SSO gagal diverifikasi"})
+	}
+	if claims.Email == "" {
+		return c.Status(403).JSON(fiber.Map{"error": "Identity provider tidak mengirim email"})
+	}
+	if !claims.EmailVerified {
+		return c.Status(403).JSON(fiber.Map{"error": "Email belum diverifikasi di identity provider"})
+	}
+
+	user, pesan, err := tautkanUserOIDC(c, claims)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if pesan != "" {
+		return c.Status(403).JSON(fiber.Map{"error": pesan})
+	}
+	if user.Nonaktif {
+		return c.Status(403).JSON(fiber.Map{"error": "Akun dinonaktifkan, hubungi admin"})
+	}
+
+	// 2FA aplikasi tetap berlaku untuk login SSO
+	if user.TOTPAktif {
+		challenge, err := buatTantangan2FA(user, models.MetodeSSO)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+		}
+		expiresIn := int64(config.Tantangan2FATTL().Seconds())
+		if frontend := config.FrontendURL(); frontend != "" {
+			return c.Redirect(frontend+"/oidc/callback#"+url.Values{
+				"challenge_token": {challenge},
+				"expires_in":      {strconv.FormatInt(expiresIn, 10)},
+			}.Encode(), fiber.StatusFound)
+		}
+		return c.JSON(fiber.Map{
+			"message": "Masukkan kode 2FA",
+			"data": fiber.Map{
+				"two_factor_required": true,
+				"challenge_token":     challenge,
+				"expires_in":          expiresIn,
+			},
+		})
+	}
+
+	response, err := buatSesi(context.Background(), c, user, models.MetodeSSO)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+	}
+
+	// Token dikirim lewat fragment agar tidak tercatat di log server frontend
+	if frontend := config.FrontendURL(); frontend != "" {
+		return c.Redirect(frontend+"/oidc/callback#"+url.Values{
+			"token":         {response.Token},
+			"refresh_token": {response.RefreshToken},
+			"expires_in":    {strconv.FormatInt(response.ExpiresIn, 10)},
+		}.Encode(), fiber.StatusFound)
+	}
+	return c.JSON(fiber.Map{
+		"message": "Login berhasil",
+		"data":    response,
+	})
+}
+
+// tautkanUserOIDC mencari akun dengan email dari provider dan menghubungkannya
+// ke subject provider, atau membuat akun baru jika OIDC_AUTO_PROVISION aktif.
+// Pesan tidak kosong berarti login ditolak.
+func tautkanUserOIDC(c *fiber.Ctx, claims *services.OIDCClaims) (*models.User, string, error) {
+	ctx := context.Background()
+	role := config.OIDCRoleDariGroup(claims.Groups)
+
+	user, err := userRepo.FindByEmail(ctx, claims.Email)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, "", err
+	}
+
+	if user == nil {
+		if !config.OIDCAutoProvision() {
+			return nil, "Akun belum terdaftar, hubungi admin", nil
+		}
+		return buatUserOIDC(c, claims, role)
+	}
+
+	// Data user dibaca ulang di dalam transaksi agar perubahan profil atau 2FA
+	// yang terjadi bersamaan tidak tertimpa
+	var pesan string
+	err = txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		sebelum, err := userRepo.FindByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if sebelum.OIDCSubject != "" && sebelum.OIDCSubject != claims.Subject {
+			pesan = "Email sudah terhubung ke akun SSO lain"
+			return nil
+		}
+
+		// Provider sudah memverifikasi email ini
+		terverifikasi := false
+		ubah := repository.UserUpdate{OIDCSubject: &claims.Subject, BelumVerifikasi: &terverifikasi}
+		if sebelum.NamaLengkap == "" {
+			ubah.NamaLengkap = &claims.Nama
+		}
+		// Role hanya diubah jika grup user ada di mapping; tanpa mapping role lokal dipertahankan
+		roleBerubah := role != "" && role != sebelum.Role
+		if roleBerubah {
+			if err := kunciRoleOIDC(ctx, role, "OIDC_ROLE_MAPPING"); err != nil {
+				return err
+			}
+			ubah.Role = &role
+		}
+		user, err = userRepo.Update(ctx, sebelum.ID, ubah)
+		if err != nil {
+			return err
+		}
+		if !roleBerubah {
+			return nil
+		}
+		return catatPerubahan(ctx, c, models.AuditRoleSSO, models.ResourceUser, user.ID, sebelum, user, map[string]interface{}{
+			"oidc_group": claims.Groups,
+		})
+	})
+	if err != nil || pesan != "" {
+		return nil, pesan, err
+	}
+	return user, "", nil
+}
+
+// buatUserOIDC membuat akun untuk user SSO baru. Akun tidak punya password;
+// user bisa membuatnya lewat lupa password jika perlu login tanpa SSO. User dan
+// audit disimpan dalam satu transaksi.
+func buatUserOIDC(c *fiber.Ctx, claims *services.OIDCClaims, role string) (*models.User, string, error) {
+	asal := "OIDC_ROLE_MAPPING"
+	if role == "" {
+		role = config.OIDCDefaultRole()
+		asal = "OIDC_DEFAULT_ROLE"
+	}
+
+	var user models.User
+	err := txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := kunciRoleOIDC(ctx, role, asal); err != nil {
+			return err
+		}
+		username, err := usernameOIDC(ctx, claims)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user = models.User{
+			ID:          primitive.NewObjectID(),
+			Username:    username,
+			Email:       claims.Email,
+			NamaLengkap: claims.Nama,
+			Role:        role,
+			OIDCSubject: claims.Subject,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := userRepo.Create(ctx, &user); err != nil {
+			return err
+		}
+
+		log := auditBaru(c, models.AuditBuatUserSSO)
+		log.Detail = map[string]interface{}{
+			"user_id":    user.ID,
+			"email":      user.Email,
+			"role":       role,
+			"oidc_group": claims.Groups,
+		}
+		return auditRepo.Create(ctx, &log)
+	})
+	if pesan := pesanAkunDipakai(err); pesan != "" {
+		return nil, pesan, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return &user, "", nil
+}
+
+// kunciRoleOIDC memastikan role dari konfigurasi SSO (asal) ada lalu menguncinya
+// seperti cekPemberianRole, agar role tidak terhapus saat diberikan ke user
+func kunciRoleOIDC(ctx context.Context, role, asal string) error {
+	if _, err := roleRepo.FindByNama(ctx, role); err == repository.ErrNotFound {
+		return fmt.Errorf("role %q dari %s tidak ada", role, asal)
+	} else if err != nil {
+		return err
+	}
+	return roleRepo.KunciRole(ctx, role)
+}
+
+// usernameOIDC memakai preferred_username atau bagian depan email, ditambah
+// angka jika sudah dipakai user lain
+func usernameOIDC(ctx context.Context, claims *services.OIDCClaims) (string, error) {
+	dasar := strings.TrimSpace(claims.Username)
+	if dasar == "" {
+		dasar, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if len(dasar) > 45 {
+		dasar = dasar[:45]
+	}
+	if len(dasar) < 2 {
+		dasar = "user"
+	}
+
+	username := dasar
+	for i := 2; ; i++ {
+		_, err := userRepo.FindByUsername(ctx, username)
+		if err == repository.ErrNotFound {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		username = dasar + strconv.Itoa(i)
+	}
+}
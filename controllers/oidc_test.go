@@ -0,0 +1,377 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const mockClientID = "inventory-test"
+
+// mockOIDC adalah identity provider tiruan yang melayani discovery, JWKS dan
+// token endpoint. ID token yang dikeluarkan berisi claims, ditambah nonce dari
+// request login terakhir kecuali claims sudah mengisi nonce sendiri.
+type mockOIDC struct {
+	server *httptest.Server
+	kunci  *rsa.PrivateKey
+
+	mu        sync.Mutex
+	claims    jwt.MapClaims
+	nonce     string
+	challenge string
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+	kunci, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockOIDC{kunci: kunci}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []services.JWK{{
+			Kty: "RSA",
+			Kid: "mock",
+			Alg: "RS256",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(kunci.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(kunci.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		// PKCE: code_verifier harus cocok dengan code_challenge dari login
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("client_id") != mockClientID || base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now()
+		claims := jwt.MapClaims{
+			"iss":   m.server.URL,
+			"aud":   mockClientID,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Minute).Unix(),
+			"nonce": m.nonce,
+		}
+		for k, v := range m.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "mock"
+		idToken, err := token.SignedString(kunci)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// setClaims mengatur claims ID token berikutnya
+func (m *mockOIDC) setClaims(claims jwt.MapClaims) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.claims = claims
+}
+
+// siapkanOIDC menyiapkan repository in-memory, token service, provider yang
+// mengarah ke mock dan app dengan route SSO
+func siapkanOIDC(t *testing.T) (*mockOIDC, *fiber.App) {
+	t.Helper()
+	mock := newMockOIDC(t)
+
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("JWT_SECRET", "rahasia-test-yang-cukup-panjang-32+")
+	t.Setenv("FRONTEND_URL", "")
+	t.Setenv("OIDC_ISSUER", mock.server.URL)
+	t.Setenv("OIDC_CLIENT_ID", mockClientID)
+	t.Setenv("OIDC_CLIENT_SECRET", "")
+	t.Setenv("OIDC_REDIRECT_URL", "http://localhost/api/auth/oidc/callback")
+	t.Setenv("OIDC_ROLE_MAPPING", "")
+	t.Setenv("OIDC_AUTO_PROVISION", "true")
+	t.Setenv("OIDC_ASSUME_EMAIL_VERIFIED", "")
+
+	SetRepositories(repository.NewMemoryRepositories())
+	if err := SeedRoles(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := services.NewTokenServiceFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTokenService(tokens)
+	provider, err := services.NewOIDCProviderFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetOIDCProvider(provider)
+	t.Cleanup(func() { SetOIDCProvider(nil) })
+
+	app := fiber.New()
+	app.Get("/api/auth/oidc/login", OIDCLogin)
+	app.Get("/api/auth/oidc/callback", OIDCCallback)
+	return mock, app
+}
+
+// mulaiLogin memanggil /auth/oidc/login dan mengembalikan cookie flow serta
+// state dari URL provider. Nonce dan code challenge dicatat di mock.
+func mulaiLogin(t *testing.T, mock *mockOIDC, app *fiber.App) (*http.Cookie, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/auth/oidc/login", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusFound {
+		t.Fatalf("login status = %d, ingin 302", resp.StatusCode)
+	}
+
+	lokasi, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(lokasi.String(), mock.server.URL+"/authorize") {
+		t.Fatalf("redirect ke %s, bukan ke provider", lokasi)
+	}
+	q := lokasi.Query()
+	mock.mu.Lock()
+	mock.nonce = q.Get("nonce")
+	mock.challenge = q.Get("code_challenge")
+	mock.mu.Unlock()
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == cookieOIDC {
+			return cookie, q.Get("state")
+		}
+	}
+	t.Fatal("cookie state tidak dikirim")
+	return nil, ""
+}
+
+// callback memanggil /auth/oidc/callback dengan cookie dan state
+func callback(t *testing.T, app *fiber.App, cookie *http.Cookie, state string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/auth/oidc/callback?"+url.Values{
+		"code":  {"kode-test"},
+		"state": {state},
+	}.Encode(), nil)
+	req.AddCookie(cookie)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body
+}
+
+func TestOIDCStateTidakCocok(t *testing.T) {
+	mock, app := siapkanOIDC(t)
+	mock.setClaims(jwt.MapClaims{"sub": "sub-1", "email": "budi@example.com", "email_verified": true})
+
+	cookie, _ := mulaiLogin(t, mock, app)
+	status, body := callback(t, app, cookie, "state-lain")
+	if status != 400 {
+		t.Fatalf("status = %d, ingin 400 (%v)", status, body)
+	}
+	if _, err := userRepo.FindByEmail(context.Background(), "budi@example.com"); err != repository.ErrNotFound {
+		t.Errorf("user tidak boleh dibuat saat state tidak cocok (err = %v)", err)
+	}
+}
+
+func TestOIDCNonceTidakCocok(t *testing.T) {
+	mock, app := siapkanOIDC(t)
+	mock.setClaims(jwt.MapClaims{"sub": "sub-1", "email": "budi@example.com", "email_verified": true, "nonce": "nonce-lain"})
+
+	cookie, state := mulaiLogin(t, mock, app)
+	status, body := callback(t, app, cookie, state)
+	if status != 401 {
+		t.Fatalf("status = %d, ingin 401 (%v)", status, body)
+	}
+	if _, err := userRepo.FindByEmail(context.Background(), "budi@example.com"); err != repository.ErrNotFound {
+		t.Errorf("user tidak boleh dibuat saat nonce tidak cocok (err = %v)", err)
+	}
+}
+
+func TestOIDCTautkanAkunYangAda(t *testing.T) {
+	mock, app := siapkanOIDC(t)
+	ctx := context.Background()
+
+	lama := models.User{
+		ID:              primitive.NewObjectID(),
+		Username:        "budi",
+		Email:           "budi@example.com",
+		Role:            models.RoleUser,
+		BelumVerifikasi: true,
+	}
+	if err := userRepo.Create(ctx, &lama); err != nil {
+		t.Fatal(err)
+	}
+	mock.setClaims(jwt.MapClaims{"sub": "sub-budi", "email": "budi@example.com", "email_verified": true, "name": "Budi Santoso"})
+
+	cookie, state := mulaiLogin(t, mock, app)
+	status, body := callback(t, app, cookie, state)
+	if status != 200 {
+		t.Fatalf("status = %d, ingin 200 (%v)", status, body)
+	}
+
+	user, err := userRepo.FindByID(ctx, lama.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.OIDCSubject != "sub-budi" {
+		t.Errorf("OIDCSubject = %q, ingin sub-budi", user.OIDCSubject)
+	}
+	if user.BelumVerifikasi {
+		t.Error("email seharusnya ditandai terverifikasi")
+	}
+	if user.NamaLengkap != "Budi Santoso" {
+		t.Errorf("NamaLengkap = %q, ingin Budi Santoso", user.NamaLengkap)
+	}
+	if _, total, _ := userRepo.FindAll(ctx, repository.UserFilter{}, repository.ListOptions{}); total != 1 {
+		t.Errorf("jumlah user = %d, ingin 1 (tidak boleh membuat akun baru)", total)
+	}
+}
+
+func TestOIDCBuatUserBaru(t *testing.T) {
+	mock, app := siapkanOIDC(t)
+	mock.setClaims(jwt.MapClaims{
+		"sub":                "sub-sari",
+		"email":              "sari@example.com",
+		"email_verified":     true,
+		"name":               "Sari",
+		"preferred_username": "sari",
+	})
+
+	cookie, state := mulaiLogin(t, mock, app)
+	status, body := callback(t, app, cookie, state)
+	if status != 200 {
+		t.Fatalf("status = %d, ingin 200 (%v)", status, body)
+	}
+
+	user, err := userRepo.FindByEmail(context.Background(), "sari@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.OIDCSubject != "sub-sari" || user.Username != "sari" || user.Role != models.RoleUser {
+		t.Errorf("user baru = {sub %q, username %q, role %q}, ingin {sub-sari, sari, user}", user.OIDCSubject, user.Username, user.Role)
+	}
+	if user.Password != "" {
+		t.Error("user SSO baru tidak boleh punya password")
+	}
+}
+
+func TestOIDCTanpaEmailVerifiedDitolak(t *testing.T) {
+	mock, app := siapkanOIDC(t)
+	ctx := context.Background()
+
+	admin := models.User{ID: primitive.NewObjectID(), Username: "admin", Email: "admin@example.com", Role: models.RoleAdmin}
+	if err := userRepo.Create(ctx, &admin); err != nil {
+		t.Fatal(err)
+	}
+	mock.setClaims(jwt.MapClaims{"sub": "sub-penyusup", "email": "admin@example.com"})
+
+	cookie, state := mulaiLogin(t, mock, app)
+	status, body := callback(t, app, cookie, state)
+	if status != 403 {
+		t.Fatalf("status = %d, ingin 403 (%v)", status, body)
+	}
+	user, err := userRepo.FindByID(ctx, admin.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.OIDCSubject != "" {
+		t.Error("akun admin tidak boleh ditautkan tanpa email_verified")
+	}
+}
+
+// auditGagal menggagalkan penulisan audit log untuk menguji rollback transaksi
+type auditGagal struct {
+	repository.AuditLogRepository
+}
+
+func (auditGagal) Create(context.Context, *models.AuditLog) error {
+	return errors.New("gagal menulis audit")
+}
+
+func TestOIDCRoleDariGroupAtomik(t *testing.T) {
+	mock, app := siapkanOIDC(t)
+	t.Setenv("OIDC_ROLE_MAPPING", "it-admin:admin")
+	repos := repository.NewMemoryRepositories()
+	SetRepositories(repos)
+	ctx := context.Background()
+	if err := SeedRoles(ctx); err != nil {
+		t.Fatal(err)
+	}
+	lama := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &lama); err != nil {
+		t.Fatal(err)
+	}
+	mock.setClaims(jwt.MapClaims{"sub": "sub-budi", "email": "budi@example.com", "email_verified": true, "groups": []string{"it-admin"}})
+
+	// Audit gagal ditulis: role tidak boleh ikut berubah
+	gagal := *repos
+	gagal.AuditLog = auditGagal{repos.AuditLog}
+	SetRepositories(&gagal)
+	cookie, state := mulaiLogin(t, mock, app)
+	if status, body := callback(t, app, cookie, state); status != 500 {
+		t.Fatalf("status = %d, ingin 500 (%v)", status, body)
+	}
+	if user, _ := userRepo.FindByID(ctx, lama.ID); user.Role != models.RoleUser || user.OIDCSubject != "" {
+		t.Errorf("user berubah walau audit gagal: role %q, sub %q", user.Role, user.OIDCSubject)
+	}
+
+	SetRepositories(repos)
+	cookie, state = mulaiLogin(t, mock, app)
+	if status, body := callback(t, app, cookie, state); status != 200 {
+		t.Fatalf("status = %d, ingin 200 (%v)", status, body)
+	}
+	if user, _ := userRepo.FindByID(ctx, lama.ID); user.Role != models.RoleAdmin {
+		t.Errorf("role = %q, ingin admin", user.Role)
+	}
+	logs, total, err := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: models.AuditRoleSSO}, repository.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Fatalf("audit log = %d, ingin 1", total)
+	}
+	if ubah, ok := logs[0].Perubahan["role"]; !ok || ubah.Dari != models.RoleUser || ubah.Ke != models.RoleAdmin {
+		t.Errorf("perubahan role = %+v, ingin user -> admin", logs[0].Perubahan)
+	}
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"errors"
+	"inventory-backend/repository"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// parseListOptions membaca query page, limit, sort dan after dengan nilai
+// default yang aman. sortable memetakan nama field di query sort ke field bson
+// yang ber-index, sehingga hanya field itu yang bisa dipakai untuk mengurutkan.
+// sort diawali "-" untuk urutan menurun, mis. sort=-stok.
+func parseListOptions(c *fiber.Ctx, sortable map[string]string) (repository.ListOptions, error) {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", defaultLimit)
+	if limit < 1 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	opts := repository.ListOptions{Page: page, Limit: limit}
+
+	if sort := c.Query("sort"); sort != "" {
+		nama, desc := strings.CutPrefix(sort, "-")
+		field, ok := sortable[nama]
+		if !ok {
+			return opts, errors.New("sort hanya bisa memakai field: " + strings.Join(daftarSortable(sortable), ", "))
+		}
+		opts.Sort = field
+		opts.Desc = desc
+	}
+
+	if after := c.Query("after"); after != "" {
+		id, err := primitive.ObjectIDFromHex(after)
+		if err != nil {
+			return opts, errors.New("after harus berisi ID data terakhir halaman sebelumnya")
+		}
+		opts.After = &id
+	}
+	return opts, nil
+}
+
+// parsePencarian membaca teks pencarian dari query param dan mode pencocokan
+// dari query match: contains (bawaan, cocok di bagian mana pun) atau prefix
+// (cocok di awal nilai)
+func parsePencarian(c *fiber.Ctx, param string) (repository.Pencarian, error) {
+	cari := repository.Pencarian{Teks: strings.TrimSpace(c.Query(param))}
+	switch c.Query("match", "contains") {
+	case "contains":
+	case "prefix":
+		cari.Awalan = true
+	default:
+		return cari, errors.New("match harus contains atau prefix")
+	}
+	return cari, nil
+}
+
+// daftarSortable mengembalikan nama field sort yang diterima, terurut
+func daftarSortable(sortable map[string]string) []string {
+	nama := make([]string, 0, len(sortable))
+	for n := range sortable {
+		nama = append(nama, n)
+	}
+	slices.Sort(nama)
+	return nama
+}
+
+// paginated membungkus data list beserta informasi halaman dan link ke halaman
+// berikutnya. Jika request memakai after, link berikutnya memakai ID data
+// terakhir (id) sebagai after; jika tidak, memakai nomor halaman.
+func paginated[T any](c *fiber.Ctx, items []T, opts repository.ListOptions, total int64, id func(T) primitive.ObjectID) fiber.Map {
+	meta := fiber.Map{
+		"limit": opts.Limit,
+		"total": total,
+	}
+	links := fiber.Map{
+		"self": linkHalaman(c, nil),
+	}
+
+	if opts.After != nil {
+		if len(items) > 0 && len(items) == opts.Limit {
+			after := id(items[len(items)-1]).Hex()
+			meta["next_after"] = after
+			links["next"] = linkHalaman(c, func(q url.Values) {
+				q.Set("after", after)
+				q.Del("page")
+			})
+		}
+	} else {
+		meta["page"] = opts.Page
+		if int64(opts.Page*opts.Limit) < total {
+			links["next"] = linkHalaman(c, func(q url.Values) {
+				q.Set("page", strconv.Itoa(opts.Page+1))
+			})
+		}
+		if opts.Page > 1 {
+			links["prev"] = linkHalaman(c, func(q url.Values) {
+				q.Set("page", strconv.Itoa(opts.Page-1))
+			})
+		}
+	}
+
+	return fiber.Map{
+		"data":  items,
+		"meta":  meta,
+		"links": links,
+	}
+}
+
+// linkHalaman menyusun path request saat ini dengan query yang diubah oleh ubah
+func linkHalaman(c *fiber.Ctx, ubah func(url.Values)) string {
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if ubah != nil {
+		ubah(query)
+	}
+	if len(query) == 0 {
+		return c.Path()
+	}
+	return c.Path() + "?" + query.Encode()
+}
+
+// errorList membalas error dari FindAll: 400 jika after tidak ada di hasil
+// query, selain itu 500 dengan pesan (atau pesan error jika pesan kosong)
+func errorList(c *fiber.Ctx, err error, pesan string) error {
+	if errors.Is(err, repository.ErrCursorTidakValid) {
+		return c.Status(400).JSON(fiber.Map{"error": "after tidak ditemukan di hasil query ini"})
+	}
+	if pesan == "" {
+		pesan = err.Error()
+	}
+	return c.Status(500).JSON(fiber.Map{"error": pesan})
+}
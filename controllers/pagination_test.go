@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// namaData mengambil field nama dari setiap baris data response list
+func namaData(body map[string]interface{}, field string) []string {
+	nama := []string{}
+	data, _ := body["data"].([]interface{})
+	for _, d := range data {
+		nama = append(nama, d.(map[string]interface{})[field].(string))
+	}
+	return nama
+}
+
+func TestDaftarBarangPagination(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	elektronik := primitive.NewObjectID()
+	for _, b := range []struct {
+		nama     string
+		stok     int
+		kategori primitive.ObjectID
+	}{
+		{"Proyektor", 3, elektronik},
+		{"Kabel", 10, primitive.NewObjectID()},
+		{"Laptop", 1, elektronik},
+		{"Speaker", 5, elektronik},
+		{"Meja", 7, primitive.NewObjectID()},
+	} {
+		barang := models.Barang{ID: primitive.NewObjectID(), Nama: b.nama, KategoriID: b.kategori, Stok: b.stok}
+		if err := barangRepo.Create(context.Background(), &barang); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := appPengguna(primitive.NewObjectID())
+	app.Get("/barang", GetAllBarang)
+
+	status, body := kirimJSON(t, app, "GET", "/barang?limit=2&page=2", "")
+	if status != 200 {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	meta := body["meta"].(map[string]interface{})
+	links := body["links"].(map[string]interface{})
+	if got := strings.Join(namaData(body, "nama"), ","); got != "Laptop,Speaker" {
+		t.Errorf("halaman 2 = %s, ingin Laptop,Speaker", got)
+	}
+	if meta["total"] != float64(5) || meta["page"] != float64(2) || meta["limit"] != float64(2) {
+		t.Errorf("meta = %v, ingin total 5 page 2 limit 2", meta)
+	}
+	if links["next"] != "/barang?limit=2&page=3" || links["prev"] != "/barang?limit=2&page=1" {
+		t.Errorf("links = %v", links)
+	}
+
+	status, body = kirimJSON(t, app, "GET", "/barang?limit=2&page=3", "")
+	if links := body["links"].(map[string]interface{}); status != 200 || links["next"] != nil {
+		t.Errorf("halaman terakhir: status = %d, links = %v, ingin tanpa next", status, links)
+	}
+
+	status, body = kirimJSON(t, app, "GET", "/barang?sort=-stok&stok_min=3&stok_max=7&kategori_id="+elektronik.Hex(), "")
+	if got := strings.Join(namaData(body, "nama"), ","); status != 200 || got != "Speaker,Proyektor" {
+		t.Errorf("filter dan sort: status = %d, hasil = %s, ingin Speaker,Proyektor", status, got)
+	}
+
+	status, body = kirimJSON(t, app, "GET", "/barang?limit=1000", "")
+	if meta := body["meta"].(map[string]interface{}); status != 200 || meta["limit"] != float64(maxLimit) {
+		t.Errorf("limit dibatasi: status = %d, meta = %v, ingin limit %d", status, meta, maxLimit)
+	}
+
+	for _, q := range []string{"sort=harga", "after=bukan-id", "after=" + primitive.NewObjectID().Hex(), "stok_min=banyak", "kategori_id=x"} {
+		if status, body := kirimJSON(t, app, "GET", "/barang?"+q, ""); status != 400 {
+			t.Errorf("%s: status = %d, body = %v, ingin 400", q, status, body)
+		}
+	}
+}
+
+// TestDaftarBarangAfter membaca semua barang dengan keyset pagination dengan
+// mengikuti next_after sampai habis
+func TestDaftarBarangAfter(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ingin := []string{}
+	for _, nama := range []string{"A", "B", "C", "D", "E"} {
+		siapkanBarang(t, nama, 1)
+		ingin = append(ingin, nama)
+	}
+
+	app := appPengguna(primitive.NewObjectID())
+	app.Get("/barang", GetAllBarang)
+
+	status, body := kirimJSON(t, app, "GET", "/barang?limit=2&sort=nama", "")
+	if status != 200 {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	semua := namaData(body, "nama")
+	after := body["data"].([]interface{})[1].(map[string]interface{})["id"].(string)
+	for range 5 {
+		status, body = kirimJSON(t, app, "GET", "/barang?limit=2&sort=nama&after="+after, "")
+		if status != 200 {
+			t.Fatalf("status = %d, body = %v", status, body)
+		}
+		semua = append(semua, namaData(body, "nama")...)
+		meta := body["meta"].(map[string]interface{})
+		if meta["page"] != nil {
+			t.Errorf("meta keyset memuat page: %v", meta)
+		}
+		next, ok := meta["next_after"].(string)
+		if !ok {
+			break
+		}
+		if links := body["links"].(map[string]interface{}); links["next"] != "/barang?after="+next+"&limit=2&sort=nama" {
+			t.Errorf("links.next = %v", links["next"])
+		}
+		after = next
+	}
+	if got := strings.Join(semua, ","); got != strings.Join(ingin, ",") {
+		t.Errorf("semua barang = %s, ingin %s", got, strings.Join(ingin, ","))
+	}
+}
+
+func TestDaftarPeminjamanFilter(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	proyektor := siapkanBarang(t, "Proyektor", 5)
+	kabel := siapkanBarang(t, "Kabel", 5)
+	for _, p := range []models.Peminjaman{
+		{NamaPeminjam: "Ani", EmailPeminjam: "ani@example.com", Status: models.StatusDipinjam, TanggalPinjam: "2026-10-01 09:00:00", Items: []models.ItemPeminjaman{{BarangID: proyektor.ID, Jumlah: 1}}},
+		{NamaPeminjam: "Budi", EmailPeminjam: "budi@example.com", Status: models.StatusDiajukan, TanggalPinjam: "2026-10-05 09:00:00", Items: []models.ItemPeminjaman{{BarangID: kabel.ID, Jumlah: 1}}},
+		{NamaPeminjam: "Citra", EmailPeminjam: "citra@example.com", Status: models.StatusDisetujui, TanggalPinjam: "2026-10-10 09:00:00", Items: []models.ItemPeminjaman{{BarangID: proyektor.ID, Jumlah: 1}, {BarangID: kabel.ID, Jumlah: 1}}},
+	} {
+		p.ID = primitive.NewObjectID()
+		if err := peminjamanRepo.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanReadAll)
+	app.Get("/peminjaman", GetAllPeminjaman)
+
+	tests := []struct {
+		query string
+		ingin string
+	}{
+		{"status=dipinjam,disetujui", "Ani,Citra"},
+		{"barang_id=" + kabel.ID.Hex(), "Budi,Citra"},
+		{"email=BUDI@example.com", "Budi"},
+		{"dari=2026-10-05&sampai=2026-10-10", "Budi,Citra"},
+		{"sampai=2026-10-05", "Ani,Budi"},
+		{"sort=-nama_peminjam", "Citra,Budi,Ani"},
+	}
+	for _, tt := range tests {
+		status, body := kirimJSON(t, app, "GET", "/peminjaman?"+tt.query, "")
+		if got := strings.Join(namaData(body, "nama_peminjam"), ","); status != 200 || got != tt.ingin {
+			t.Errorf("%s: status = %d, hasil = %s, ingin %s", tt.query, status, got, tt.ingin)
+		}
+	}
+
+	for _, q := range []string{"status=hilang", "dari=01-10-2026", "barang_id=x"} {
+		if status, body := kirimJSON(t, app, "GET", "/peminjaman?"+q, ""); status != 400 {
+			t.Errorf("%s: status = %d, body = %v, ingin 400", q, status, body)
+		}
+	}
+}
+
+func TestCariBarang(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	for _, nama := range []string{"Proyektor Epson", "LCD Proyektor", "Kabel (HDMI)", "Kabel HDMI panjang", "Laptop"} {
+		siapkanBarang(t, nama, 1)
+	}
+
+	app := appPengguna(primitive.NewObjectID())
+	app.Get("/barang", GetAllBarang)
+
+	tests := []struct {
+		query string
+		ingin string
+	}{
+		{"search=proyektor", "Proyektor Epson,LCD Proyektor"},
+		{"search=proy&match=prefix", "Proyektor Epson"},
+		{"search=" + url.QueryEscape("(HDMI"), "Kabel (HDMI)"},
+		{"search=" + url.QueryEscape(".*"), ""},
+		// Dengan q hasil diurutkan dari kata kunci yang paling banyak cocok
+		{"q=" + url.QueryEscape("kabel hdmi panjang"), "Kabel HDMI panjang,Kabel (HDMI)"},
+	}
+	for _, tt := range tests {
+		status, body := kirimJSON(t, app, "GET", "/barang?"+tt.query, "")
+		if got := strings.Join(namaData(body, "nama"), ","); status != 200 || got != tt.ingin {
+			t.Errorf("%s: status = %d, hasil = %q, ingin %q", tt.query, status, got, tt.ingin)
+		}
+	}
+
+	for _, q := range []string{"search=x&match=regex", "q=kabel&after=" + primitive.NewObjectID().Hex()} {
+		if status, body := kirimJSON(t, app, "GET", "/barang?"+q, ""); status != 400 {
+			t.Errorf("%s: status = %d, body = %v, ingin 400", q, status, body)
+		}
+	}
+}
+
+func TestCariPeminjaman(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	proyektor := siapkanBarang(t, "Proyektor Epson", 5)
+	kabel := siapkanBarang(t, "Kabel", 5)
+	for _, p := range []models.Peminjaman{
+		{NamaPeminjam: "Ani Wijaya", EmailPeminjam: "ani@example.com", TeleponPeminjam: "081111", Items: []models.ItemPeminjaman{{BarangID: proyektor.ID, Jumlah: 1}}},
+		{NamaPeminjam: "Budi", EmailPeminjam: "budi+lab@example.com", TeleponPeminjam: "082222", Items: []models.ItemPeminjaman{{BarangID: kabel.ID, Jumlah: 1}}},
+	} {
+		p.ID = primitive.NewObjectID()
+		p.Status = models.StatusDiajukan
+		if err := peminjamanRepo.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanReadAll)
+	app.Get("/peminjaman", GetAllPeminjaman)
+
+	tests := []struct {
+		query string
+		ingin string
+	}{
+		{"search=wijaya", "Ani Wijaya"},
+		{"search=" + url.QueryEscape("budi+lab"), "Budi"},
+		{"search=0822", "Budi"},
+		{"search=proyektor", "Ani Wijaya"},
+		{"search=example&match=prefix", ""},
+		{"search=" + url.QueryEscape("^a"), ""},
+	}
+	for _, tt := range tests {
+		status, body := kirimJSON(t, app, "GET", "/peminjaman?"+tt.query, "")
+		if got := strings.Join(namaData(body, "nama_peminjam"), ","); status != 200 || got != tt.ingin {
+			t.Errorf("%s: status = %d, hasil = %q, ingin %q", tt.query, status, got, tt.ingin)
+		}
+	}
+}
@@ -2,27 +2,22 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"inventory-backend/middlewares"
 	"inventory-backend/models"
+	"inventory-backend/repository"
 	"inventory-backend/validators"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var peminjamanCollection *mongo.Collection
-var barangCollectionPeminjaman *mongo.Collection
-
-func SetPeminjamanCollection(db *mongo.Database) {
-	peminjamanCollection = db.Collection("peminjaman")
-	barangCollectionPeminjaman = db.Collection("barang")
-}
-
 // GetPeminjamanByID godoc
 // @Summary Get peminjaman by ID
-// @Description Mengambil data peminjaman berdasarkan ID
+// @Description Mengambil data peminjaman berdasarkan ID. Tanpa permission peminjaman:read_all user hanya bisa melihat peminjaman miliknya sendiri.
 // @Tags Peminjaman
 // @Accept json
 // @Produce json
@@ -39,66 +34,167 @@ func GetPeminjamanByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	var peminjaman models.Peminjaman
-	err = peminjamanCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&peminjaman)
+	peminjaman, err := peminjamanRepo.FindByID(context.Background(), id)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
+		if err == repository.ErrNotFound {
 			return c.Status(404).JSON(fiber.Map{"error": "Data peminjaman tidak ditemukan"})
 		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// Peminjaman milik user lain diperlakukan seperti tidak ada
+	if !middlewares.HasPermission(c, models.PermPeminjamanReadAll) && !milikUser(peminjaman, currentUserID(c)) {
+		return c.Status(404).JSON(fiber.Map{"error": "Data peminjaman tidak ditemukan"})
+	}
+
+	peminjaman.HitungKeterlambatan(time.Now())
 	return c.JSON(peminjaman)
 }
 
 // GetAllPeminjaman godoc
 // @Summary Get all peminjaman
-// @Description Mengambil semua data peminjaman dengan opsi pencarian exact match nama peminjam
+// @Description Mengambil data peminjaman dengan pencarian parsial atas nama, email dan telepon peminjam serta nama barang. User dengan permission peminjaman:read_all melihat semua peminjaman, user lain hanya peminjaman miliknya sendiri.
 // @Tags Peminjaman
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param search query string false "Pencarian exact match berdasarkan nama peminjam (case-insensitive)"
-// @Success 200 {array} models.Peminjaman "Daftar peminjaman"
+// @Param search query string false "Cari di nama, email, telepon peminjam dan nama barang (case-insensitive)"
+// @Param match query string false "Cara mencocokkan search: contains (bawaan) atau prefix"
+// @Param status query string false "Filter status, boleh beberapa dipisah koma (mis. dipinjam,disetujui)"
+// @Param barang_id query string false "Filter peminjaman yang memuat barang ini"
+// @Param email query string false "Filter email peminjam (exact match, case-insensitive)"
+// @Param dari query string false "Tanggal pinjam mulai (YYYY-MM-DD, inklusif)"
+// @Param sampai query string false "Tanggal pinjam sampai (YYYY-MM-DD, inklusif)"
+// @Param sort query string false "Urutan: id, tanggal_pinjam, tanggal_jatuh_tempo, status atau nama_peminjam; awali dengan - untuk menurun"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID peminjaman terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Daftar peminjaman"
+// @Failure 400 {object} map[string]interface{} "Parameter tidak valid"
 // @Failure 500 {object} map[string]interface{} "Terjadi kesalahan server"
 // @Router /peminjaman [get]
 func GetAllPeminjaman(c *fiber.Ctx) error {
-	search := c.Query("search")
-	filter := bson.M{}
-
-	// Jika parameter search ada, tambahkan filter nama_peminjam untuk exact match
-	if search != "" {
-		filter = bson.M{
-			"nama_peminjam": bson.M{
-				"$regex": primitive.Regex{
-					Pattern: "^" + search + "$", // ^ untuk awal string, $ untuk akhir string (exact match)
-					Options: "i",                // case-insensitive
-				},
-			},
-		}
+	filter := repository.PeminjamanFilter{}
+	if !middlewares.HasPermission(c, models.PermPeminjamanReadAll) {
+		userID := currentUserID(c)
+		filter.UserID = &userID
 	}
 
-	cursor, err := peminjamanCollection.Find(context.Background(), filter)
+	return daftarPeminjaman(c, filter)
+}
+
+// GetPeminjamanSaya godoc
+// @Summary Get peminjaman milik sendiri
+// @Description Mengambil peminjaman yang terikat ke akun user yang sedang login. Filter, pengurutan dan pagination sama seperti GET /peminjaman.
+// @Tags Peminjaman
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Cari di nama, email, telepon peminjam dan nama barang"
+// @Param match query string false "Cara mencocokkan search: contains (bawaan) atau prefix"
+// @Param status query string false "Filter status, boleh beberapa dipisah koma"
+// @Param sort query string false "Urutan, lihat GET /peminjaman"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param after query string false "ID peminjaman terakhir halaman sebelumnya"
+// @Success 200 {object} map[string]interface{} "Daftar peminjaman milik user"
+// @Failure 400 {object} map[string]interface{} "Parameter tidak valid"
+// @Failure 500 {object} map[string]interface{} "Terjadi kesalahan server"
+// @Router /peminjaman/saya [get]
+func GetPeminjamanSaya(c *fiber.Ctx) error {
+	userID := currentUserID(c)
+	return daftarPeminjaman(c, repository.PeminjamanFilter{UserID: &userID})
+}
+
+// daftarPeminjaman menambahkan filter dari query ke filter lalu mengirim hasil
+// FindAll lengkap dengan status keterlambatan
+func daftarPeminjaman(c *fiber.Ctx, filter repository.PeminjamanFilter) error {
+	if err := isiFilterPeminjaman(c, &filter); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	opts, err := parseListOptions(c, map[string]string{
+		"id":                  "_id",
+		"tanggal_pinjam":      "tanggal_pinjam",
+		"tanggal_jatuh_tempo": "tanggal_jatuh_tempo",
+		"status":              "status",
+		"nama_peminjam":       "nama_peminjam",
+	})
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data peminjaman"})
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	var peminjaman []models.Peminjaman
-	if err := cursor.All(context.Background(), &peminjaman); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal membaca data peminjaman"})
+	peminjaman, total, err := peminjamanRepo.FindAll(context.Background(), filter, opts)
+	if err != nil {
+		return errorList(c, err, "Gagal mengambil data peminjaman")
 	}
-	return c.JSON(peminjaman)
+
+	now := time.Now()
+	for i := range peminjaman {
+		peminjaman[i].HitungKeterlambatan(now)
+	}
+	return c.JSON(paginated(c, peminjaman, opts, total, func(p models.Peminjaman) primitive.ObjectID { return p.ID }))
+}
+
+// isiFilterPeminjaman membaca query search, status, barang_id, email, dari dan sampai
+func isiFilterPeminjaman(c *fiber.Ctx, filter *repository.PeminjamanFilter) error {
+	cari, err := parsePencarian(c, "search")
+	if err != nil {
+		return err
+	}
+	filter.Cari = cari
+
+	if v := c.Query("status"); v != "" {
+		for _, status := range strings.Split(v, ",") {
+			status = strings.TrimSpace(status)
+			if !slices.Contains(models.SemuaStatus, status) {
+				return errors.New("status tidak dikenal: " + status)
+			}
+			filter.Status = append(filter.Status, status)
+		}
+	}
+	if v := c.Query("barang_id"); v != "" {
+		id, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return errors.New("barang_id tidak valid")
+		}
+		filter.BarangID = []primitive.ObjectID{id}
+	}
+	filter.EmailPeminjam = strings.TrimSpace(c.Query("email"))
+
+	for _, q := range []struct {
+		nama  string
+		nilai *string
+	}{
+		{"dari", &filter.TanggalDari},
+		{"sampai", &filter.TanggalSampai},
+	} {
+		v := c.Query(q.nama)
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(models.FormatTanggal, v); err != nil {
+			return errors.New(q.nama + " harus berformat YYYY-MM-DD")
+		}
+		*q.nilai = v
+	}
+	return nil
+}
+
+// milikUser bernilai true jika peminjaman terikat ke akun userID
+func milikUser(p *models.Peminjaman, userID primitive.ObjectID) bool {
+	return p.UserID != nil && *p.UserID == userID
 }
 
 // CreatePeminjaman godoc
 // @Summary Create new peminjaman
-// @Description Membuat data peminjaman baru
+// @Description Mengajukan peminjaman baru dengan status 'diajukan' untuk satu atau beberapa barang (items). Semua barang harus tersedia; stok belum dikurangi sampai peminjaman disetujui admin. Format lama dengan barang_id dan jumlah tetap diterima.
+// @Description Peminjaman otomatis terikat ke akun pengaju dan nama, email serta telepon peminjam diambil dari akun; telepon boleh kosong jika akun belum mengisinya. User dengan permission peminjaman:create_for_others boleh mengisi user_id untuk meminjam atas nama user lain, atau mengosongkannya dan mengisi nama_peminjam, email_peminjam dan telepon_peminjam untuk peminjam tamu.
 // @Tags Peminjaman
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param peminjaman body models.Peminjaman true "Data peminjaman baru"
-// @Success 201 {object} models.Peminjaman "Peminjaman berhasil dibuat"
+// @Success 201 {object} models.Peminjaman "Peminjaman berhasil diajukan"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 404 {object} map[string]interface{} "Barang tidak ditemukan"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -109,34 +205,71 @@ func CreatePeminjaman(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	if err := validators.ValidatePeminjaman(data.NamaPeminjam, data.EmailPeminjam, data.TeleponPeminjam, data.Jumlah, data.Status); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	// Request format lama (barang_id dan jumlah) diubah menjadi satu item
+	data.Normalisasi()
+
+	// Tanpa permission create_for_others, user selalu meminjam atas nama sendiri;
+	// dengan permission itu user_id boleh dipilih atau dikosongkan untuk peminjam tamu
+	if !middlewares.HasPermission(c, models.PermPeminjamanCreateForOthers) {
+		userID := currentUserID(c)
+		data.UserID = &userID
+	} else if data.UserID != nil && data.UserID.IsZero() {
+		data.UserID = nil
+	}
+	if data.UserID != nil {
+		user, err := userRepo.FindByID(context.Background(), *data.UserID)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "User peminjam tidak ditemukan"})
+		}
+		data.NamaPeminjam = user.Username
+		data.EmailPeminjam = user.Email
+		// Telepon diambil dari profil akun jika ada; jika tidak, telepon boleh kosong
+		if user.Telepon != "" {
+			data.TeleponPeminjam = user.Telepon
+		}
+	} else if strings.TrimSpace(data.TeleponPeminjam) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "telepon peminjam wajib diisi untuk peminjam tamu"})
 	}
 
-	// Cek barang
-	var barang models.Barang
-	err := barangCollectionPeminjaman.FindOne(context.Background(), bson.M{"_id": data.BarangID}).Decode(&barang)
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan"})
+	if err := validators.ValidatePeminjaman(data.NamaPeminjam, data.EmailPeminjam, data.TeleponPeminjam, data.Items); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := validators.ValidateJatuhTempo(data.TanggalJatuhTempo); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// Hanya proses jika status dipinjam
-	if data.Status == "dipinjam" {
-		if data.Jumlah > barang.Stok {
-			return c.Status(400).JSON(fiber.Map{"error": "Stok barang tidak mencukupi"})
-		}
-		_, err = barangCollectionPeminjaman.UpdateOne(context.Background(),
-			bson.M{"_id": barang.ID},
-			bson.M{"$inc": bson.M{"stok": -data.Jumlah}})
+	data.Items = gabungItems(data.Items)
+
+	// Semua barang harus ada dan stoknya cukup, jika tidak seluruh peminjaman ditolak.
+	// Ini pengecekan awal saja; stok baru dipesan secara atomik saat disetujui.
+	for _, item := range data.Items {
+		barang, err := barangRepo.FindByID(context.Background(), item.BarangID)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan: " + item.BarangID.Hex()})
+		}
+		if item.Jumlah > barang.Stok {
+			return c.Status(400).JSON(fiber.Map{"error": "Stok barang " + barang.Nama + " tidak mencukupi"})
 		}
 	}
 
+	now := time.Now().Format(models.FormatTanggalWaktu)
 	data.ID = primitive.NewObjectID()
-	data.TanggalPinjam = time.Now().Format("2006-01-02 15:04:05")
+	data.Terhapus = models.Terhapus{}
+	data.TanggalPinjam = now
+	data.TanggalKembali = ""
+	data.Status = models.StatusDiajukan
+	data.RiwayatStatus = []models.RiwayatStatus{{
+		Ke:      models.StatusDiajukan,
+		Oleh:    currentUserID(c),
+		Tanggal: now,
+	}}
 
-	_, err = peminjamanCollection.InsertOne(context.Background(), data)
+	err := txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := peminjamanRepo.Create(ctx, &data); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditBuatPeminjaman, models.ResourcePeminjaman, data.ID, nil, data, nil)
+	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -146,18 +279,21 @@ func CreatePeminjaman(c *fiber.Ctx) error {
 
 // UpdateStatusPeminjaman godoc
 // @Summary Update status peminjaman
-// @Description Mengupdate status peminjaman (dipinjam/dikembalikan)
+// @Description Memindahkan status peminjaman sesuai alur: diajukan -> disetujui/ditolak/dibatalkan, disetujui -> dipinjam/dibatalkan, dipinjam -> dikembalikan. Stok dipesan saat disetujui dan dikembalikan saat dibatalkan atau dikembalikan. Alasan wajib diisi saat menolak.
+// @Description Menandai dikembalikan butuh permission peminjaman:return, perpindahan lain butuh peminjaman:approve. Pemilik peminjaman boleh membatalkan pengajuannya sendiri selama masih berstatus diajukan.
 // @Tags Peminjaman
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Peminjaman ID"
-// @Param status body object{status=string} true "Status baru"
+// @Param status body object{status=string,alasan=string} true "Status baru dan alasan"
 // @Success 200 {object} map[string]interface{} "Status berhasil diperbarui"
 // @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Tidak punya permission untuk perpindahan status ini"
 // @Failure 404 {object} map[string]interface{} "Data tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Perpindahan status tidak diizinkan"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Router /peminjaman/{id}/status [put]
+// @Router /peminjaman/{id} [put]
 func UpdateStatusPeminjaman(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := primitive.ObjectIDFromHex(idParam)
@@ -167,57 +303,107 @@ func UpdateStatusPeminjaman(c *fiber.Ctx) error {
 
 	var updateData struct {
 		Status string `json:"status"`
+		Alasan string `json:"alasan"`
 	}
 	if err := c.BodyParser(&updateData); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// Ambil data peminjaman dulu
-	var pinjam models.Peminjaman
-	err = peminjamanCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&pinjam)
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
+	if updateData.Status == models.StatusDitolak && strings.TrimSpace(updateData.Alasan) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Alasan penolakan wajib diisi"})
 	}
 
-	// Otomatisasi stok:
-	if pinjam.Status != updateData.Status {
-		if updateData.Status == "dipinjam" {
-			// Validasi stok sebelum update
-			var barang models.Barang
-			err := barangCollectionPeminjaman.FindOne(context.Background(), bson.M{"_id": pinjam.BarangID}).Decode(&barang)
-			if err != nil {
-				return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan"})
-			}
-			if pinjam.Jumlah > barang.Stok {
-				return c.Status(400).JSON(fiber.Map{"error": "Stok barang tidak mencukupi"})
+	userID := currentUserID(c)
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		// Ambil data peminjaman di dalam transaksi agar status terbaru yang dipakai
+		pinjam, err := peminjamanRepo.FindByID(ctx, id)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(404, "Data tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := cekIzinTransisi(c, pinjam, updateData.Status); err != nil {
+			return err
+		}
+		if err := validators.ValidateTransisiStatus(pinjam.Status, updateData.Status); err != nil {
+			return fiber.NewError(409, err.Error())
+		}
+
+		// Snapshot diambil sebelum pinjam diubah di tempat
+		sebelum, err := snapshot(pinjam)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().Format(models.FormatTanggalWaktu)
+
+		// Otomatisasi stok: semua item dipesan saat mulai menahan stok, dikembalikan saat berhenti
+		menahanSebelum := models.MenahanStok(pinjam.Status)
+		menahanSesudah := models.MenahanStok(updateData.Status)
+		if !menahanSebelum && menahanSesudah {
+			// Stok semua item dicek dan dikurangi secara atomik
+			if err := pesanStokPeminjaman(ctx, pinjam, models.MutasiPinjam, userID); err != nil {
+				return err
 			}
-			_, err = barangCollectionPeminjaman.UpdateOne(context.Background(),
-				bson.M{"_id": pinjam.BarangID},
-				bson.M{"$inc": bson.M{"stok": -pinjam.Jumlah}})
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		} else if updateData.Status == models.StatusDikembalikan {
+			// Semua unit yang belum kembali dianggap dikembalikan dalam kondisi baik
+			for i, item := range pinjam.Items {
+				if item.SisaPinjam() > 0 {
+					err := catatPengembalian(ctx, pinjam, i, item.SisaPinjam(), models.KondisiBaik, updateData.Alasan, userID, now)
+					if err != nil {
+						return err
+					}
+				}
 			}
-		} else if pinjam.Status == "dipinjam" && updateData.Status == "dikembalikan" {
-			_, err := barangCollectionPeminjaman.UpdateOne(context.Background(),
-				bson.M{"_id": pinjam.BarangID},
-				bson.M{"$inc": bson.M{"stok": pinjam.Jumlah}})
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			pinjam.TanggalKembali = now
+		} else if menahanSebelum && !menahanSesudah {
+			if err := lepasStokPeminjaman(ctx, pinjam, models.MutasiPeminjamanBatal, userID); err != nil {
+				return err
 			}
 		}
-	}
 
-	// Update status
-	_, err = peminjamanCollection.UpdateOne(context.Background(),
-		bson.M{"_id": id},
-		bson.M{"$set": bson.M{"status": updateData.Status}})
+		pinjam.RiwayatStatus = append(pinjam.RiwayatStatus, models.RiwayatStatus{
+			Dari:    pinjam.Status,
+			Ke:      updateData.Status,
+			Oleh:    userID,
+			Alasan:  updateData.Alasan,
+			Tanggal: now,
+		})
+
+		// Update status
+		pinjam.Status = updateData.Status
+		if err := peminjamanRepo.Update(ctx, pinjam); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditStatusPeminjaman, models.ResourcePeminjaman, id, sebelum, pinjam, nil)
+	})
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return respondStokError(c, err)
 	}
 
 	return c.JSON(fiber.Map{"message": "Status berhasil diperbarui"})
 }
 
+// cekIzinTransisi memastikan pemanggil boleh memindahkan pinjam ke status ke.
+// Menandai dikembalikan butuh peminjaman:return, perpindahan lain butuh
+// peminjaman:approve. Pemilik peminjaman boleh membatalkan pengajuannya sendiri
+// selama masih berstatus diajukan.
+func cekIzinTransisi(c *fiber.Ctx, pinjam *models.Peminjaman, ke string) error {
+	if ke == models.StatusDibatalkan && pinjam.Status == models.StatusDiajukan && milikUser(pinjam, currentUserID(c)) {
+		return nil
+	}
+	permission := models.PermPeminjamanApprove
+	if ke == models.StatusDikembalikan {
+		permission = models.PermPeminjamanReturn
+	}
+	if !middlewares.HasPermission(c, permission) {
+		return fiber.NewError(403, "Akses ditolak. Membutuhkan permission "+permission)
+	}
+	return nil
+}
+
 // DeletePeminjaman godoc
 // @Summary Delete peminjaman
 // @Description Menghapus data peminjaman berdasarkan ID
@@ -237,30 +423,47 @@ func DeletePeminjaman(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	// Cari data peminjaman yang akan dihapus
-	var peminjaman models.Peminjaman
-	err = peminjamanCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&peminjaman)
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Data peminjaman tidak ditemukan"})
-	}
-
-	// Jika status masih "dipinjam", kembalikan stok barang
-	if peminjaman.Status == "dipinjam" {
-		_, err = barangCollectionPeminjaman.UpdateOne(
-			context.Background(),
-			bson.M{"_id": peminjaman.BarangID},
-			bson.M{"$inc": bson.M{"stok": peminjaman.Jumlah}},
-		)
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		// Cari data peminjaman yang akan dihapus
+		peminjaman, err := peminjamanRepo.FindByID(ctx, id)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(404, "Data peminjaman tidak ditemukan")
+		}
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengembalikan stok barang"})
+			return err
+		}
+
+		// Jika stok masih tertahan (disetujui/dipinjam), kembalikan stok semua item
+		if models.MenahanStok(peminjaman.Status) {
+			if err := lepasStokPeminjaman(ctx, peminjaman, models.MutasiPeminjamanDihapus, currentUserID(c)); err != nil {
+				return err
+			}
 		}
-	}
 
-	// Hapus data peminjaman
-	_, err = peminjamanCollection.DeleteOne(context.Background(), bson.M{"_id": id})
+		// Pindahkan ke tempat sampah; stok dipesan lagi jika dipulihkan
+		if err := peminjamanRepo.Delete(ctx, id, currentUserID(c)); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditHapusPeminjaman, models.ResourcePeminjaman, id, peminjaman, nil, nil)
+	})
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus data peminjaman"})
+		return respondStokError(c, err)
 	}
 
 	return c.Status(200).JSON(fiber.Map{"message": "Data peminjaman berhasil dihapus"})
 }
+
+// respondStokError memetakan error dari transaksi stok ke response HTTP
+func respondStokError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": fiberErr.Message})
+	case errors.Is(err, repository.ErrStokTidakCukup):
+		return c.Status(400).JSON(fiber.Map{"error": "Stok barang tidak mencukupi"})
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan"})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// gabungItems menggabungkan baris dengan barang yang sama agar setiap barang
+// hanya muncul sekali di dalam satu peminjaman
+func gabungItems(items []models.ItemPeminjaman) []models.ItemPeminjaman {
+	hasil := []models.ItemPeminjaman{}
+	posisi := map[primitive.ObjectID]int{}
+	for _, item := range items {
+		if i, ok := posisi[item.BarangID]; ok {
+			hasil[i].Jumlah += item.Jumlah
+			continue
+		}
+		posisi[item.BarangID] = len(hasil)
+		hasil = append(hasil, models.ItemPeminjaman{BarangID: item.BarangID, Jumlah: item.Jumlah})
+	}
+	return hasil
+}
+
+// cariItem mengembalikan indeks baris untuk barang tertentu, atau -1 jika tidak ada
+func cariItem(p *models.Peminjaman, barangID primitive.ObjectID) int {
+	for i, item := range p.Items {
+		if item.BarangID == barangID {
+			return i
+		}
+	}
+	return -1
+}
+
+// stokTidakCukup membuat pesan error yang menyebutkan nama barang
+func stokTidakCukup(ctx context.Context, barangID primitive.ObjectID) error {
+	nama := barangID.Hex()
+	if barang, err := barangRepo.FindByID(ctx, barangID); err == nil {
+		nama = barang.Nama
+	}
+	return fiber.NewError(400, fmt.Sprintf("Stok barang %s tidak mencukupi", nama))
+}
+
+// pesanStokPeminjaman mengurangi stok semua item peminjaman. Dipanggil di dalam
+// transaksi, sehingga jika satu barang tidak cukup semua pengurangan dibatalkan.
+func pesanStokPeminjaman(ctx context.Context, p *models.Peminjaman, alasan string, userID primitive.ObjectID) error {
+	for _, item := range p.Items {
+		err := ubahStok(ctx, item.BarangID, -item.SisaPinjam(), alasan, userID, &p.ID)
+		if errors.Is(err, repository.ErrStokTidakCukup) {
+			return stokTidakCukup(ctx, item.BarangID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lepasStokPeminjaman mengembalikan stok untuk semua unit yang belum dikembalikan
+// tanpa menandai item sebagai dikembalikan (untuk pembatalan dan penghapusan)
+func lepasStokPeminjaman(ctx context.Context, p *models.Peminjaman, alasan string, userID primitive.ObjectID) error {
+	for _, item := range p.Items {
+		if err := ubahStok(ctx, item.BarangID, item.SisaPinjam(), alasan, userID, &p.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catatPengembalian menerima kembali sejumlah unit dari baris ke-i. Hanya unit
+// berkondisi baik yang kembali ke stok tersedia; unit rusak dan hilang dicatat
+// terpisah di barang.
+func catatPengembalian(ctx context.Context, p *models.Peminjaman, i, jumlah int, kondisi, catatan string, userID primitive.ObjectID, tanggal string) error {
+	item := &p.Items[i]
+	if jumlah <= 0 {
+		return fiber.NewError(400, "Jumlah pengembalian harus lebih dari 0")
+	}
+	if jumlah > item.SisaPinjam() {
+		return fiber.NewError(400, fmt.Sprintf("Jumlah pengembalian barang %s melebihi sisa pinjam (%d)", item.BarangID.Hex(), item.SisaPinjam()))
+	}
+
+	var err error
+	switch kondisi {
+	case models.KondisiBaik:
+		err = ubahStok(ctx, item.BarangID, jumlah, models.MutasiKembali, userID, &p.ID)
+	case models.KondisiHilang:
+		err = barangRepo.IncrementStokTidakTersedia(ctx, item.BarangID, 0, jumlah)
+	default:
+		err = barangRepo.IncrementStokTidakTersedia(ctx, item.BarangID, jumlah, 0)
+	}
+	if err != nil {
+		return err
+	}
+
+	item.JumlahKembali += jumlah
+	if item.SisaPinjam() == 0 {
+		item.TanggalKembali = tanggal
+	}
+	p.Pengembalian = append(p.Pengembalian, models.Pengembalian{
+		BarangID:     item.BarangID,
+		Jumlah:       jumlah,
+		Kondisi:      kondisi,
+		Catatan:      catatan,
+		DiterimaOleh: userID,
+		Tanggal:      tanggal,
+	})
+	return nil
+}
+
+// tutupJikaSemuaKembali menyelesaikan peminjaman berstatus dipinjam jika semua
+// unit sudah tercatat kembali
+func tutupJikaSemuaKembali(p *models.Peminjaman, userID primitive.ObjectID, tanggal string) {
+	if p.Status != models.StatusDipinjam || !p.SemuaKembali() {
+		return
+	}
+	p.TanggalKembali = tanggal
+	p.RiwayatStatus = append(p.RiwayatStatus, models.RiwayatStatus{
+		Dari:    p.Status,
+		Ke:      models.StatusDikembalikan,
+		Oleh:    userID,
+		Alasan:  "Semua unit sudah dikembalikan",
+		Tanggal: tanggal,
+	})
+	p.Status = models.StatusDikembalikan
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestPersetujuanParalelMemory menjalankan ujiPersetujuanParalel dengan backend
+// in-memory. Backend ini memakai satu mutex untuk semua operasi, jadi hanya
+// memeriksa alur controller; guard stok MongoDB diuji TestPersetujuanParalelMongo.
+func TestPersetujuanParalelMemory(t *testing.T) {
+	ujiPersetujuanParalel(t, repository.NewMemoryRepositories())
+}
+
+// TestPersetujuanParalelMongo menjalankan ujiPersetujuanParalel dengan MongoDB
+// di MONGODB_TEST_URI (wajib replica set karena memakai transaksi). Database
+// sementara dibuat dan dihapus lagi setelah test selesai.
+func TestPersetujuanParalelMongo(t *testing.T) {
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI tidak diset")
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect(ctx) })
+
+	db := client.Database("inventory_test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() { db.Drop(ctx) })
+	if err := repository.EnsureMongoIndexes(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	ujiPersetujuanParalel(t, repository.NewMongoRepositories(db))
+}
+
+// ujiPersetujuanParalel menyetujui banyak peminjaman secara bersamaan untuk
+// barang dengan stok terbatas. Hanya sebanyak stok yang boleh berhasil dan
+// stok akhir tidak boleh negatif.
+func ujiPersetujuanParalel(t *testing.T, repos *repository.Repositories) {
+	const stokAwal = 3
+	const jumlahPengajuan = 10
+
+	SetRepositories(repos)
+	ctx := context.Background()
+
+	barang := models.Barang{ID: primitive.NewObjectID(), Nama: "Proyektor", KategoriID: primitive.NewObjectID(), Stok: stokAwal}
+	if err := barangRepo.Create(ctx, &barang); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]primitive.ObjectID, jumlahPengajuan)
+	for i := range ids {
+		p := models.Peminjaman{
+			ID:           primitive.NewObjectID(),
+			NamaPeminjam: "Tamu",
+			Items:        []models.ItemPeminjaman{{BarangID: barang.ID, Jumlah: 1}},
+			Status:       models.StatusDiajukan,
+		}
+		if err := peminjamanRepo.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = p.ID
+	}
+
+	adminID := primitive.NewObjectID().Hex()
+	app := fiber.New()
+	app.Put("/peminjaman/:id", func(c *fiber.Ctx) error {
+		c.Locals("user_id", adminID)
+		c.Locals("permissions", []string{models.PermPeminjamanApprove})
+		return c.Next()
+	}, UpdateStatusPeminjaman)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	status := map[int]int{}
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id primitive.ObjectID) {
+			defer wg.Done()
+			req := httptest.NewRequest("PUT", "/peminjaman/"+id.Hex(), strings.NewReader(`{"status":"disetujui"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			status[resp.StatusCode]++
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	if status[200] != stokAwal {
+		t.Errorf("persetujuan berhasil = %d, ingin %d (status: %v)", status[200], stokAwal, status)
+	}
+	if status[400] != jumlahPengajuan-stokAwal {
+		t.Errorf("persetujuan ditolak = %d, ingin %d (status: %v)", status[400], jumlahPengajuan-stokAwal, status)
+	}
+
+	akhir, err := barangRepo.FindByID(ctx, barang.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if akhir.Stok < 0 {
+		t.Errorf("stok akhir negatif: %d", akhir.Stok)
+	}
+	if akhir.Stok != 0 {
+		t.Errorf("stok akhir = %d, ingin 0", akhir.Stok)
+	}
+}
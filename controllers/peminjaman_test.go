@@ -0,0 +1,487 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// appPengguna membuat app Fiber yang menjalankan request sebagai userID dengan
+// permission perms, seperti yang diisi JWTMiddleware
+func appPengguna(userID primitive.ObjectID, perms ...string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID.Hex())
+		c.Locals("permissions", perms)
+		return c.Next()
+	})
+	return app
+}
+
+// kirimJSON mengirim request dengan body JSON (boleh kosong) lalu mengembalikan
+// status dan body respons yang sudah di-decode
+func kirimJSON(t *testing.T, app *fiber.App, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hasil map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&hasil)
+	return resp.StatusCode, hasil
+}
+
+// besok mengembalikan tanggal jatuh tempo yang valid untuk peminjaman baru
+func besok() string {
+	return time.Now().AddDate(0, 0, 1).Format(models.FormatTanggal)
+}
+
+// siapkanBarang menyimpan barang dengan stok tertentu
+func siapkanBarang(t *testing.T, nama string, stok int) models.Barang {
+	t.Helper()
+	barang := models.Barang{ID: primitive.NewObjectID(), Nama: nama, KategoriID: primitive.NewObjectID(), Stok: stok}
+	if err := barangRepo.Create(context.Background(), &barang); err != nil {
+		t.Fatal(err)
+	}
+	return barang
+}
+
+func TestCreatePeminjamanEmailAkunBerhurufBesar(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	user := models.User{ID: primitive.NewObjectID(), Username: "Budi", Email: "Budi.Santoso@Example.COM", Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	barang := siapkanBarang(t, "Proyektor", 2)
+
+	app := appPengguna(user.ID, models.PermPeminjamanCreate)
+	app.Post("/peminjaman", CreatePeminjaman)
+
+	// Akun belum punya telepon, jadi telepon peminjam boleh kosong
+	status, body := kirimJSON(t, app, "POST", "/peminjaman",
+		`{"items":[{"barang_id":"`+barang.ID.Hex()+`","jumlah":1}],"tanggal_jatuh_tempo":"`+besok()+`"}`)
+	if status != 201 {
+		t.Fatalf("status = %d, body = %v, ingin 201", status, body)
+	}
+	if body["email_peminjam"] != user.Email || body["telepon_peminjam"] != "" {
+		t.Errorf("peminjam = %v/%v, ingin %s tanpa telepon", body["email_peminjam"], body["telepon_peminjam"], user.Email)
+	}
+}
+
+func TestCreatePeminjamanTeleponDariAkun(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Telepon: "08123", Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	barang := siapkanBarang(t, "Proyektor", 2)
+
+	app := appPengguna(user.ID, models.PermPeminjamanCreate)
+	app.Post("/peminjaman", CreatePeminjaman)
+
+	// Nama, email dan telepon dari body diabaikan untuk peminjaman milik akun
+	status, body := kirimJSON(t, app, "POST", "/peminjaman",
+		`{"nama_peminjam":"Orang Lain","email_peminjam":"lain@example.com","telepon_peminjam":"0999",`+
+			`"items":[{"barang_id":"`+barang.ID.Hex()+`","jumlah":1}],"tanggal_jatuh_tempo":"`+besok()+`"}`)
+	if status != 201 {
+		t.Fatalf("status = %d, body = %v, ingin 201", status, body)
+	}
+	if body["nama_peminjam"] != "budi" || body["email_peminjam"] != "budi@example.com" || body["telepon_peminjam"] != "08123" {
+		t.Errorf("peminjam = %v, ingin data dari akun", body)
+	}
+	if body["user_id"] != user.ID.Hex() {
+		t.Errorf("user_id = %v, ingin %s", body["user_id"], user.ID.Hex())
+	}
+}
+
+func TestCreatePeminjamanTamu(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	barang := siapkanBarang(t, "Proyektor", 2)
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanCreate, models.PermPeminjamanCreateForOthers)
+	app.Post("/peminjaman", CreatePeminjaman)
+
+	item := `"items":[{"barang_id":"` + barang.ID.Hex() + `","jumlah":1}],"tanggal_jatuh_tempo":"` + besok() + `"`
+	status, body := kirimJSON(t, app, "POST", "/peminjaman", `{"nama_peminjam":"Tamu","email_peminjam":"Tamu@Example.com",`+item+`}`)
+	if status != 400 {
+		t.Fatalf("tanpa telepon: status = %d, body = %v, ingin 400", status, body)
+	}
+
+	status, body = kirimJSON(t, app, "POST", "/peminjaman", `{"nama_peminjam":"Tamu","email_peminjam":"Tamu@Example.com","telepon_peminjam":"0812",`+item+`}`)
+	if status != 201 {
+		t.Fatalf("status = %d, body = %v, ingin 201", status, body)
+	}
+	if body["user_id"] != nil {
+		t.Errorf("user_id = %v, ingin kosong untuk peminjam tamu", body["user_id"])
+	}
+}
+
+func TestPeminjamanSayaHanyaMilikSendiri(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	budi := primitive.NewObjectID()
+	sari := primitive.NewObjectID()
+	for _, p := range []models.Peminjaman{
+		{ID: primitive.NewObjectID(), UserID: &budi, NamaPeminjam: "budi", Status: models.StatusDiajukan},
+		{ID: primitive.NewObjectID(), UserID: &budi, NamaPeminjam: "budi", Status: models.StatusDipinjam},
+		{ID: primitive.NewObjectID(), UserID: &sari, NamaPeminjam: "sari", Status: models.StatusDiajukan},
+		{ID: primitive.NewObjectID(), NamaPeminjam: "tamu", Status: models.StatusDiajukan},
+	} {
+		if err := peminjamanRepo.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := appPengguna(budi, models.PermPeminjamanCreate)
+	app.Get("/peminjaman", GetAllPeminjaman)
+	app.Get("/peminjaman/saya", GetPeminjamanSaya)
+
+	for _, path := range []string{"/peminjaman/saya", "/peminjaman"} {
+		status, body := kirimJSON(t, app, "GET", path, "")
+		if status != 200 {
+			t.Fatalf("%s: status = %d, ingin 200", path, status)
+		}
+		data, _ := body["data"].([]interface{})
+		if len(data) != 2 {
+			t.Fatalf("%s: jumlah data = %d, ingin 2", path, len(data))
+		}
+		for _, d := range data {
+			if d.(map[string]interface{})["user_id"] != budi.Hex() {
+				t.Errorf("%s: peminjaman milik user lain ikut tampil: %v", path, d)
+			}
+		}
+	}
+
+	// Dengan peminjaman:read_all semua peminjaman terlihat di GET /peminjaman
+	admin := appPengguna(primitive.NewObjectID(), models.PermPeminjamanReadAll)
+	admin.Get("/peminjaman", GetAllPeminjaman)
+	if _, body := kirimJSON(t, admin, "GET", "/peminjaman", ""); body["meta"].(map[string]interface{})["total"] != float64(4) {
+		t.Errorf("meta admin = %v, ingin total 4", body["meta"])
+	}
+}
+
+func TestUbahStatusDicekPerTransisi(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	barang := siapkanBarang(t, "Proyektor", 10)
+	pemilik := primitive.NewObjectID()
+
+	buat := func(status string) primitive.ObjectID {
+		p := models.Peminjaman{
+			ID:     primitive.NewObjectID(),
+			UserID: &pemilik,
+			Items:  []models.ItemPeminjaman{{BarangID: barang.ID, Jumlah: 1}},
+			Status: status,
+		}
+		if err := peminjamanRepo.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+		return p.ID
+	}
+
+	tests := []struct {
+		nama  string
+		user  primitive.ObjectID
+		perms []string
+		dari  string
+		ke    string
+		ingin int
+	}{
+		{"pemilik membatalkan pengajuan", pemilik, []string{models.PermPeminjamanCreate}, models.StatusDiajukan, models.StatusDibatalkan, 200},
+		{"pemilik menyetujui sendiri", pemilik, []string{models.PermPeminjamanCreate}, models.StatusDiajukan, models.StatusDisetujui, 403},
+		{"pemilik membatalkan yang sudah disetujui", pemilik, []string{models.PermPeminjamanCreate}, models.StatusDisetujui, models.StatusDibatalkan, 403},
+		{"user lain membatalkan", primitive.NewObjectID(), []string{models.PermPeminjamanCreate}, models.StatusDiajukan, models.StatusDibatalkan, 403},
+		{"approver menolak", primitive.NewObjectID(), []string{models.PermPeminjamanApprove}, models.StatusDiajukan, models.StatusDitolak, 200},
+		{"approver membatalkan yang disetujui", primitive.NewObjectID(), []string{models.PermPeminjamanApprove}, models.StatusDisetujui, models.StatusDibatalkan, 200},
+		{"approver menandai dikembalikan", primitive.NewObjectID(), []string{models.PermPeminjamanApprove}, models.StatusDipinjam, models.StatusDikembalikan, 403},
+		{"petugas menandai dikembalikan", primitive.NewObjectID(), []string{models.PermPeminjamanReturn}, models.StatusDipinjam, models.StatusDikembalikan, 200},
+		{"petugas menyetujui", primitive.NewObjectID(), []string{models.PermPeminjamanReturn}, models.StatusDiajukan, models.StatusDisetujui, 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			id := buat(tt.dari)
+			app := appPengguna(tt.user, tt.perms...)
+			app.Put("/peminjaman/:id", UpdateStatusPeminjaman)
+
+			status, body := kirimJSON(t, app, "PUT", "/peminjaman/"+id.Hex(), `{"status":"`+tt.ke+`","alasan":"uji"}`)
+			if status != tt.ingin {
+				t.Fatalf("status = %d, body = %v, ingin %d", status, body, tt.ingin)
+			}
+
+			ingin := tt.dari
+			if tt.ingin == 200 {
+				ingin = tt.ke
+			}
+			if p, _ := peminjamanRepo.FindByID(ctx, id); p.Status != ingin {
+				t.Errorf("status peminjaman = %s, ingin %s", p.Status, ingin)
+			}
+		})
+	}
+}
+
+func TestTurunkanJumlahMenutupPeminjaman(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	barang := siapkanBarang(t, "Proyektor", 5)
+	p := models.Peminjaman{
+		ID:     primitive.NewObjectID(),
+		Items:  []models.ItemPeminjaman{{BarangID: barang.ID, Jumlah: 3, JumlahKembali: 1}},
+		Status: models.StatusDipinjam,
+	}
+	if err := peminjamanRepo.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanUpdate)
+	app.Put("/peminjaman/:id/jumlah", UpdateJumlahPeminjaman)
+
+	if status, body := kirimJSON(t, app, "PUT", "/peminjaman/"+p.ID.Hex()+"/jumlah", `{"jumlah":0}`); status != 400 {
+		t.Fatalf("jumlah 0: status = %d, body = %v, ingin 400", status, body)
+	}
+	if status, body := kirimJSON(t, app, "PUT", "/peminjaman/"+p.ID.Hex()+"/jumlah", `{"jumlah":1}`); status != 200 {
+		t.Fatalf("status = %d, body = %v, ingin 200", status, body)
+	}
+
+	akhir, err := peminjamanRepo.FindByID(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if akhir.Status != models.StatusDikembalikan || akhir.TanggalKembali == "" || akhir.Items[0].TanggalKembali == "" {
+		t.Errorf("peminjaman = %s (kembali %q, item %q), ingin dikembalikan dengan tanggal kembali",
+			akhir.Status, akhir.TanggalKembali, akhir.Items[0].TanggalKembali)
+	}
+	if n := len(akhir.RiwayatStatus); n == 0 || akhir.RiwayatStatus[n-1].Ke != models.StatusDikembalikan {
+		t.Errorf("riwayat status = %v, ingin diakhiri dikembalikan", akhir.RiwayatStatus)
+	}
+	// Dua unit yang batal dipinjam kembali ke stok
+	if b, _ := barangRepo.FindByID(ctx, barang.ID); b.Stok != 7 {
+		t.Errorf("stok = %d, ingin 7", b.Stok)
+	}
+
+	if status, _ := kirimJSON(t, app, "PUT", "/peminjaman/"+p.ID.Hex()+"/jumlah", `{"jumlah":2}`); status != 409 {
+		t.Errorf("ubah jumlah peminjaman selesai: status = %d, ingin 409", status)
+	}
+}
+
+// TestAlurStatusPeminjaman menjalankan peminjaman dari pengajuan sampai
+// dikembalikan dan memastikan stok dipesan, dikembalikan dan status akhir
+// tidak bisa diubah lagi
+func TestAlurStatusPeminjaman(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	barang := siapkanBarang(t, "Proyektor", 2)
+
+	buat := func() primitive.ObjectID {
+		t.Helper()
+		p := models.Peminjaman{
+			ID:     primitive.NewObjectID(),
+			Items:  []models.ItemPeminjaman{{BarangID: barang.ID, Jumlah: 2}},
+			Status: models.StatusDiajukan,
+		}
+		if err := peminjamanRepo.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+		return p.ID
+	}
+	stok := func() int {
+		t.Helper()
+		b, err := barangRepo.FindByID(ctx, barang.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b.Stok
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanApprove, models.PermPeminjamanReturn)
+	app.Put("/peminjaman/:id", UpdateStatusPeminjaman)
+	ubah := func(id primitive.ObjectID, ke string) (int, map[string]interface{}) {
+		return kirimJSON(t, app, "PUT", "/peminjaman/"+id.Hex(), `{"status":"`+ke+`","alasan":"uji"}`)
+	}
+
+	id := buat()
+	if status, body := ubah(id, models.StatusDipinjam); status != 409 {
+		t.Errorf("diajukan ke dipinjam: status = %d, body = %v, ingin 409", status, body)
+	}
+
+	langkah := []struct {
+		ke   string
+		stok int
+	}{
+		{models.StatusDisetujui, 0},
+		{models.StatusDipinjam, 0},
+		{models.StatusDikembalikan, 2},
+	}
+	for _, l := range langkah {
+		if status, body := ubah(id, l.ke); status != 200 {
+			t.Fatalf("ubah ke %s: status = %d, body = %v", l.ke, status, body)
+		}
+		if s := stok(); s != l.stok {
+			t.Errorf("stok setelah %s = %d, ingin %d", l.ke, s, l.stok)
+		}
+	}
+
+	p, err := peminjamanRepo.FindByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.RiwayatStatus) != 3 || p.TanggalKembali == "" {
+		t.Errorf("riwayat = %d, tanggal kembali = %q, ingin 3 riwayat dan tanggal kembali terisi", len(p.RiwayatStatus), p.TanggalKembali)
+	}
+
+	// Status akhir tidak bisa diubah lagi dan stok tidak berubah
+	for _, ke := range []string{models.StatusDisetujui, models.StatusDibatalkan, models.StatusDipinjam} {
+		if status, body := ubah(id, ke); status != 409 {
+			t.Errorf("dikembalikan ke %s: status = %d, body = %v, ingin 409", ke, status, body)
+		}
+	}
+	if s := stok(); s != 2 {
+		t.Errorf("stok setelah perpindahan ditolak = %d, ingin 2", s)
+	}
+
+	// Membatalkan peminjaman yang sudah disetujui melepas stoknya
+	id = buat()
+	if status, body := ubah(id, models.StatusDisetujui); status != 200 {
+		t.Fatalf("setujui: status = %d, body = %v", status, body)
+	}
+	if status, body := ubah(id, models.StatusDibatalkan); status != 200 {
+		t.Fatalf("batalkan: status = %d, body = %v", status, body)
+	}
+	if s := stok(); s != 2 {
+		t.Errorf("stok setelah dibatalkan = %d, ingin 2", s)
+	}
+}
+
+// TestCreatePeminjamanBanyakBarang memastikan satu peminjaman bisa memuat
+// beberapa barang, baris dengan barang sama digabung, dan pengajuan ditolak
+// seluruhnya jika stok salah satu barang tidak cukup
+func TestCreatePeminjamanBanyakBarang(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	laptop := siapkanBarang(t, "Laptop", 2)
+	charger := siapkanBarang(t, "Charger", 1)
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanCreate, models.PermPeminjamanCreateForOthers)
+	app.Post("/peminjaman", CreatePeminjaman)
+	ajukan := func(items string) (int, map[string]interface{}) {
+		return kirimJSON(t, app, "POST", "/peminjaman",
+			`{"nama_peminjam":"Tamu","email_peminjam":"tamu@example.com","telepon_peminjam":"081234567890",`+
+				`"items":[`+items+`],"tanggal_jatuh_tempo":"`+besok()+`"}`)
+	}
+	item := func(b models.Barang, jumlah string) string {
+		return `{"barang_id":"` + b.ID.Hex() + `","jumlah":` + jumlah + `}`
+	}
+
+	status, body := ajukan(item(laptop, "1") + "," + item(charger, "2"))
+	if status != 400 {
+		t.Fatalf("charger tidak cukup: status = %d, body = %v, ingin 400", status, body)
+	}
+	if _, total, _ := peminjamanRepo.FindAll(ctx, repository.PeminjamanFilter{}, repository.ListOptions{}); total != 0 {
+		t.Errorf("peminjaman tersimpan = %d, ingin 0", total)
+	}
+
+	status, body = ajukan(item(laptop, "1") + "," + item(charger, "1") + "," + item(laptop, "1"))
+	if status != 201 {
+		t.Fatalf("status = %d, body = %v, ingin 201", status, body)
+	}
+	items := body["items"].([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("items = %v, ingin 2 baris", items)
+	}
+	if laptopItem := items[0].(map[string]interface{}); laptopItem["barang_id"] != laptop.ID.Hex() || laptopItem["jumlah"] != float64(2) {
+		t.Errorf("baris laptop = %v, ingin jumlah 2", laptopItem)
+	}
+
+	// Stok belum berkurang sampai peminjaman disetujui
+	if b, _ := barangRepo.FindByID(ctx, laptop.ID); b.Stok != 2 {
+		t.Errorf("stok laptop = %d, ingin 2", b.Stok)
+	}
+}
+
+// TestSetujuiPeminjamanSatuBarangKurang memastikan persetujuan tidak
+// mengurangi stok barang mana pun jika salah satu barang sudah tidak cukup
+func TestSetujuiPeminjamanSatuBarangKurang(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	laptop := siapkanBarang(t, "Laptop", 2)
+	charger := siapkanBarang(t, "Charger", 0)
+
+	p := models.Peminjaman{
+		ID:     primitive.NewObjectID(),
+		Items:  []models.ItemPeminjaman{{BarangID: laptop.ID, Jumlah: 1}, {BarangID: charger.ID, Jumlah: 1}},
+		Status: models.StatusDiajukan,
+	}
+	if err := peminjamanRepo.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanApprove)
+	app.Put("/peminjaman/:id", UpdateStatusPeminjaman)
+	status, body := kirimJSON(t, app, "PUT", "/peminjaman/"+p.ID.Hex(), `{"status":"disetujui"}`)
+	if status != 400 || !strings.Contains(body["error"].(string), "Charger") {
+		t.Fatalf("status = %d, body = %v, ingin 400 menyebut Charger", status, body)
+	}
+
+	if b, _ := barangRepo.FindByID(ctx, laptop.ID); b.Stok != 2 {
+		t.Errorf("stok laptop = %d, ingin tetap 2", b.Stok)
+	}
+	if akhir, _ := peminjamanRepo.FindByID(ctx, p.ID); akhir.Status != models.StatusDiajukan {
+		t.Errorf("status = %s, ingin tetap diajukan", akhir.Status)
+	}
+	if mutasi, _, _ := mutasiRepo.FindByBarang(ctx, laptop.ID, repository.ListOptions{}); len(mutasi) != 0 {
+		t.Errorf("mutasi laptop = %+v, ingin kosong", mutasi)
+	}
+}
+
+// TestLaporanPeminjamanPerBaris memastikan laporan berisi satu baris untuk
+// setiap barang di dalam peminjaman
+func TestLaporanPeminjamanPerBaris(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	laptop := siapkanBarang(t, "Laptop", 2)
+	charger := siapkanBarang(t, "Charger", 2)
+
+	p := models.Peminjaman{
+		ID:     primitive.NewObjectID(),
+		Items:  []models.ItemPeminjaman{{BarangID: laptop.ID, Jumlah: 1}, {BarangID: charger.ID, Jumlah: 2}},
+		Status: models.StatusDiajukan,
+	}
+	if err := peminjamanRepo.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermLaporanRead)
+	app.Get("/laporan/peminjaman", GetLaporanPeminjaman)
+	req := httptest.NewRequest("GET", "/laporan/peminjaman", nil)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var baris []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&baris); err != nil {
+		t.Fatal(err)
+	}
+	if len(baris) != 2 {
+		t.Fatalf("jumlah baris = %d, ingin 2", len(baris))
+	}
+	for i, ingin := range []struct {
+		nama   string
+		jumlah float64
+	}{{"Laptop", 1}, {"Charger", 2}} {
+		info, _ := baris[i]["barang_info"].(map[string]interface{})
+		if info["nama"] != ingin.nama || baris[i]["jumlah"] != ingin.jumlah {
+			t.Errorf("baris %d = %v jumlah %v, ingin %s jumlah %v", i, info["nama"], baris[i]["jumlah"], ingin.nama, ingin.jumlah)
+		}
+	}
+}
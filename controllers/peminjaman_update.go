@@ -2,14 +2,30 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"inventory-backend/models"
+	"inventory-backend/repository"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// UpdateJumlahPeminjaman mengubah jumlah peminjaman dan otomatis update stok barang
+// UpdateJumlahPeminjaman godoc
+// @Summary Ubah jumlah item peminjaman
+// @Description Mengubah jumlah satu barang di peminjaman berstatus diajukan, disetujui atau dipinjam. Untuk status disetujui dan dipinjam stok barang ikut disesuaikan. barang_id boleh dikosongkan jika peminjaman hanya berisi satu barang. Jumlah tidak boleh kurang dari unit yang sudah dikembalikan; jika jumlah diturunkan sampai semua unit tercatat kembali, peminjaman otomatis berstatus dikembalikan.
+// @Tags Peminjaman
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Peminjaman ID"
+// @Param jumlah body object{barang_id=string,jumlah=int} true "Barang dan jumlah baru"
+// @Success 200 {object} map[string]interface{} "Jumlah berhasil diubah atau tidak berubah"
+// @Failure 400 {object} map[string]interface{} "Bad request, barang tidak ada di peminjaman, jumlah kurang dari yang sudah dikembalikan, atau stok tidak mencukupi"
+// @Failure 404 {object} map[string]interface{} "Data peminjaman tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Status peminjaman sudah selesai (ditolak, dikembalikan atau dibatalkan)"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /peminjaman/{id}/jumlah [put]
 func UpdateJumlahPeminjaman(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := primitive.ObjectIDFromHex(idParam)
@@ -18,66 +34,90 @@ func UpdateJumlahPeminjaman(c *fiber.Ctx) error {
 	}
 
 	var updateData struct {
-		Jumlah int `json:"jumlah"`
+		BarangID primitive.ObjectID `json:"barang_id"`
+		Jumlah   int                `json:"jumlah"`
 	}
 	if err := c.BodyParser(&updateData); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// Ambil data peminjaman lama
-	var pinjam models.Peminjaman
-	err = peminjamanCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&pinjam)
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Data peminjaman tidak ditemukan"})
-	}
-
-	if pinjam.Status != "dipinjam" {
-		return c.Status(400).JSON(fiber.Map{"error": "Hanya peminjaman dengan status 'dipinjam' yang bisa diubah jumlahnya"})
-	}
-
 	if updateData.Jumlah <= 0 {
 		return c.Status(400).JSON(fiber.Map{"error": "Jumlah pinjam harus lebih dari 0"})
 	}
 
-	if updateData.Jumlah == pinjam.Jumlah {
-		return c.Status(200).JSON(fiber.Map{"message": "Jumlah tidak berubah"})
-	}
+	unchanged := false
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		// Ambil data peminjaman lama
+		pinjam, err := peminjamanRepo.FindByID(ctx, id)
+		if err != nil {
+			return fiber.NewError(404, "Data peminjaman tidak ditemukan")
+		}
 
-	// Ambil data barang
-	var barang models.Barang
-	err = barangCollectionPeminjaman.FindOne(context.Background(), bson.M{"_id": pinjam.BarangID}).Decode(&barang)
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan"})
-	}
+		if pinjam.Status != models.StatusDiajukan && !models.MenahanStok(pinjam.Status) {
+			return fiber.NewError(409, "Hanya peminjaman yang masih berjalan yang bisa diubah jumlahnya")
+		}
 
-	diff := updateData.Jumlah - pinjam.Jumlah
-	if diff > 0 {
-		// Tambah jumlah pinjam, cek stok cukup
-		if diff > barang.Stok {
-			return c.Status(400).JSON(fiber.Map{"error": "Stok barang tidak mencukupi"})
+		i := cariItem(pinjam, updateData.BarangID)
+		if updateData.BarangID.IsZero() && len(pinjam.Items) == 1 {
+			i = 0
 		}
-		_, err = barangCollectionPeminjaman.UpdateOne(context.Background(),
-			bson.M{"_id": barang.ID},
-			bson.M{"$inc": bson.M{"stok": -diff}})
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		if i < 0 {
+			return fiber.NewError(400, "barang_id tidak ada di peminjaman ini")
 		}
-	} else if diff < 0 {
-		// Kurangi jumlah pinjam, kembalikan stok
-		_, err = barangCollectionPeminjaman.UpdateOne(context.Background(),
-			bson.M{"_id": barang.ID},
-			bson.M{"$inc": bson.M{"stok": -diff}}) // -diff karena diff negatif
+		item := &pinjam.Items[i]
+
+		if updateData.Jumlah < item.JumlahKembali {
+			return fiber.NewError(400, "Jumlah tidak boleh kurang dari jumlah yang sudah dikembalikan")
+		}
+
+		unchanged = updateData.Jumlah == item.Jumlah
+		if unchanged {
+			return nil
+		}
+
+		sebelum, err := snapshot(pinjam)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			return err
 		}
-	}
 
-	// Update jumlah di peminjaman
-	_, err = peminjamanCollection.UpdateOne(context.Background(),
-		bson.M{"_id": id},
-		bson.M{"$set": bson.M{"jumlah": updateData.Jumlah}})
+		// Stok hanya disesuaikan jika peminjaman sudah menahan stok
+		// diff > 0: tambah jumlah pinjam, stok dikurangi secara atomik
+		// diff < 0: kurangi jumlah pinjam, stok dikembalikan
+		if models.MenahanStok(pinjam.Status) {
+			diff := updateData.Jumlah - item.Jumlah
+			alasan := models.MutasiPinjam
+			if diff < 0 {
+				alasan = models.MutasiKembali
+			}
+			err := ubahStok(ctx, item.BarangID, -diff, alasan, currentUserID(c), &pinjam.ID)
+			if errors.Is(err, repository.ErrStokTidakCukup) {
+				return stokTidakCukup(ctx, item.BarangID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// Update jumlah di item peminjaman. Jika jumlah diturunkan sampai sama
+		// dengan unit yang sudah kembali, item dan peminjaman ikut selesai.
+		now := time.Now().Format(models.FormatTanggalWaktu)
+		item.Jumlah = updateData.Jumlah
+		if item.SisaPinjam() == 0 && item.TanggalKembali == "" {
+			item.TanggalKembali = now
+		}
+		tutupJikaSemuaKembali(pinjam, currentUserID(c), now)
+		if err := peminjamanRepo.Update(ctx, pinjam); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditJumlahPeminjaman, models.ResourcePeminjaman, id, sebelum, pinjam,
+			map[string]interface{}{"barang_id": item.BarangID})
+	})
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return respondStokError(c, err)
+	}
+
+	if unchanged {
+		return c.Status(200).JSON(fiber.Map{"message": "Jumlah tidak berubah"})
 	}
 
 	return c.JSON(fiber.Map{"message": "Jumlah peminjaman berhasil diubah"})
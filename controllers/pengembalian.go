@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ItemPengembalian adalah satu baris di request pengembalian
+type ItemPengembalian struct {
+	BarangID primitive.ObjectID `json:"barang_id"`
+	Jumlah   int                `json:"jumlah"`
+	Kondisi  string             `json:"kondisi"`
+	Catatan  string             `json:"catatan"`
+}
+
+// PengembalianRequest adalah body untuk mencatat pengembalian
+type PengembalianRequest struct {
+	Items []ItemPengembalian `json:"items"`
+	// BarangIDs adalah bentuk singkat: seluruh sisa unit barang tersebut kembali dalam kondisi baik
+	BarangIDs []primitive.ObjectID `json:"barang_ids"`
+}
+
+// KembalikanItemPeminjaman godoc
+// @Summary Catat pengembalian peminjaman
+// @Description Mencatat pengembalian sebagian atau seluruh unit, boleh dalam beberapa kali, dengan kondisi baik, rusak_ringan, rusak_berat atau hilang. Hanya unit berkondisi baik yang kembali ke stok; unit rusak dan hilang dicatat di stok_rusak dan stok_hilang barang. Peminjaman otomatis selesai ('dikembalikan') jika semua unit sudah tercatat kembali.
+// @Tags Peminjaman
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Peminjaman ID"
+// @Param pengembalian body PengembalianRequest true "Item yang dikembalikan"
+// @Success 200 {object} models.Peminjaman "Peminjaman setelah pengembalian"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Data peminjaman tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Peminjaman tidak sedang dipinjam"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /peminjaman/{id}/pengembalian [post]
+func KembalikanItemPeminjaman(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	var body PengembalianRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(body.Items) == 0 && len(body.BarangIDs) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "items wajib diisi"})
+	}
+	for _, item := range body.Items {
+		if err := validators.ValidateKondisi(item.Kondisi); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	userID := currentUserID(c)
+	var hasil *models.Peminjaman
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		pinjam, err := peminjamanRepo.FindByID(ctx, id)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(404, "Data peminjaman tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+
+		if pinjam.Status != models.StatusDipinjam {
+			return fiber.NewError(409, "Hanya peminjaman berstatus 'dipinjam' yang bisa dikembalikan")
+		}
+
+		sebelum, err := snapshot(pinjam)
+		if err != nil {
+			return err
+		}
+
+		// Bentuk singkat barang_ids: seluruh sisa unit kembali dalam kondisi baik
+		items := body.Items
+		for _, barangID := range body.BarangIDs {
+			if i := cariItem(pinjam, barangID); i >= 0 {
+				items = append(items, ItemPengembalian{
+					BarangID: barangID,
+					Jumlah:   pinjam.Items[i].SisaPinjam(),
+					Kondisi:  models.KondisiBaik,
+				})
+			} else {
+				items = append(items, ItemPengembalian{BarangID: barangID})
+			}
+		}
+
+		now := time.Now().Format(models.FormatTanggalWaktu)
+		for _, item := range items {
+			i := cariItem(pinjam, item.BarangID)
+			if i < 0 {
+				return fiber.NewError(400, "Barang "+item.BarangID.Hex()+" tidak ada di peminjaman ini")
+			}
+			if pinjam.Items[i].SisaPinjam() == 0 {
+				return fiber.NewError(400, "Barang "+item.BarangID.Hex()+" sudah dikembalikan")
+			}
+			if err := catatPengembalian(ctx, pinjam, i, item.Jumlah, item.Kondisi, item.Catatan, userID, now); err != nil {
+				return err
+			}
+		}
+
+		// Peminjaman selesai otomatis jika semua unit sudah tercatat kembali
+		tutupJikaSemuaKembali(pinjam, userID, now)
+
+		hasil = pinjam
+		if err := peminjamanRepo.Update(ctx, pinjam); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditPengembalian, models.ResourcePeminjaman, id, sebelum, pinjam, nil)
+	})
+	if err != nil {
+		return respondStokError(c, err)
+	}
+
+	return c.JSON(hasil)
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestPengembalianSebagian mencatat pengembalian dalam beberapa kali dengan
+// kondisi berbeda. Hanya unit baik yang kembali ke stok, unit rusak dan hilang
+// dicatat di barang, dan peminjaman selesai saat semua unit tercatat kembali.
+func TestPengembalianSebagian(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	// Stok tersisa 1 setelah 4 unit dipinjam
+	laptop := siapkanBarang(t, "Laptop", 1)
+
+	p := models.Peminjaman{
+		ID:     primitive.NewObjectID(),
+		Items:  []models.ItemPeminjaman{{BarangID: laptop.ID, Jumlah: 4}},
+		Status: models.StatusDipinjam,
+	}
+	if err := peminjamanRepo.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	petugas := primitive.NewObjectID()
+	app := appPengguna(petugas, models.PermPeminjamanReturn)
+	app.Post("/peminjaman/:id/pengembalian", KembalikanItemPeminjaman)
+	kembalikan := func(items string) (int, map[string]interface{}) {
+		return kirimJSON(t, app, "POST", "/peminjaman/"+p.ID.Hex()+"/pengembalian", `{"items":[`+items+`]}`)
+	}
+	item := func(jumlah, kondisi string) string {
+		return `{"barang_id":"` + laptop.ID.Hex() + `","jumlah":` + jumlah + `,"kondisi":"` + kondisi + `","catatan":"uji"}`
+	}
+	cekBarang := func(stok, rusak, hilang int) {
+		t.Helper()
+		b, err := barangRepo.FindByID(ctx, laptop.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Stok != stok || b.StokRusak != rusak || b.StokHilang != hilang {
+			t.Errorf("stok/rusak/hilang = %d/%d/%d, ingin %d/%d/%d", b.Stok, b.StokRusak, b.StokHilang, stok, rusak, hilang)
+		}
+	}
+
+	if status, body := kembalikan(item("1", "lecet")); status != 400 {
+		t.Errorf("kondisi tidak dikenal: status = %d, body = %v, ingin 400", status, body)
+	}
+
+	status, body := kembalikan(item("1", models.KondisiBaik) + "," + item("1", models.KondisiRusakRingan))
+	if status != 200 {
+		t.Fatalf("pengembalian pertama: status = %d, body = %v", status, body)
+	}
+	if body["status"] != models.StatusDipinjam {
+		t.Errorf("status = %v, ingin tetap dipinjam", body["status"])
+	}
+	cekBarang(2, 1, 0)
+
+	// Melebihi sisa pinjam ditolak seluruhnya
+	if status, body := kembalikan(item("1", models.KondisiBaik) + "," + item("2", models.KondisiBaik)); status != 400 {
+		t.Errorf("melebihi sisa: status = %d, body = %v, ingin 400", status, body)
+	}
+	cekBarang(2, 1, 0)
+
+	status, body = kembalikan(item("1", models.KondisiHilang) + "," + item("1", models.KondisiRusakBerat))
+	if status != 200 {
+		t.Fatalf("pengembalian kedua: status = %d, body = %v", status, body)
+	}
+	if body["status"] != models.StatusDikembalikan || body["tanggal_kembali"] == nil {
+		t.Errorf("status = %v, tanggal_kembali = %v, ingin dikembalikan dengan tanggal", body["status"], body["tanggal_kembali"])
+	}
+	cekBarang(2, 2, 1)
+
+	akhir, err := peminjamanRepo.FindByID(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(akhir.Pengembalian) != 4 {
+		t.Fatalf("jumlah catatan pengembalian = %d, ingin 4", len(akhir.Pengembalian))
+	}
+	for _, k := range akhir.Pengembalian {
+		if k.DiterimaOleh != petugas || k.Catatan != "uji" {
+			t.Errorf("pengembalian %+v, ingin diterima %s dengan catatan", k, petugas.Hex())
+		}
+	}
+
+	if status, body := kembalikan(item("1", models.KondisiBaik)); status != 409 {
+		t.Errorf("peminjaman selesai: status = %d, body = %v, ingin 409", status, body)
+	}
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/validators"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cekAkunUnik memastikan username (case-insensitive) dan email belum dipakai
+// user lain selain kecuali. Mengembalikan pesan error jika sudah dipakai.
+func cekAkunUnik(ctx context.Context, username, email string, kecuali primitive.ObjectID) (string, error) {
+	if user, err := userRepo.FindByUsername(ctx, username); err == nil && user.ID != kecuali {
+		return "Username sudah dipakai", nil
+	} else if err != nil && err != repository.ErrNotFound {
+		return "", err
+	}
+	if user, err := userRepo.FindByEmail(ctx, email); err == nil && user.ID != kecuali {
+		return "Email sudah terdaftar", nil
+	} else if err != nil && err != repository.ErrNotFound {
+		return "", err
+	}
+	return "", nil
+}
+
+// pesanAkunDipakai mengembalikan pesan untuk error index unik username/email.
+// cekAkunUnik tidak menutup kemungkinan dua request bersamaan lolos pengecekan,
+// jadi error dari repository tetap harus dipetakan ke 400.
+func pesanAkunDipakai(err error) string {
+	switch {
+	case errors.Is(err, repository.ErrUsernameDipakai):
+		return "Username sudah dipakai"
+	case errors.Is(err, repository.ErrEmailDipakai):
+		return "Email sudah terdaftar"
+	}
+	return ""
+}
+
+// UpdateProfile godoc
+// @Summary Update profile sendiri
+// @Description Mengubah username, email, nama lengkap dan telepon user yang sedang login. Field yang tidak dikirim tidak diubah. Mengganti email wajib menyertakan password_sekarang dan email baru harus diverifikasi ulang.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body models.UpdateProfileRequest true "Data profile"
+// @Success 200 {object} map[string]interface{} "Profile berhasil diperbarui"
+// @Failure 400 {object} map[string]interface{} "Bad request, password salah, atau username/email sudah dipakai"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/profile [put]
+func UpdateProfile(c *fiber.Ctx) error {
+	var body models.UpdateProfileRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if err := validators.ValidateProfil(body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var user *models.User
+	emailBerubah := false
+	// User dibaca di dalam transaksi agar pengecekan password dan perubahannya
+	// memakai data terbaru
+	err := txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		sebelum, err := userRepo.FindByID(ctx, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		username, email := sebelum.Username, sebelum.Email
+		if body.Username != nil {
+			username = strings.TrimSpace(*body.Username)
+		}
+		if body.Email != nil {
+			email = strings.TrimSpace(*body.Email)
+		}
+
+		// Mengganti email butuh password agar access token yang bocor tidak bisa
+		// dipakai mengambil alih akun lewat lupa password
+		emailBerubah = email != sebelum.Email
+		if emailBerubah {
+			if body.PasswordSekarang == "" {
+				return fiber.NewError(400, "password_sekarang wajib diisi untuk mengganti email")
+			}
+			if bcrypt.CompareHashAndPassword([]byte(sebelum.Password), []byte(body.PasswordSekarang)) != nil {
+				return fiber.NewError(400, "Password saat ini salah")
+			}
+		}
+
+		pesan, err := cekAkunUnik(ctx, username, email, sebelum.ID)
+		if err != nil {
+			return err
+		}
+		if pesan != "" {
+			return fiber.NewError(400, pesan)
+		}
+
+		ubah := repository.UserUpdate{Username: &username, Email: &email, Telepon: body.Telepon}
+		if body.NamaLengkap != nil {
+			namaLengkap := strings.TrimSpace(*body.NamaLengkap)
+			ubah.NamaLengkap = &namaLengkap
+		}
+		if emailBerubah {
+			ubah.BelumVerifikasi = &emailBerubah
+		}
+		user, err = userRepo.Update(ctx, sebelum.ID, ubah)
+		if err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditUbahProfil, models.ResourceUser, user.ID, sebelum, user, nil)
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	if emailBerubah {
+		if err := kirimVerifikasiEmail(context.Background(), user); err != nil {
+			log.Printf("Gagal mengirim email verifikasi ke %s: %v", user.Email, err)
+		}
+	}
+
+	user.Password = ""
+	return c.JSON(fiber.Map{
+		"message": "Profile berhasil diperbarui",
+		"data":    user,
+	})
+}
+
+// ChangePassword godoc
+// @Summary Ganti password sendiri
+// @Description Mengganti password user yang sedang login dengan memasukkan password lama. Semua sesi lain dicabut; sesi saat ini tetap berlaku.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param password body models.ChangePasswordRequest true "Password lama dan baru"
+// @Success 200 {object} map[string]interface{} "Password berhasil diganti"
+// @Failure 400 {object} map[string]interface{} "Password lama salah atau password baru tidak valid"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 429 {object} map[string]interface{} "Terlalu banyak percobaan gagal"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/password [put]
+func ChangePassword(c *fiber.Ctx) error {
+	var body models.ChangePasswordRequest
+	if err := c.BodyParser(&body); err != nil || body.PasswordLama == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "password_lama wajib diisi"})
+	}
+	if err := validators.ValidatePassword(body.PasswordBaru); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if body.PasswordBaru == body.PasswordLama {
+		return c.Status(400).JSON(fiber.Map{"error": "Password baru harus berbeda dari password lama"})
+	}
+
+	ctx := context.Background()
+	user, err := userRepo.FindByID(ctx, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Password lama yang salah dibatasi sama seperti login gagal
+	tunggu, err := tungguLogin(ctx, kunciEmail(user.Email), kunciIPRequest(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if tunggu > 0 {
+		return tolakLogin(c, tunggu)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.PasswordLama)) != nil {
+		dikunci, err := catatLoginGagal(c, user.Email, user)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if dikunci > 0 {
+			return tolakLogin(c, dikunci)
+		}
+		return c.Status(400).JSON(fiber.Map{"error": "Password lama salah"})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.PasswordBaru), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengenkripsi password"})
+	}
+	password, harusGanti := string(hashedPassword), false
+	sessionID, _ := c.Locals("session_id").(primitive.ObjectID)
+	var jumlah int64
+	// Password, audit, pencabutan sesi lain dan pembatalan link reset disimpan
+	// bersama agar sesi milik pemegang password lama tidak tertinggal hidup
+	err = txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		sebelum, err := userRepo.FindByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		// Password sudah diganti request lain sejak dicocokkan di atas
+		if sebelum.Password != user.Password {
+			return fiber.NewError(400, "Password lama salah")
+		}
+
+		sesudah, err := userRepo.Update(ctx, user.ID, repository.UserUpdate{Password: &password, HarusGantiPassword: &harusGanti})
+		if err != nil {
+			return err
+		}
+		if err := catatPerubahan(ctx, c, models.AuditGantiPassword, models.ResourceUser, user.ID, sebelum, sesudah, nil); err != nil {
+			return err
+		}
+		if jumlah, err = sessionRepo.RevokeByUser(ctx, user.ID, &sessionID, models.SesiGantiPassword); err != nil {
+			return err
+		}
+		// Link reset password yang masih beredar tidak boleh dipakai lagi
+		return authTokenRepo.BatalkanMilikUser(ctx, user.ID, models.TokenResetPassword)
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":      "Password berhasil diganti",
+		"sesi_dicabut": jumlah,
+	})
+}
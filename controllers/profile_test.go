@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestGantiPasswordAtomik(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Password: string(hash), Role: models.RoleUser}
+	if err := repos.User.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	sesiLain := models.Session{ID: primitive.NewObjectID(), UserID: user.ID, RefreshTokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repos.Session.Create(ctx, &sesiLain); err != nil {
+		t.Fatal(err)
+	}
+
+	ganti := func() int {
+		app := fiber.New()
+		app.Put("/auth/password", func(c *fiber.Ctx) error {
+			c.Locals("user_id", user.ID.Hex())
+			c.Locals("session_id", primitive.NewObjectID())
+			return c.Next()
+		}, ChangePassword)
+		req := httptest.NewRequest("PUT", "/auth/password", strings.NewReader(`{"password_lama":"rahasia123","password_baru":"rahasiaBaru456"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	// Pencabutan sesi gagal: password dan audit ikut dibatalkan
+	gagal := *repos
+	gagal.Session = sessionGagal{repos.Session}
+	SetRepositories(&gagal)
+	if status := ganti(); status != 500 {
+		t.Fatalf("status = %d, ingin 500", status)
+	}
+	if tersimpan, _ := userRepo.FindByID(ctx, user.ID); tersimpan.Password != user.Password {
+		t.Error("password berubah walau sesi lain gagal dicabut")
+	}
+	if _, total, _ := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: models.AuditGantiPassword}, repository.ListOptions{}); total != 0 {
+		t.Errorf("audit log tercatat %d kali untuk perubahan yang dibatalkan", total)
+	}
+
+	SetRepositories(repos)
+	if status := ganti(); status != 200 {
+		t.Fatalf("status = %d, ingin 200", status)
+	}
+	if aktif, _ := sessionRepo.FindAktifByUser(ctx, user.ID); len(aktif) != 0 {
+		t.Errorf("sesi lain yang aktif = %d, ingin 0", len(aktif))
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"inventory-backend/repository"
+	"inventory-backend/services"
+)
+
+var (
+	txManager        repository.Transactor
+	barangRepo       repository.BarangRepository
+	kategoriRepo     repository.KategoriRepository
+	peminjamanRepo   repository.PeminjamanRepository
+	userRepo         repository.UserRepository
+	mutasiRepo       repository.MutasiStokRepository
+	sessionRepo      repository.SessionRepository
+	roleRepo         repository.RoleRepository
+	auditRepo        repository.AuditLogRepository
+	authTokenRepo    repository.AuthTokenRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	apiKeyRepo       repository.APIKeyRepository
+	loginHistoryRepo repository.LoginHistoryRepository
+)
+
+// SetRepositories menghubungkan controller ke backend penyimpanan (MongoDB atau in-memory)
+func SetRepositories(repos *repository.Repositories) {
+	txManager = repos.Tx
+	barangRepo = repos.Barang
+	kategoriRepo = repos.Kategori
+	peminjamanRepo = repos.Peminjaman
+	userRepo = repos.User
+	mutasiRepo = repos.MutasiStok
+	sessionRepo = repos.Session
+	roleRepo = repos.Role
+	auditRepo = repos.AuditLog
+	authTokenRepo = repos.AuthToken
+	loginAttemptRepo = repos.LoginAttempt
+	apiKeyRepo = repos.APIKey
+	loginHistoryRepo = repos.LoginHistory
+}
+
+// tokenService menandatangani access token, dipakai bersama middleware JWT
+var tokenService *services.TokenService
+
+// SetTokenService mengatur token service yang dipakai untuk login dan refresh
+func SetTokenService(tokens *services.TokenService) {
+	tokenService = tokens
+}
+
+// mailer mengirim email reset password dan verifikasi email
+var mailer services.Mailer
+
+// SetMailer mengatur mailer yang dipakai controller
+func SetMailer(m services.Mailer) {
+	mailer = m
+}
+
+// oidcProvider dipakai untuk login SSO; nil jika SSO tidak dikonfigurasi
+var oidcProvider *services.OIDCProvider
+
+// SetOIDCProvider mengatur identity provider untuk login SSO
+func SetOIDCProvider(p *services.OIDCProvider) {
+	oidcProvider = p
+}
@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/validators"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RoleRequest adalah body untuk membuat atau mengubah role
+type RoleRequest struct {
+	Nama        string   `json:"nama"`
+	Deskripsi   string   `json:"deskripsi"`
+	Permissions []string `json:"permissions"`
+}
+
+// roleBawaan dibuat saat server start jika belum ada di database
+var roleBawaan = []models.Role{
+	{
+		Nama:      models.RoleAdmin,
+		Deskripsi: "Administrator dengan semua permission",
+		Sistem:    true,
+	},
+	{
+		Nama:        models.RoleUser,
+		Deskripsi:   "Pengguna yang bisa mengajukan peminjaman untuk dirinya sendiri",
+		Permissions: []string{models.PermPeminjamanCreate},
+		Sistem:      true,
+	},
+	{
+		Nama:      models.RolePetugasGudang,
+		Deskripsi: "Petugas gudang yang mengelola barang, stok dan pengembalian",
+		Permissions: []string{
+			models.PermBarangWrite,
+			models.PermKategoriWrite,
+			models.PermStokRead,
+			models.PermStokWrite,
+			models.PermPeminjamanCreate,
+			models.PermPeminjamanReadAll,
+			models.PermPeminjamanReturn,
+		},
+	},
+	{
+		Nama:      models.RoleApprover,
+		Deskripsi: "Menyetujui dan menolak peminjaman",
+		Permissions: []string{
+			models.PermPeminjamanCreate,
+			models.PermPeminjamanReadAll,
+			models.PermPeminjamanApprove,
+			models.PermLaporanRead,
+		},
+	},
+	{
+		Nama:      models.RoleViewer,
+		Deskripsi: "Hanya bisa melihat data dan laporan",
+		Permissions: []string{
+			models.PermPeminjamanReadAll,
+			models.PermStokRead,
+			models.PermLaporanRead,
+		},
+	},
+}
+
+// daftarPermission mengembalikan semua permission yang dikenal secara terurut
+func daftarPermission() []string {
+	permissions := make([]string, 0, len(models.SemuaPermission))
+	for permission := range models.SemuaPermission {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// SeedRoles membuat role bawaan yang belum ada. Role admin selalu diperbarui
+// agar memiliki semua permission, termasuk permission yang baru ditambahkan.
+func SeedRoles(ctx context.Context) error {
+	for _, bawaan := range roleBawaan {
+		if bawaan.Nama == models.RoleAdmin {
+			bawaan.Permissions = daftarPermission()
+		}
+
+		role, err := roleRepo.FindByNama(ctx, bawaan.Nama)
+		if err == repository.ErrNotFound {
+			bawaan.ID = primitive.NewObjectID()
+			bawaan.CreatedAt = time.Now()
+			bawaan.UpdatedAt = time.Now()
+			if err := roleRepo.Create(ctx, &bawaan); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if role.Nama == models.RoleAdmin && !slices.Equal(role.Permissions, bawaan.Permissions) {
+			role.Permissions = bawaan.Permissions
+			role.UpdatedAt = time.Now()
+			if err := roleRepo.Update(ctx, role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cekPemberianRole memastikan role ada dan semua permission-nya juga dimiliki
+// pemanggil, sehingga pemegang users:manage tidak bisa memberikan role yang
+// lebih tinggi dari miliknya sendiri, termasuk kepada dirinya sendiri. Dipanggil
+// di dalam transaksi yang menyimpan role ke user; KunciRole membuat transaksi
+// itu bentrok dengan DeleteRole untuk role yang sama.
+func cekPemberianRole(ctx context.Context, c *fiber.Ctx, nama string) error {
+	role, err := roleRepo.FindByNama(ctx, nama)
+	if err == repository.ErrNotFound {
+		return fiber.NewError(400, "Role '"+nama+"' tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+	if permission := permissionKurang(c, role.Permissions); permission != "" {
+		return fiber.NewError(403, "Tidak bisa memberikan role '"+nama+"' karena Anda tidak memiliki permission "+permission)
+	}
+	return roleRepo.KunciRole(ctx, nama)
+}
+
+// cekPengelolaanUser memastikan pemanggil memiliki semua permission role user
+// yang akan dikelola, sehingga pemegang users:manage tidak bisa mereset,
+// menonaktifkan, menghapus atau mencabut 2FA akun yang lebih tinggi darinya.
+// Role yang sudah tidak terdaftar tidak memberikan permission apa pun.
+func cekPengelolaanUser(ctx context.Context, c *fiber.Ctx, user *models.User) (string, error) {
+	role, err := roleRepo.FindByNama(ctx, user.Role)
+	if err == repository.ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if permission := permissionKurang(c, role.Permissions); permission != "" {
+		return "Tidak bisa mengelola user dengan role '" + user.Role + "' karena Anda tidak memiliki permission " + permission, nil
+	}
+	return "", nil
+}
+
+// permissionKurang mengembalikan permission pertama yang tidak dimiliki
+// pemanggil, atau string kosong jika semuanya dimiliki
+func permissionKurang(c *fiber.Ctx, permissions []string) string {
+	for _, permission := range permissions {
+		if !middlewares.HasPermission(c, permission) {
+			return permission
+		}
+	}
+	return ""
+}
+
+// GetAllPermissions godoc
+// @Summary Get all permissions
+// @Description Mengambil semua permission yang dikenal beserta keterangannya
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "Daftar permission"
+// @Router /roles/permissions [get]
+func GetAllPermissions(c *fiber.Ctx) error {
+	return c.JSON(models.SemuaPermission)
+}
+
+// GetAllRoles godoc
+// @Summary Get all roles
+// @Description Mengambil semua role beserta permission-nya
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Role "Daftar role"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /roles [get]
+func GetAllRoles(c *fiber.Ctx) error {
+	roles, err := roleRepo.FindAll(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data role"})
+	}
+	return c.JSON(roles)
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Description Membuat role baru dengan kumpulan permission. Nama role tidak bisa diubah setelah dibuat. Permission hanya boleh berisi permission yang juga dimiliki pemanggil.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role body RoleRequest true "Data role baru"
+// @Success 201 {object} models.Role "Role berhasil dibuat"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Permission melebihi permission pemanggil"
+// @Failure 409 {object} map[string]interface{} "Nama role sudah dipakai"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /roles [post]
+func CreateRole(c *fiber.Ctx) error {
+	var body RoleRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if err := validators.ValidateRole(body.Nama, body.Permissions); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if permission := permissionKurang(c, body.Permissions); permission != "" {
+		return c.Status(403).JSON(fiber.Map{"error": "Tidak bisa memberikan permission " + permission + " yang tidak Anda miliki"})
+	}
+
+	role := models.Role{
+		ID:          primitive.NewObjectID(),
+		Nama:        body.Nama,
+		Deskripsi:   body.Deskripsi,
+		Permissions: normalisasiPermissions(body.Permissions),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	err := txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if _, err := roleRepo.FindByNama(ctx, role.Nama); err == nil {
+			return fiber.NewError(409, "Nama role sudah dipakai")
+		} else if err != repository.ErrNotFound {
+			return err
+		}
+		if err := roleRepo.Create(ctx, &role); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditBuatRole, models.ResourceRole, role.ID, nil, role, nil)
+	})
+	if err != nil {
+		return respondRoleError(c, err)
+	}
+	return c.Status(201).JSON(role)
+}
+
+// UpdateRole godoc
+// @Summary Update role
+// @Description Mengubah deskripsi dan permission role. Permission role admin tidak bisa diubah. Pemanggil harus memiliki semua permission lama dan permission baru role tersebut.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param role body RoleRequest true "Deskripsi dan permission baru (nama diabaikan)"
+// @Success 200 {object} models.Role "Role berhasil diubah"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Permission role melebihi permission pemanggil"
+// @Failure 404 {object} map[string]interface{} "Role tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Role admin tidak bisa diubah"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /roles/{id} [put]
+func UpdateRole(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	var body RoleRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if err := validators.ValidatePermissions(body.Permissions); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if permission := permissionKurang(c, body.Permissions); permission != "" {
+		return c.Status(403).JSON(fiber.Map{"error": "Tidak bisa memberikan permission " + permission + " yang tidak Anda miliki"})
+	}
+
+	var role *models.Role
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		var err error
+		role, err = roleRepo.FindByID(ctx, id)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(404, "Role tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Admin selalu memiliki semua permission agar tidak ada yang terkunci
+		if role.Nama == models.RoleAdmin {
+			return fiber.NewError(409, "Permission role admin tidak bisa diubah")
+		}
+		// Role yang lebih tinggi dari pemanggil tidak boleh diubah, termasuk dikurangi
+		if permission := permissionKurang(c, role.Permissions); permission != "" {
+			return fiber.NewError(403, "Tidak bisa mengubah role '"+role.Nama+"' karena Anda tidak memiliki permission "+permission)
+		}
+
+		sebelum, err := snapshot(role)
+		if err != nil {
+			return err
+		}
+		role.Deskripsi = body.Deskripsi
+		role.Permissions = normalisasiPermissions(body.Permissions)
+		role.UpdatedAt = time.Now()
+		if err := roleRepo.Update(ctx, role); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditUbahRole, models.ResourceRole, role.ID, sebelum, role, nil)
+	})
+	if err != nil {
+		return respondRoleError(c, err)
+	}
+	return c.JSON(role)
+}
+
+// DeleteRole godoc
+// @Summary Delete role
+// @Description Menghapus role. Role bawaan sistem, role yang masih dipakai user dan role dengan permission yang tidak dimiliki pemanggil tidak bisa dihapus.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]interface{} "Role berhasil dihapus"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 403 {object} map[string]interface{} "Permission role melebihi permission pemanggil"
+// @Failure 404 {object} map[string]interface{} "Role tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Role sistem atau masih dipakai"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /roles/{id} [delete]
+func DeleteRole(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	var jumlahUser int64
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		role, err := roleRepo.FindByID(ctx, id)
+		if err == repository.ErrNotFound {
+			return fiber.NewError(404, "Role tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+		if role.Sistem {
+			return fiber.NewError(409, "Role bawaan sistem tidak bisa dihapus")
+		}
+		// Sama seperti mengubah, role yang lebih tinggi dari pemanggil tidak boleh dihapus
+		if permission := permissionKurang(c, role.Permissions); permission != "" {
+			return fiber.NewError(403, "Tidak bisa menghapus role '"+role.Nama+"' karena Anda tidak memiliki permission "+permission)
+		}
+
+		// Penanda role ditulis sebelum menghitung user agar pemberian role yang
+		// berjalan bersamaan tidak lolos dari hitungan
+		if err := roleRepo.KunciRole(ctx, role.Nama); err != nil {
+			return err
+		}
+		_, jumlahUser, err = userRepo.FindAll(ctx, repository.UserFilter{Role: role.Nama}, repository.ListOptions{Limit: 1})
+		if err != nil {
+			return err
+		}
+		if jumlahUser > 0 {
+			return fiber.NewError(409, "Role masih dipakai user")
+		}
+
+		if err := roleRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditHapusRole, models.ResourceRole, id, role, nil, nil)
+	})
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && fiberErr.Code == 409 && jumlahUser > 0 {
+			return c.Status(409).JSON(fiber.Map{
+				"error":       fiberErr.Message,
+				"jumlah_user": jumlahUser,
+			})
+		}
+		return respondRoleError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Role berhasil dihapus"})
+}
+
+// respondRoleError memetakan error dari transaksi role ke response HTTP
+func respondRoleError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": fiberErr.Message})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// normalisasiPermissions membuang duplikat dan mengurutkan permission
+func normalisasiPermissions(permissions []string) []string {
+	hasil := append([]string{}, permissions...)
+	sort.Strings(hasil)
+	return slices.Compact(hasil)
+}
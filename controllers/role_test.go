@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRoleTidakBisaMelebihiPermissionPemanggil(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	if err := SeedRoles(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("permissions", []string{models.PermRolesManage, models.PermStokRead})
+		return c.Next()
+	})
+	app.Post("/roles", CreateRole)
+	app.Put("/roles/:id", UpdateRole)
+
+	kirim := func(method, path, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hasil map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&hasil)
+		return resp.StatusCode, hasil
+	}
+
+	if status, body := kirim("POST", "/roles", `{"nama":"naik","permissions":["stok:read","users:manage"]}`); status != 403 {
+		t.Errorf("buat role dengan users:manage: status = %d, ingin 403 (%v)", status, body)
+	}
+	status, body := kirim("POST", "/roles", `{"nama":"pemantau","permissions":["stok:read"]}`)
+	if status != 201 {
+		t.Fatalf("buat role dengan stok:read: status = %d, ingin 201 (%v)", status, body)
+	}
+	id := body["id"].(string)
+
+	if status, body := kirim("PUT", "/roles/"+id, `{"permissions":["stok:read","laporan:pii"]}`); status != 403 {
+		t.Errorf("tambah laporan:pii: status = %d, ingin 403 (%v)", status, body)
+	}
+	if status, body := kirim("PUT", "/roles/"+id, `{"deskripsi":"Memantau stok","permissions":["stok:read"]}`); status != 200 {
+		t.Errorf("ubah deskripsi: status = %d, ingin 200 (%v)", status, body)
+	}
+
+	gudang, err := roleRepo.FindByNama(ctx, models.RolePetugasGudang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, body := kirim("PUT", "/roles/"+gudang.ID.Hex(), `{"permissions":["stok:read"]}`); status != 403 {
+		t.Errorf("ubah role yang lebih tinggi: status = %d, ingin 403 (%v)", status, body)
+	}
+	if tersimpan, _ := roleRepo.FindByID(ctx, gudang.ID); len(tersimpan.Permissions) != len(gudang.Permissions) {
+		t.Errorf("permission petugas_gudang berubah: %v", tersimpan.Permissions)
+	}
+}
+
+func TestHapusRole(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	SetRepositories(repos)
+	ctx := context.Background()
+
+	buatRole := func(nama string, permissions ...string) string {
+		role := models.Role{ID: primitive.NewObjectID(), Nama: nama, Permissions: permissions}
+		if err := roleRepo.Create(ctx, &role); err != nil {
+			t.Fatal(err)
+		}
+		return role.ID.Hex()
+	}
+	tinggi := buatRole("tinggi", models.PermStokRead, models.PermUsersManage)
+	dipakai := buatRole("dipakai", models.PermStokRead)
+	bebas := buatRole("bebas", models.PermStokRead)
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: "dipakai"}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermRolesManage, models.PermStokRead)
+	app.Delete("/roles/:id", DeleteRole)
+
+	if status, body := kirimJSON(t, app, "DELETE", "/roles/"+tinggi, ""); status != 403 {
+		t.Errorf("role lebih tinggi: status = %d, body = %v, ingin 403", status, body)
+	}
+	if status, body := kirimJSON(t, app, "DELETE", "/roles/"+dipakai, ""); status != 409 || body["jumlah_user"] != float64(1) {
+		t.Errorf("role dipakai: status = %d, body = %v, ingin 409 dengan jumlah_user 1", status, body)
+	}
+
+	// Audit gagal: role tidak boleh ikut terhapus
+	gagal := *repos
+	gagal.AuditLog = auditGagal{repos.AuditLog}
+	SetRepositories(&gagal)
+	if status, _ := kirimJSON(t, app, "DELETE", "/roles/"+bebas, ""); status != 500 {
+		t.Errorf("audit gagal: status = %d, ingin 500", status)
+	}
+	if _, err := roleRepo.FindByNama(ctx, "bebas"); err != nil {
+		t.Errorf("role terhapus walau audit gagal: %v", err)
+	}
+
+	SetRepositories(repos)
+	if status, body := kirimJSON(t, app, "DELETE", "/roles/"+bebas, ""); status != 200 {
+		t.Errorf("role bebas: status = %d, body = %v, ingin 200", status, body)
+	}
+	if _, err := roleRepo.FindByNama(ctx, "bebas"); err != repository.ErrNotFound {
+		t.Errorf("role bebas masih ada: %v", err)
+	}
+	for _, nama := range []string{"tinggi", "dipakai"} {
+		if _, err := roleRepo.FindByNama(ctx, nama); err != nil {
+			t.Errorf("role %s ikut terhapus: %v", nama, err)
+		}
+	}
+}
+
+// TestHapusRoleSaatDiberikanParalel menghapus role bersamaan dengan
+// memberikannya ke user. Role tidak boleh terhapus sementara ada user yang
+// memakainya.
+func TestHapusRoleSaatDiberikanParalel(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	role := models.Role{ID: primitive.NewObjectID(), Nama: "pemantau", Permissions: []string{models.PermStokRead}}
+	if err := roleRepo.Create(ctx, &role); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermRolesManage, models.PermUsersManage, models.PermStokRead, models.PermPeminjamanCreate)
+	app.Delete("/roles/:id", DeleteRole)
+	app.Put("/users/:id/role", UpdateRoleUser)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		kirimJSON(t, app, "DELETE", "/roles/"+role.ID.Hex(), "")
+	}()
+	go func() {
+		defer wg.Done()
+		kirimJSON(t, app, "PUT", "/users/"+user.ID.Hex()+"/role", `{"role":"pemantau"}`)
+	}()
+	wg.Wait()
+
+	akhir, err := userRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := roleRepo.FindByNama(ctx, "pemantau"); err == repository.ErrNotFound && akhir.Role == "pemantau" {
+		t.Error("role terhapus padahal sudah diberikan ke user")
+	}
+}
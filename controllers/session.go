@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"inventory-backend/config"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshRequest adalah body untuk memperbarui access token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// buatRefreshToken membuat refresh token acak dengan format <session_id>.<rahasia>
+// beserta hash yang disimpan di sesi
+func buatRefreshToken(sessionID primitive.ObjectID) (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	token := sessionID.Hex() + "." + base64.RawURLEncoding.EncodeToString(secret)
+	return token, hashToken(token), nil
+}
+
+// hashToken menghitung hash SHA-256 token; token acak cukup panjang sehingga
+// tidak perlu bcrypt
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionIDDariRefreshToken mengambil ID sesi dari bagian depan refresh token
+func sessionIDDariRefreshToken(token string) (primitive.ObjectID, error) {
+	sid, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return primitive.NilObjectID, errors.New("format refresh token tidak valid")
+	}
+	return primitive.ObjectIDFromHex(sid)
+}
+
+// buatSesi membuat sesi login baru, mencatatnya di riwayat login, dan
+// mengembalikan access token serta refresh token. metode adalah cara user
+// login (MetodePassword atau MetodeSSO). ctx harus ctx transaksi jika dipanggil
+// di dalam WithTransaction.
+func buatSesi(ctx context.Context, c *fiber.Ctx, user *models.User, metode string) (*models.LoginResponse, error) {
+	now := time.Now()
+	session := models.Session{
+		ID:         primitive.NewObjectID(),
+		UserID:     user.ID,
+		UserAgent:  strings.Clone(c.Get("User-Agent")),
+		IP:         strings.Clone(c.IP()),
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(config.RefreshTokenTTL()),
+		// buatSesi hanya dipanggil untuk user dengan 2FA setelah kodenya diverifikasi
+		Dengan2FA: user.TOTPAktif,
+		Metode:    metode,
+	}
+
+	refreshToken, hash, err := buatRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshTokenHash = hash
+
+	if err := sessionRepo.Create(ctx, &session); err != nil {
+		return nil, err
+	}
+	if err := catatRiwayat(ctx, c, riwayatSesi(models.RiwayatLogin, &session)); err != nil {
+		return nil, err
+	}
+
+	return responseLogin(user, session.ID, refreshToken)
+}
+
+// responseLogin membuat access token untuk sesi dan menyusun response login
+func responseLogin(user *models.User, sessionID primitive.ObjectID, refreshToken string) (*models.LoginResponse, error) {
+	token, err := generateJWTToken(user.ID, user.Email, user.Role, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.AccessTokenTTL().Seconds()),
+	}
+	response.User.ID = user.ID
+	response.User.Username = user.Username
+	response.User.Email = user.Email
+	response.User.Role = user.Role
+	response.User.HarusGantiPassword = user.HarusGantiPassword
+	response.User.BelumVerifikasi = user.BelumVerifikasi
+	response.User.TOTPAktif = user.TOTPAktif
+	response.User.Wajib2FA = config.Wajib2FA(user.Role) && !user.TOTPAktif
+	return response, nil
+}
+
+// RefreshToken godoc
+// @Summary Refresh access token
+// @Description Menukar refresh token dengan access token dan refresh token baru. Setiap refresh token hanya bisa dipakai sekali; refresh token lama yang dipakai ulang dianggap bocor sehingga seluruh sesinya dicabut.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param refresh body RefreshRequest true "Refresh token"
+// @Success 200 {object} map[string]interface{} "Token berhasil diperbarui"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Refresh token tidak valid atau sesi sudah berakhir"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/refresh [post]
+func RefreshToken(c *fiber.Ctx) error {
+	var body RefreshRequest
+	if err := c.BodyParser(&body); err != nil || body.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "refresh_token wajib diisi"})
+	}
+
+	sessionID, err := sessionIDDariRefreshToken(body.RefreshToken)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Refresh token tidak valid"})
+	}
+
+	ctx := context.Background()
+	session, err := sessionRepo.FindByID(ctx, sessionID)
+	if err == repository.ErrNotFound {
+		return c.Status(401).JSON(fiber.Map{"error": "Refresh token tidak valid"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	hash := hashToken(body.RefreshToken)
+	if hash != session.RefreshTokenHash {
+		// Token lama dari family ini dipakai lagi: kemungkinan token dicuri,
+		// jadi seluruh family dicabut
+		for _, lama := range session.RefreshTokenLama {
+			if lama == hash {
+				return tolakTokenDipakaiUlang(c, session)
+			}
+		}
+		return c.Status(401).JSON(fiber.Map{"error": "Refresh token tidak valid"})
+	}
+	if !session.Aktif(time.Now()) {
+		return c.Status(401).JSON(fiber.Map{"error": "Sesi sudah berakhir, silakan login kembali"})
+	}
+
+	user, err := userRepo.FindByID(ctx, session.UserID)
+	if err != nil || user.Nonaktif {
+		return c.Status(401).JSON(fiber.Map{"error": "Akun tidak aktif"})
+	}
+
+	refreshToken, hashBaru, err := buatRefreshToken(session.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+	}
+	err = sessionRepo.Rotate(ctx, session.ID, hash, hashBaru, time.Now().Add(config.RefreshTokenTTL()))
+	if err == repository.ErrNotFound {
+		// Request lain sudah lebih dulu memakai token yang sama
+		return tolakTokenDipakaiUlang(c, session)
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := catatRiwayat(context.Background(), c, riwayatSesi(models.RiwayatRefresh, session)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	response, err := responseLogin(user, session.ID, refreshToken)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Token berhasil diperbarui",
+		"data":    response,
+	})
+}
+
+// tolakTokenDipakaiUlang mencabut sesi yang refresh tokennya dipakai ulang
+func tolakTokenDipakaiUlang(c *fiber.Ctx, session *models.Session) error {
+	if err := sessionRepo.Revoke(context.Background(), session.ID, models.SesiTokenDipakai); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := catatRiwayat(context.Background(), c, riwayatSesi(models.RiwayatRefreshDitolak, session)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(401).JSON(fiber.Map{"error": "Refresh token sudah pernah dipakai, sesi dicabut. Silakan login kembali"})
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Mencabut sesi yang sedang dipakai sehingga access token dan refresh token sesi ini tidak berlaku lagi
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Logout berhasil"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/logout [post]
+func Logout(c *fiber.Ctx) error {
+	sessionID, _ := c.Locals("session_id").(primitive.ObjectID)
+	if err := sessionRepo.Revoke(context.Background(), sessionID, models.SesiLogout); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	userID := currentUserID(c)
+	if err := catatRiwayat(context.Background(), c, models.LoginHistory{
+		Jenis:     models.RiwayatLogout,
+		UserID:    &userID,
+		SessionID: &sessionID,
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Logout berhasil"})
+}
+
+// LogoutAll godoc
+// @Summary Logout dari semua perangkat
+// @Description Mencabut semua sesi milik user yang sedang login, termasuk sesi saat ini
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Semua sesi berhasil dicabut"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/logout-all [post]
+func LogoutAll(c *fiber.Ctx) error {
+	userID := currentUserID(c)
+	jumlah, err := sessionRepo.RevokeByUser(context.Background(), userID, nil, models.SesiLogoutSemua)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	sessionID, _ := c.Locals("session_id").(primitive.ObjectID)
+	if err := catatRiwayat(context.Background(), c, models.LoginHistory{
+		Jenis:      models.RiwayatLogoutSemua,
+		UserID:     &userID,
+		SessionID:  &sessionID,
+		Keterangan: strconv.FormatInt(jumlah, 10) + " sesi dicabut",
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message":     "Semua sesi berhasil dicabut",
+		"jumlah_sesi": jumlah,
+	})
+}
+
+// GetJWKS mengembalikan public key penandatangan access token dalam format JWKS
+// (RFC 7517) di /.well-known/jwks.json, agar service lain bisa memverifikasi
+// token tanpa berbagi secret. Kunci HS256 tidak ikut dipublikasikan.
+func GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(fiber.Map{"keys": tokenService.JWKS()})
+}
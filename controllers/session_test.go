@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"inventory-backend/validators"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRotasiMembatasiRefreshTokenLama(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+
+	session := models.Session{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), RefreshTokenHash: "hash-0"}
+	if err := sessionRepo.Create(ctx, &session); err != nil {
+		t.Fatal(err)
+	}
+	const rotasi = models.MaksRefreshTokenLama + 5
+	for i := range rotasi {
+		if err := sessionRepo.Rotate(ctx, session.ID, fmt.Sprintf("hash-%d", i), fmt.Sprintf("hash-%d", i+1), time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tersimpan, err := sessionRepo.FindByID(ctx, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lama := tersimpan.RefreshTokenLama
+	if len(lama) != models.MaksRefreshTokenLama {
+		t.Fatalf("jumlah hash lama = %d, ingin %d", len(lama), models.MaksRefreshTokenLama)
+	}
+	// Yang disimpan adalah hash terbaru, urut dari yang paling lama
+	if awal, akhir := lama[0], lama[len(lama)-1]; awal != "hash-5" || akhir != fmt.Sprintf("hash-%d", rotasi-1) {
+		t.Errorf("hash lama = [%s ... %s], ingin [hash-5 ... hash-%d]", awal, akhir, rotasi-1)
+	}
+}
+
+// TestSesiDanRiwayatLogin menjalankan login, refresh, pencabutan sesi dan
+// logout lewat JWTMiddleware, lalu memastikan semuanya tercatat di riwayat
+// login user beserta user agent-nya
+func TestSesiDanRiwayatLogin(t *testing.T) {
+	t.Setenv("JWT_SECRET", "rahasia-uji-sesi-yang-cukup-panjang-32")
+	tokens, err := services.NewTokenServiceFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTokenService(tokens)
+	middlewares.SetTokenService(tokens)
+	repos := repository.NewMemoryRepositories()
+	SetRepositories(repos)
+	middlewares.SetRepositories(repos)
+	ctx := context.Background()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Password: string(hash), Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Post("/auth/login", validators.ValidateLogin, Login)
+	app.Post("/auth/refresh", RefreshToken)
+	app.Post("/auth/logout", middlewares.JWTMiddleware, middlewares.RequireSesi, Logout)
+	app.Get("/auth/sessions", middlewares.JWTMiddleware, middlewares.RequireSesi, GetSessions)
+	app.Delete("/auth/sessions/:id", middlewares.JWTMiddleware, middlewares.RequireSesi, RevokeSession)
+	kirim := func(method, path, token, userAgent, body string) (int, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hasil map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&hasil)
+		return resp.StatusCode, hasil
+	}
+	login := func(userAgent string) (token, refresh string) {
+		t.Helper()
+		status, body := kirim("POST", "/auth/login", "", userAgent, `{"email":"budi@example.com","password":"rahasia123"}`)
+		if status != 200 {
+			t.Fatalf("login: status = %d, body = %v", status, body)
+		}
+		data := body["data"].(map[string]interface{})
+		return data["token"].(string), data["refresh_token"].(string)
+	}
+
+	if status, _ := kirim("POST", "/auth/login", "", "laptop", `{"email":"budi@example.com","password":"salah"}`); status != 401 {
+		t.Fatalf("login gagal: status = %d, ingin 401", status)
+	}
+	laptop, refreshLaptop := login("laptop")
+	hp, _ := login("hp")
+
+	status, body := kirim("POST", "/auth/refresh", "", "laptop", `{"refresh_token":"`+refreshLaptop+`"}`)
+	if status != 200 {
+		t.Fatalf("refresh: status = %d, body = %v", status, body)
+	}
+
+	status, body = kirim("GET", "/auth/sessions", laptop, "laptop", "")
+	if status != 200 {
+		t.Fatalf("daftar sesi: status = %d, body = %v", status, body)
+	}
+	sesi := body["data"].([]interface{})
+	if len(sesi) != 2 {
+		t.Fatalf("sesi aktif = %d, ingin 2", len(sesi))
+	}
+	var idHP string
+	for _, s := range sesi {
+		s := s.(map[string]interface{})
+		if s["user_agent"] == "hp" {
+			idHP = s["id"].(string)
+			if s["saat_ini"] != false {
+				t.Error("sesi hp ditandai saat_ini")
+			}
+		} else if s["saat_ini"] != true {
+			t.Error("sesi laptop tidak ditandai saat_ini")
+		}
+	}
+
+	// Sesi milik user lain tidak bisa dicabut
+	lain := models.Session{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), RefreshTokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := sessionRepo.Create(ctx, &lain); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := kirim("DELETE", "/auth/sessions/"+lain.ID.Hex(), laptop, "laptop", ""); status != 404 {
+		t.Errorf("cabut sesi user lain: status = %d, ingin 404", status)
+	}
+
+	if status, body := kirim("DELETE", "/auth/sessions/"+idHP, laptop, "laptop", ""); status != 200 {
+		t.Fatalf("cabut sesi hp: status = %d, body = %v", status, body)
+	}
+	if status, _ := kirim("GET", "/auth/sessions", hp, "hp", ""); status != 401 {
+		t.Errorf("token sesi yang dicabut: status = %d, ingin 401", status)
+	}
+
+	if status, body := kirim("POST", "/auth/logout", laptop, "laptop", ""); status != 200 {
+		t.Fatalf("logout: status = %d, body = %v", status, body)
+	}
+	if status, _ := kirim("GET", "/auth/sessions", laptop, "laptop", ""); status != 401 {
+		t.Errorf("token setelah logout: status = %d, ingin 401", status)
+	}
+
+	admin := appPengguna(primitive.NewObjectID(), models.PermUsersManage)
+	admin.Get("/users/:id/login-history", GetLoginHistoryUser)
+	status, body = kirimJSON(t, admin, "GET", "/users/"+user.ID.Hex()+"/login-history", "")
+	if status != 200 {
+		t.Fatalf("riwayat login: status = %d, body = %v", status, body)
+	}
+	ingin := []struct{ jenis, userAgent string }{
+		// Terbaru lebih dulu
+		{models.RiwayatLogout, "laptop"},
+		{models.RiwayatSesiDicabut, "laptop"},
+		{models.RiwayatRefresh, "laptop"},
+		{models.RiwayatLogin, "hp"},
+		{models.RiwayatLogin, "laptop"},
+		{models.RiwayatLoginGagal, "laptop"},
+	}
+	riwayat := body["data"].([]interface{})
+	if len(riwayat) != len(ingin) {
+		t.Fatalf("jumlah riwayat = %d, ingin %d: %v", len(riwayat), len(ingin), riwayat)
+	}
+	for i, r := range riwayat {
+		r := r.(map[string]interface{})
+		if r["jenis"] != ingin[i].jenis || r["user_agent"] != ingin[i].userAgent || r["ip"] == "" {
+			t.Errorf("riwayat[%d] = %v dari %v, ingin %s dari %s", i, r["jenis"], r["user_agent"], ingin[i].jenis, ingin[i].userAgent)
+		}
+	}
+}
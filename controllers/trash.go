@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis data yang bisa masuk tempat sampah
+const (
+	TrashBarang     = "barang"
+	TrashKategori   = "kategori"
+	TrashPeminjaman = "peminjaman"
+)
+
+var semuaJenisTrash = []string{TrashBarang, TrashKategori, TrashPeminjaman}
+
+// GetTrash godoc
+// @Summary Get tempat sampah
+// @Description Mengambil barang, kategori dan peminjaman yang sudah dihapus (soft delete), terbaru dihapus lebih dulu. Setiap jenis dipaginasi sendiri dengan page dan limit yang sama. Butuh permission trash:manage.
+// @Tags Trash
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param jenis query string false "Jenis data, boleh beberapa dipisah koma: barang, kategori, peminjaman (default semua)"
+// @Param sort query string false "Urutan: id atau deleted_at; awali dengan - untuk menurun (default -deleted_at)"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Success 200 {object} map[string]interface{} "Isi tempat sampah per jenis"
+// @Failure 400 {object} map[string]interface{} "Parameter tidak valid"
+// @Failure 403 {object} map[string]interface{} "Tidak memiliki permission trash:manage"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /trash [get]
+func GetTrash(c *fiber.Ctx) error {
+	jenis := semuaJenisTrash
+	if v := c.Query("jenis"); v != "" {
+		jenis = nil
+		for _, j := range strings.Split(v, ",") {
+			j = strings.TrimSpace(j)
+			if !slices.Contains(semuaJenisTrash, j) {
+				return c.Status(400).JSON(fiber.Map{"error": "jenis tidak dikenal: " + j})
+			}
+			jenis = append(jenis, j)
+		}
+	}
+
+	opts, err := parseListOptions(c, map[string]string{
+		"id":         "_id",
+		"deleted_at": "deleted_at",
+	})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if opts.After != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "tempat sampah hanya mendukung pagination dengan page"})
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	hasil := fiber.Map{}
+	for _, j := range jenis {
+		switch j {
+		case TrashBarang:
+			barang, total, err := barangRepo.FindTerhapus(ctx, now, opts)
+			if err != nil {
+				return errorList(c, err, "")
+			}
+			hasil[j] = paginated(c, barang, opts, total, func(b models.Barang) primitive.ObjectID { return b.ID })
+		case TrashKategori:
+			kategori, total, err := kategoriRepo.FindTerhapus(ctx, now, opts)
+			if err != nil {
+				return errorList(c, err, "")
+			}
+			hasil[j] = paginated(c, kategori, opts, total, func(k models.Kategori) primitive.ObjectID { return k.ID })
+		case TrashPeminjaman:
+			peminjaman, total, err := peminjamanRepo.FindTerhapus(ctx, now, opts)
+			if err != nil {
+				return errorList(c, err, "")
+			}
+			hasil[j] = paginated(c, peminjaman, opts, total, func(p models.Peminjaman) primitive.ObjectID { return p.ID })
+		}
+	}
+	return c.JSON(hasil)
+}
+
+// RestoreBarang godoc
+// @Summary Restore barang
+// @Description Mengeluarkan barang dari tempat sampah. Kategorinya harus dipulihkan lebih dulu jika ikut terhapus.
+// @Tags Barang
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Barang ID"
+// @Success 200 {object} map[string]interface{} "Barang berhasil dipulihkan"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 404 {object} map[string]interface{} "Barang tidak ada di tempat sampah"
+// @Failure 409 {object} map[string]interface{} "Kategori barang masih terhapus"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /barang/{id}/restore [post]
+func RestoreBarang(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := barangRepo.Restore(ctx, id); err == repository.ErrNotFound {
+			return fiber.NewError(404, "Barang tidak ada di tempat sampah")
+		} else if err != nil {
+			return err
+		}
+
+		barang, err := barangRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := kategoriRepo.FindByID(ctx, barang.KategoriID); err == repository.ErrNotFound {
+			return fiber.NewError(409, "Kategori barang ada di tempat sampah, pulihkan kategori terlebih dahulu")
+		} else if err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditPulihkanBarang, models.ResourceBarang, id, nil, barang, nil)
+	})
+	if err != nil {
+		return respondHapusError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Barang berhasil dipulihkan"})
+}
+
+// RestoreKategori godoc
+// @Summary Restore kategori
+// @Description Mengeluarkan kategori dari tempat sampah. Barang yang ikut terhapus karena cascade dipulihkan terpisah.
+// @Tags Kategori
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Kategori ID"
+// @Success 200 {object} map[string]interface{} "Kategori berhasil dipulihkan"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 404 {object} map[string]interface{} "Kategori tidak ada di tempat sampah"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /kategori/{id}/restore [post]
+func RestoreKategori(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := kategoriRepo.Restore(ctx, id); err == repository.ErrNotFound {
+			return fiber.NewError(404, "Kategori tidak ada di tempat sampah")
+		} else if err != nil {
+			return err
+		}
+
+		kategori, err := kategoriRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditPulihkanKategori, models.ResourceKategori, id, nil, kategori, nil)
+	})
+	if err != nil {
+		return respondHapusError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Kategori berhasil dipulihkan"})
+}
+
+// RestorePeminjaman godoc
+// @Summary Restore peminjaman
+// @Description Mengeluarkan peminjaman dari tempat sampah. Peminjaman berstatus disetujui atau dipinjam memesan stoknya lagi, sehingga ditolak jika stok tidak cukup. Semua barangnya harus sudah dipulihkan.
+// @Tags Peminjaman
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Peminjaman ID"
+// @Success 200 {object} map[string]interface{} "Peminjaman berhasil dipulihkan"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid atau stok tidak mencukupi"
+// @Failure 404 {object} map[string]interface{} "Peminjaman tidak ada di tempat sampah"
+// @Failure 409 {object} map[string]interface{} "Barang peminjaman masih terhapus"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /peminjaman/{id}/restore [post]
+func RestorePeminjaman(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := peminjamanRepo.Restore(ctx, id); err == repository.ErrNotFound {
+			return fiber.NewError(404, "Peminjaman tidak ada di tempat sampah")
+		} else if err != nil {
+			return err
+		}
+
+		peminjaman, err := peminjamanRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, item := range peminjaman.Items {
+			if _, err := barangRepo.FindByID(ctx, item.BarangID); err == repository.ErrNotFound {
+				return fiber.NewError(409, "Barang "+item.BarangID.Hex()+" ada di tempat sampah, pulihkan barang terlebih dahulu")
+			} else if err != nil {
+				return err
+			}
+		}
+
+		// Stok dilepas saat peminjaman dihapus, jadi dipesan lagi
+		if models.MenahanStok(peminjaman.Status) {
+			if err := pesanStokPeminjaman(ctx, peminjaman, models.MutasiPeminjamanPulih, currentUserID(c)); err != nil {
+				return err
+			}
+		}
+		return catatPerubahan(ctx, c, models.AuditPulihkanPeminjaman, models.ResourcePeminjaman, id, nil, peminjaman, nil)
+	})
+	if err != nil {
+		return respondStokError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Peminjaman berhasil dipulihkan"})
+}
+
+// BersihkanTrash menghapus permanen isi tempat sampah yang dihapus pada atau
+// sebelum batas dan mengembalikan jumlahnya per jenis. Peminjaman dibersihkan
+// lebih dulu, lalu barang yang tidak lagi dirujuk peminjaman mana pun, lalu
+// kategori yang tidak lagi dipakai barang mana pun; data yang masih dirujuk
+// tetap di tempat sampah agar tidak ada rujukan yang hilang.
+func BersihkanTrash(ctx context.Context, batas time.Time) (map[string]int, error) {
+	hasil := map[string]int{}
+
+	peminjaman, _, err := peminjamanRepo.FindTerhapus(ctx, batas, repository.ListOptions{})
+	if err != nil {
+		return hasil, err
+	}
+	for _, p := range peminjaman {
+		if err := hapusPermanen(peminjamanRepo.HapusPermanen(ctx, p.ID)); err != nil {
+			return hasil, err
+		}
+		hasil[TrashPeminjaman]++
+	}
+
+	barang, _, err := barangRepo.FindTerhapus(ctx, batas, repository.ListOptions{})
+	if err != nil {
+		return hasil, err
+	}
+	for _, b := range barang {
+		_, dirujuk, err := peminjamanRepo.FindAll(ctx,
+			repository.PeminjamanFilter{BarangID: []primitive.ObjectID{b.ID}, TermasukTerhapus: true},
+			repository.ListOptions{Limit: 1},
+		)
+		if err != nil {
+			return hasil, err
+		}
+		if dirujuk > 0 {
+			continue
+		}
+		if err := hapusPermanen(barangRepo.HapusPermanen(ctx, b.ID)); err != nil {
+			return hasil, err
+		}
+		hasil[TrashBarang]++
+	}
+
+	kategori, _, err := kategoriRepo.FindTerhapus(ctx, batas, repository.ListOptions{})
+	if err != nil {
+		return hasil, err
+	}
+	for _, k := range kategori {
+		_, dipakai, err := barangRepo.FindAll(ctx,
+			repository.BarangFilter{KategoriID: &k.ID, TermasukTerhapus: true},
+			repository.ListOptions{Limit: 1},
+		)
+		if err != nil {
+			return hasil, err
+		}
+		if dipakai > 0 {
+			continue
+		}
+		if err := hapusPermanen(kategoriRepo.HapusPermanen(ctx, k.ID)); err != nil {
+			return hasil, err
+		}
+		hasil[TrashKategori]++
+	}
+	return hasil, nil
+}
+
+// hapusPermanen mengabaikan ErrNotFound, yaitu data yang sudah dipulihkan atau
+// dibersihkan proses lain di antara pengambilan dan penghapusan
+func hapusPermanen(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// JalankanPembersihanTrash membersihkan tempat sampah dari data yang lebih lama
+// dari retensi setiap interval. Dijalankan di goroutine terpisah dan tidak pernah berhenti.
+func JalankanPembersihanTrash(retensi, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		hasil, err := BersihkanTrash(ctx, time.Now().Add(-retensi))
+		cancel()
+		if err != nil {
+			log.Printf("Warning: gagal membersihkan tempat sampah: %v", err)
+		} else if len(hasil) > 0 {
+			log.Printf("Tempat sampah dibersihkan: %d barang, %d kategori, %d peminjaman",
+				hasil[TrashBarang], hasil[TrashKategori], hasil[TrashPeminjaman])
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestTrashDanRestore memindahkan kategori, barang dan peminjaman ke tempat
+// sampah lalu memulihkannya. Data yang masih merujuk induk yang terhapus
+// tidak bisa dipulihkan sebelum induknya dipulihkan.
+func TestTrashDanRestore(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	kategori, barang := siapkanKategori(t, "Elektronik", "Proyektor", "Laptop")
+	proyektor, laptop := barang[0], barang[1]
+	p := models.Peminjaman{
+		ID:           primitive.NewObjectID(),
+		NamaPeminjam: "Budi",
+		Items:        []models.ItemPeminjaman{{BarangID: proyektor.ID, Jumlah: 1}},
+		Status:       models.StatusDikembalikan,
+	}
+	if err := peminjamanRepo.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	oleh := primitive.NewObjectID()
+	if err := peminjamanRepo.Delete(ctx, p.ID, oleh); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := barangRepo.DeleteByKategori(ctx, kategori.ID, oleh); err != nil {
+		t.Fatal(err)
+	}
+	if err := kategoriRepo.Delete(ctx, kategori.ID, oleh); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(oleh, models.PermTrashManage, models.PermBarangWrite, models.PermKategoriWrite, models.PermPeminjamanDelete)
+	app.Get("/trash", GetTrash)
+	app.Get("/barang", GetAllBarang)
+	app.Post("/barang/:id/restore", RestoreBarang)
+	app.Post("/kategori/:id/restore", RestoreKategori)
+	app.Post("/peminjaman/:id/restore", RestorePeminjaman)
+
+	status, body := kirimJSON(t, app, "GET", "/barang", "")
+	if status != 200 || len(namaData(body, "nama")) != 0 {
+		t.Errorf("daftar barang = %d %v, ingin kosong", status, body)
+	}
+
+	status, body = kirimJSON(t, app, "GET", "/trash", "")
+	if status != 200 {
+		t.Fatalf("trash: status = %d, body = %v", status, body)
+	}
+	for jenis, total := range map[string]float64{TrashBarang: 2, TrashKategori: 1, TrashPeminjaman: 1} {
+		isi := body[jenis].(map[string]interface{})
+		if meta := isi["meta"].(map[string]interface{}); meta["total"] != total {
+			t.Errorf("trash %s total = %v, ingin %v", jenis, meta["total"], total)
+		}
+	}
+	barangTrash := body[TrashBarang].(map[string]interface{})["data"].([]interface{})
+	if d := barangTrash[0].(map[string]interface{}); d["deleted_at"] == nil || d["deleted_by"] != oleh.Hex() {
+		t.Errorf("barang di trash = %v, ingin deleted_at dan deleted_by %s", d, oleh.Hex())
+	}
+
+	status, body = kirimJSON(t, app, "GET", "/trash?jenis=kategori", "")
+	if status != 200 || len(body) != 1 || body[TrashKategori] == nil {
+		t.Errorf("trash jenis kategori = %d %v, ingin hanya kategori", status, body)
+	}
+	for _, q := range []string{"jenis=user", "after=" + primitive.NewObjectID().Hex()} {
+		if status, body := kirimJSON(t, app, "GET", "/trash?"+q, ""); status != 400 {
+			t.Errorf("%s: status = %d, body = %v, ingin 400", q, status, body)
+		}
+	}
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		// Induknya masih di tempat sampah
+		{"/barang/" + laptop.ID.Hex() + "/restore", 409},
+		{"/peminjaman/" + p.ID.Hex() + "/restore", 409},
+		{"/kategori/" + kategori.ID.Hex() + "/restore", 200},
+		{"/kategori/" + kategori.ID.Hex() + "/restore", 404},
+		{"/peminjaman/" + p.ID.Hex() + "/restore", 409},
+		{"/barang/" + proyektor.ID.Hex() + "/restore", 200},
+		{"/peminjaman/" + p.ID.Hex() + "/restore", 200},
+		{"/barang/" + laptop.ID.Hex() + "/restore", 200},
+	}
+	for _, tt := range tests {
+		if status, body := kirimJSON(t, app, "POST", tt.path, ""); status != tt.status {
+			t.Errorf("%s: status = %d, body = %v, ingin %d", tt.path, status, body, tt.status)
+		}
+	}
+
+	status, body = kirimJSON(t, app, "GET", "/barang?sort=nama", "")
+	if got := strings.Join(namaData(body, "nama"), ","); status != 200 || got != "Laptop,Proyektor" {
+		t.Errorf("daftar barang setelah restore = %d %s, ingin Laptop,Proyektor", status, got)
+	}
+	pulih, err := barangRepo.FindByID(ctx, laptop.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pulih.SudahDihapus() || pulih.DeletedBy != nil {
+		t.Errorf("barang dipulihkan masih bertanda terhapus: %+v", pulih.Terhapus)
+	}
+}
+
+// TestRestorePeminjamanStokKurang memastikan peminjaman yang menahan stok
+// memesan stoknya lagi saat dipulihkan dan tetap di tempat sampah jika kurang
+func TestRestorePeminjamanStokKurang(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	barang := siapkanBarang(t, "Proyektor", 1)
+	p := models.Peminjaman{
+		ID:     primitive.NewObjectID(),
+		Items:  []models.ItemPeminjaman{{BarangID: barang.ID, Jumlah: 2}},
+		Status: models.StatusDipinjam,
+	}
+	if err := peminjamanRepo.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+	if err := peminjamanRepo.Delete(ctx, p.ID, primitive.NewObjectID()); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(primitive.NewObjectID(), models.PermPeminjamanDelete)
+	app.Post("/peminjaman/:id/restore", RestorePeminjaman)
+
+	if status, body := kirimJSON(t, app, "POST", "/peminjaman/"+p.ID.Hex()+"/restore", ""); status != 400 {
+		t.Errorf("status = %d, body = %v, ingin 400", status, body)
+	}
+	if _, err := peminjamanRepo.FindByID(ctx, p.ID); err != repository.ErrNotFound {
+		t.Errorf("peminjaman: err = %v, ingin tetap di tempat sampah", err)
+	}
+	if b, _ := barangRepo.FindByID(ctx, barang.ID); b.Stok != 1 {
+		t.Errorf("stok = %d, ingin tetap 1", b.Stok)
+	}
+}
+
+// TestBersihkanTrash memastikan pembersihan hanya menghapus data yang lewat
+// batas dan tidak lagi dirujuk data lain, termasuk rujukan dari tempat sampah
+func TestBersihkanTrash(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	elektronik, barang := siapkanKategori(t, "Elektronik", "Proyektor", "Laptop", "Kabel")
+	proyektor, laptop, kabel := barang[0], barang[1], barang[2]
+	kosong, _ := siapkanKategori(t, "Kosong")
+
+	// Riwayat aktif masih merujuk proyektor; peminjaman terhapus merujuk laptop
+	riwayat := models.Peminjaman{ID: primitive.NewObjectID(), Items: []models.ItemPeminjaman{{BarangID: proyektor.ID, Jumlah: 1}}, Status: models.StatusDikembalikan}
+	lama := models.Peminjaman{ID: primitive.NewObjectID(), Items: []models.ItemPeminjaman{{BarangID: laptop.ID, Jumlah: 1}}, Status: models.StatusDikembalikan}
+	for _, p := range []*models.Peminjaman{&riwayat, &lama} {
+		if err := peminjamanRepo.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oleh := primitive.NewObjectID()
+	if err := peminjamanRepo.Delete(ctx, lama.ID, oleh); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := barangRepo.DeleteByKategori(ctx, elektronik.ID, oleh); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []primitive.ObjectID{elektronik.ID, kosong.ID} {
+		if err := kategoriRepo.Delete(ctx, k, oleh); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hasil, err := BersihkanTrash(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hasil) != 0 {
+		t.Errorf("sebelum batas = %v, ingin tidak ada yang dibersihkan", hasil)
+	}
+
+	hasil, err = BersihkanTrash(ctx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingin := map[string]int{TrashPeminjaman: 1, TrashBarang: 2, TrashKategori: 1}
+	for jenis, n := range ingin {
+		if hasil[jenis] != n {
+			t.Errorf("dibersihkan %s = %d, ingin %d (hasil %v)", jenis, hasil[jenis], n, hasil)
+		}
+	}
+
+	// Proyektor dan kategorinya tetap di tempat sampah dan masih bisa dipulihkan
+	if err := kategoriRepo.Restore(ctx, elektronik.ID); err != nil {
+		t.Errorf("restore kategori dirujuk: %v", err)
+	}
+	if err := barangRepo.Restore(ctx, proyektor.ID); err != nil {
+		t.Errorf("restore barang dirujuk: %v", err)
+	}
+	for _, id := range []primitive.ObjectID{laptop.ID, kabel.ID} {
+		if err := barangRepo.Restore(ctx, id); err != repository.ErrNotFound {
+			t.Errorf("restore barang %s: err = %v, ingin ErrNotFound", id.Hex(), err)
+		}
+	}
+	if err := kategoriRepo.Restore(ctx, kosong.ID); err != repository.ErrNotFound {
+		t.Errorf("restore kategori kosong: err = %v, ingin ErrNotFound", err)
+	}
+}
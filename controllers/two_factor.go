@@ -0,0 +1,475 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"inventory-backend/config"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tujuanTantangan2FA menandai challenge token agar tidak bisa dipakai sebagai
+// access token (challenge token juga tidak punya claim sid)
+const tujuanTantangan2FA = "2fa"
+
+// jumlahKodePemulihan adalah jumlah kode pemulihan yang dibuat sekaligus
+const jumlahKodePemulihan = 10
+
+// KodeRequest adalah body berisi kode 2FA
+type KodeRequest struct {
+	Kode string `json:"kode"`
+}
+
+// Verify2FARequest adalah body langkah kedua login untuk user dengan 2FA
+type Verify2FARequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Kode           string `json:"kode"` // kode TOTP 6 digit atau kode pemulihan
+}
+
+// Disable2FARequest adalah body untuk menonaktifkan 2FA
+type Disable2FARequest struct {
+	Password string `json:"password"`
+	Kode     string `json:"kode"`
+}
+
+// buatTantangan2FA membuat challenge token berumur pendek setelah password benar
+func buatTantangan2FA(user *models.User, metode string) (string, error) {
+	now := time.Now()
+	return tokenService.Sign(jwt.MapClaims{
+		"user_id": user.ID.Hex(),
+		"tujuan":  tujuanTantangan2FA,
+		"metode":  metode,
+		"exp":     now.Add(config.Tantangan2FATTL()).Unix(),
+		"iat":     now.Unix(),
+	})
+}
+
+// buatKodePemulihan membuat kode pemulihan baru. Kode mentah dikembalikan
+// untuk ditampilkan sekali, yang disimpan hanya hash-nya.
+func buatKodePemulihan() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	kode := make([]string, 0, jumlahKodePemulihan)
+	hash := make([]string, 0, jumlahKodePemulihan)
+	for range jumlahKodePemulihan {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		s := strings.ToLower(encoding.EncodeToString(b))[:10]
+		kode = append(kode, s[:5]+"-"+s[5:])
+		hash = append(hash, hashToken(s))
+	}
+	return kode, hash, nil
+}
+
+// cocokkanKode2FA memeriksa kode TOTP atau kode pemulihan dan menyimpan
+// pemakaiannya, sehingga kode TOTP dan kode pemulihan tidak bisa dipakai dua kali.
+// Pemakaian disimpan dengan update bersyarat di database, jadi dari dua request
+// bersamaan dengan kode yang sama hanya satu yang berhasil.
+func cocokkanKode2FA(ctx context.Context, user *models.User, kode string, bolehPemulihan bool) (bool, error) {
+	kode = strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(kode))
+
+	if langkah, ok := services.CocokkanTOTP(user.TOTPSecret, kode, time.Now()); ok {
+		if langkah <= user.TOTPLangkah {
+			return false, nil
+		}
+		dipakai, err := userRepo.PakaiLangkahTOTP(ctx, user.ID, langkah)
+		if err != nil || !dipakai {
+			return false, err
+		}
+		user.TOTPLangkah = langkah
+		return true, nil
+	}
+
+	if !bolehPemulihan {
+		return false, nil
+	}
+	hash := hashToken(kode)
+	i := slices.Index(user.KodePemulihan, hash)
+	if i < 0 {
+		return false, nil
+	}
+	dipakai, err := userRepo.PakaiKodePemulihan(ctx, user.ID, hash)
+	if err != nil || !dipakai {
+		return false, err
+	}
+	user.KodePemulihan = slices.Delete(slices.Clone(user.KodePemulihan), i, i+1)
+	return true, nil
+}
+
+// Verify2FA godoc
+// @Summary Verifikasi kode 2FA saat login
+// @Description Langkah kedua login untuk user yang mengaktifkan 2FA. Menukar challenge token dari /auth/login dan kode TOTP (atau kode pemulihan) dengan access token dan refresh token.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Param body body Verify2FARequest true "Challenge token dan kode"
+// @Success 200 {object} map[string]interface{} "Login berhasil"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Challenge token atau kode tidak valid"
+// @Failure 429 {object} map[string]interface{} "Terlalu banyak percobaan gagal"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/2fa/verify [post]
+func Verify2FA(c *fiber.Ctx) error {
+	var body Verify2FARequest
+	if err := c.BodyParser(&body); err != nil || body.ChallengeToken == "" || body.Kode == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "challenge_token dan kode wajib diisi"})
+	}
+
+	claims, err := tokenService.Parse(body.ChallengeToken)
+	if err != nil || claims["tujuan"] != tujuanTantangan2FA {
+		return c.Status(401).JSON(fiber.Map{"error": "Challenge token tidak valid atau sudah kedaluwarsa, silakan login kembali"})
+	}
+	userHex, _ := claims["user_id"].(string)
+	userID, err := primitive.ObjectIDFromHex(userHex)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Challenge token tidak valid"})
+	}
+
+	ctx := context.Background()
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil || user.Nonaktif || !user.TOTPAktif {
+		return c.Status(401).JSON(fiber.Map{"error": "Challenge token tidak valid"})
+	}
+
+	// Kode 2FA yang salah dihitung sama seperti password salah
+	tunggu, err := tungguLogin(ctx, kunciEmail(user.Email), kunciIPRequest(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if tunggu > 0 {
+		return tolakLogin(c, tunggu)
+	}
+
+	ok, err := cocokkanKode2FA(ctx, user, body.Kode, true)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		if err := catatRiwayat(context.Background(), c, riwayatLoginGagal(user.Email, user, "kode 2FA salah")); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		dikunci, err := catatLoginGagal(c, user.Email, user)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if dikunci > 0 {
+			return tolakLogin(c, dikunci)
+		}
+		return c.Status(401).JSON(fiber.Map{"error": "Kode 2FA salah"})
+	}
+
+	if err := loginAttemptRepo.Reset(ctx, kunciEmail(user.Email)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	metode, _ := claims["metode"].(string)
+	if metode == "" {
+		metode = models.MetodePassword
+	}
+	response, err := buatSesi(context.Background(), c, user, metode)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":             "Login berhasil",
+		"data":                response,
+		"sisa_kode_pemulihan": len(user.KodePemulihan),
+	})
+}
+
+// Setup2FA godoc
+// @Summary Mulai pendaftaran 2FA
+// @Description Membuat secret TOTP baru dan mengembalikan URI otpauth untuk dipindai aplikasi authenticator. 2FA baru aktif setelah kode pertama dikonfirmasi lewat /auth/2fa/enable.
+// @Tags Two-Factor Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Secret dan URI otpauth"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "2FA sudah aktif"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/2fa/setup [post]
+func Setup2FA(c *fiber.Ctx) error {
+	ctx := context.Background()
+	user, err := userRepo.FindByID(ctx, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if user.TOTPAktif {
+		return c.Status(409).JSON(fiber.Map{"error": "2FA sudah aktif, nonaktifkan dulu untuk mendaftarkan ulang"})
+	}
+
+	secret, err := services.BuatSecretTOTP()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat secret 2FA"})
+	}
+	if err := userRepo.SimpanSecretTOTP(ctx, user.ID, secret); err == repository.ErrNotFound {
+		return c.Status(409).JSON(fiber.Map{"error": "2FA sudah aktif, nonaktifkan dulu untuk mendaftarkan ulang"})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"secret":      secret,
+		"otpauth_uri": services.URIOtpauth(config.TOTPIssuer(), user.Email, secret),
+	})
+}
+
+// Enable2FA godoc
+// @Summary Aktifkan 2FA
+// @Description Mengonfirmasi kode dari aplikasi authenticator lalu mengaktifkan 2FA. Semua sesi lain dicabut dan sesi baru yang sudah terverifikasi 2FA dikembalikan beserta kode pemulihan yang hanya ditampilkan sekali.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body KodeRequest true "Kode TOTP"
+// @Success 200 {object} map[string]interface{} "2FA aktif"
+// @Failure 400 {object} map[string]interface{} "Setup belum dilakukan atau kode salah"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "2FA sudah aktif"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/2fa/enable [post]
+func Enable2FA(c *fiber.Ctx) error {
+	var body KodeRequest
+	if err := c.BodyParser(&body); err != nil || body.Kode == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "kode wajib diisi"})
+	}
+
+	ctx := context.Background()
+	user, err := userRepo.FindByID(ctx, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if user.TOTPAktif {
+		return c.Status(409).JSON(fiber.Map{"error": "2FA sudah aktif"})
+	}
+	if user.TOTPSecret == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Jalankan /auth/2fa/setup terlebih dahulu"})
+	}
+
+	ok, err := cocokkanKode2FA(ctx, user, body.Kode, false)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Kode 2FA salah"})
+	}
+
+	kode, hash, err := buatKodePemulihan()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat kode pemulihan"})
+	}
+
+	// Sesi lama dibuat tanpa 2FA, jadi semuanya diganti dengan sesi baru yang
+	// memakai metode login sesi saat ini
+	metode := models.MetodePassword
+	sessionID, _ := c.Locals("session_id").(primitive.ObjectID)
+	if session, err := sessionRepo.FindByID(ctx, sessionID); err == nil && session.Metode != "" {
+		metode = session.Metode
+	}
+
+	// 2FA, kode pemulihan, pergantian sesi dan audit disimpan bersama agar sesi
+	// tanpa 2FA tidak tertinggal hidup setelah 2FA aktif
+	var response *models.LoginResponse
+	err = txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := userRepo.AktifkanTOTP(ctx, user.ID, hash); err == repository.ErrNotFound {
+			return fiber.NewError(409, "2FA sudah aktif")
+		} else if err != nil {
+			return err
+		}
+		aktif, err := userRepo.FindByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := sessionRepo.RevokeByUser(ctx, user.ID, nil, models.SesiAktifkan2FA); err != nil {
+			return err
+		}
+		if response, err = buatSesi(ctx, c, aktif, metode); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditAktifkan2FA, models.ResourceUser, user.ID, user, aktif, nil)
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":        "2FA berhasil diaktifkan. Simpan kode pemulihan di tempat aman",
+		"kode_pemulihan": kode,
+		"data":           response,
+	})
+}
+
+// Disable2FA godoc
+// @Summary Nonaktifkan 2FA
+// @Description Menonaktifkan 2FA dengan password dan kode TOTP atau kode pemulihan. Tidak bisa dilakukan jika role user wajib memakai 2FA.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body Disable2FARequest true "Password dan kode"
+// @Success 200 {object} map[string]interface{} "2FA dinonaktifkan"
+// @Failure 400 {object} map[string]interface{} "Password atau kode salah"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Role wajib 2FA"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/2fa/disable [post]
+func Disable2FA(c *fiber.Ctx) error {
+	var body Disable2FARequest
+	if err := c.BodyParser(&body); err != nil || body.Password == "" || body.Kode == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "password dan kode wajib diisi"})
+	}
+
+	ctx := context.Background()
+	user, err := userRepo.FindByID(ctx, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !user.TOTPAktif {
+		return c.Status(400).JSON(fiber.Map{"error": "2FA belum aktif"})
+	}
+	if config.Wajib2FA(user.Role) {
+		return c.Status(403).JSON(fiber.Map{"error": "Role " + user.Role + " wajib memakai 2FA"})
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password)) != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Password salah"})
+	}
+
+	ok, err := cocokkanKode2FA(ctx, user, body.Kode, true)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Kode 2FA salah"})
+	}
+
+	// 2FA dan audit disimpan bersama
+	err = txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := userRepo.Hapus2FA(ctx, user.ID); err != nil {
+			return err
+		}
+		nonaktif, err := userRepo.FindByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditNonaktifkan2FA, models.ResourceUser, user.ID, user, nonaktif, nil)
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "2FA berhasil dinonaktifkan"})
+}
+
+// RegenerateKodePemulihan godoc
+// @Summary Buat ulang kode pemulihan
+// @Description Mengganti semua kode pemulihan dengan yang baru. Membutuhkan kode TOTP dari aplikasi authenticator.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body KodeRequest true "Kode TOTP"
+// @Success 200 {object} map[string]interface{} "Kode pemulihan baru"
+// @Failure 400 {object} map[string]interface{} "2FA belum aktif atau kode salah"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/2fa/recovery-codes [post]
+func RegenerateKodePemulihan(c *fiber.Ctx) error {
+	var body KodeRequest
+	if err := c.BodyParser(&body); err != nil || body.Kode == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "kode wajib diisi"})
+	}
+
+	ctx := context.Background()
+	user, err := userRepo.FindByID(ctx, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !user.TOTPAktif {
+		return c.Status(400).JSON(fiber.Map{"error": "2FA belum aktif"})
+	}
+
+	ok, err := cocokkanKode2FA(ctx, user, body.Kode, false)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Kode 2FA salah"})
+	}
+
+	kode, hash, err := buatKodePemulihan()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat kode pemulihan"})
+	}
+	if err := userRepo.GantiKodePemulihan(ctx, user.ID, hash); err == repository.ErrNotFound {
+		return c.Status(400).JSON(fiber.Map{"error": "2FA belum aktif"})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":        "Kode pemulihan lama tidak berlaku lagi",
+		"kode_pemulihan": kode,
+	})
+}
+
+// Reset2FAUser godoc
+// @Summary Reset 2FA user
+// @Description Menghapus 2FA user yang kehilangan aplikasi authenticator dan kode pemulihannya, lalu mencabut semua sesinya. User dengan role wajib 2FA harus mendaftarkan ulang setelah login.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "2FA direset"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid atau akun sendiri"
+// @Failure 403 {object} map[string]interface{} "Role user melebihi permission pemanggil"
+// @Failure 404 {object} map[string]interface{} "User tidak ditemukan"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/reset-2fa [post]
+func Reset2FAUser(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	var user *models.User
+	// 2FA, pencabutan sesi dan audit disimpan bersama agar sesi lama tidak
+	// tertinggal hidup setelah 2FA dihapus
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		sebelum, err := userDikelola(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		// Admin yang kehilangan 2FA harus direset admin lain atau memakai kode pemulihan
+		if id == currentUserID(c) {
+			return fiber.NewError(400, "Tidak bisa mereset 2FA akun sendiri")
+		}
+
+		if err := userRepo.Hapus2FA(ctx, id); err != nil {
+			return err
+		}
+		if user, err = userRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		if _, err := sessionRepo.RevokeByUser(ctx, id, nil, models.SesiReset2FA); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditReset2FA, models.ResourceUser, id, sebelum, user, nil)
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "2FA user " + user.Email + " berhasil direset"})
+}
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/services"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// pakaiKodeParalel memakai kode yang sama dari beberapa request bersamaan.
+// Semua request sudah membaca user sebelum kode dipakai, seperti handler yang
+// berjalan bersamaan, lalu jumlah yang berhasil dikembalikan.
+func pakaiKodeParalel(t *testing.T, id primitive.ObjectID, kode string) int32 {
+	t.Helper()
+	users := make([]*models.User, 10)
+	for i := range users {
+		user, err := userRepo.FindByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		users[i] = user
+	}
+
+	var berhasil atomic.Int32
+	var wg sync.WaitGroup
+	for _, user := range users {
+		wg.Add(1)
+		go func(user *models.User) {
+			defer wg.Done()
+			ok, err := cocokkanKode2FA(context.Background(), user, kode, true)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if ok {
+				berhasil.Add(1)
+			}
+		}(user)
+	}
+	wg.Wait()
+	return berhasil.Load()
+}
+
+func TestKodeTOTPParalelHanyaSekali(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	secret, err := services.BuatSecretTOTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", TOTPAktif: true, TOTPSecret: secret}
+	if err := userRepo.Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	kode, err := services.KodeTOTP(secret, services.LangkahTOTP(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := pakaiKodeParalel(t, user.ID, kode); n != 1 {
+		t.Errorf("kode TOTP berhasil dipakai %d kali, ingin 1", n)
+	}
+}
+
+func TestKodePemulihanParalelHanyaSekali(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	kode, hash, err := buatKodePemulihan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", TOTPAktif: true, KodePemulihan: hash}
+	if err := userRepo.Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := pakaiKodeParalel(t, user.ID, kode[0]); n != 1 {
+		t.Errorf("kode pemulihan berhasil dipakai %d kali, ingin 1", n)
+	}
+	tersimpan, err := userRepo.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tersimpan.KodePemulihan) != jumlahKodePemulihan-1 {
+		t.Errorf("sisa kode pemulihan = %d, ingin %d", len(tersimpan.KodePemulihan), jumlahKodePemulihan-1)
+	}
+}
+
+// TestUpdateUserTidakMengembalikanLangkahTOTP memakai kode TOTP sambil profil
+// user diubah bersamaan. Update tidak boleh menimpa langkah TOTP terakhir,
+// karena itu akan membuat kode yang sudah dipakai bisa diputar ulang.
+func TestUpdateUserTidakMengembalikanLangkahTOTP(t *testing.T) {
+	const jumlahLangkah = 50
+
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", TOTPAktif: true, TOTPSecret: "rahasia"}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for langkah := int64(1); langkah <= jumlahLangkah; langkah++ {
+			if ok, err := userRepo.PakaiLangkahTOTP(ctx, user.ID, langkah); err != nil || !ok {
+				t.Errorf("PakaiLangkahTOTP(%d) = %v, %v, ingin true", langkah, ok, err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < jumlahLangkah; i++ {
+			nama := "Budi " + strconv.Itoa(i)
+			if _, err := userRepo.Update(ctx, user.ID, repository.UserUpdate{NamaLengkap: &nama}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	tersimpan, err := userRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tersimpan.TOTPLangkah != jumlahLangkah {
+		t.Errorf("langkah TOTP = %d, ingin %d", tersimpan.TOTPLangkah, jumlahLangkah)
+	}
+	if ok, _ := userRepo.PakaiLangkahTOTP(ctx, user.ID, jumlahLangkah); ok {
+		t.Error("langkah TOTP terakhir bisa dipakai ulang setelah Update")
+	}
+	if !tersimpan.TOTPAktif || tersimpan.TOTPSecret != "rahasia" {
+		t.Errorf("data 2FA berubah setelah Update: aktif = %v, secret = %q", tersimpan.TOTPAktif, tersimpan.TOTPSecret)
+	}
+}
+
+func TestReset2FAUserAtomik(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	ctx := context.Background()
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: models.RoleUser, TOTPAktif: true, TOTPSecret: "rahasia"}
+	if err := repos.User.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	reset := func() int {
+		app := fiber.New()
+		app.Post("/users/:id/reset-2fa", func(c *fiber.Ctx) error {
+			c.Locals("user_id", primitive.NewObjectID().Hex())
+			c.Locals("permissions", []string{models.PermUsersManage})
+			return c.Next()
+		}, Reset2FAUser)
+		resp, err := app.Test(httptest.NewRequest("POST", "/users/"+user.ID.Hex()+"/reset-2fa", nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	// Pencabutan sesi gagal: 2FA dan audit ikut dibatalkan
+	gagal := *repos
+	gagal.Session = sessionGagal{repos.Session}
+	SetRepositories(&gagal)
+	if status := reset(); status != 500 {
+		t.Fatalf("status = %d, ingin 500", status)
+	}
+	if tersimpan, _ := userRepo.FindByID(ctx, user.ID); !tersimpan.TOTPAktif {
+		t.Error("2FA terhapus walau sesi gagal dicabut")
+	}
+
+	SetRepositories(repos)
+	if status := reset(); status != 200 {
+		t.Fatalf("status = %d, ingin 200", status)
+	}
+	if tersimpan, _ := userRepo.FindByID(ctx, user.ID); tersimpan.TOTPAktif || tersimpan.TOTPSecret != "" {
+		t.Error("2FA masih tersimpan setelah direset")
+	}
+	logs, total, _ := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: models.AuditReset2FA}, repository.ListOptions{})
+	if total != 1 {
+		t.Fatalf("audit log = %d, ingin 1", total)
+	}
+	if _, ok := logs[0].Perubahan["totp_aktif"]; !ok {
+		t.Errorf("perubahan = %+v, ingin memuat totp_aktif", logs[0].Perubahan)
+	}
+}
+
+func TestAktifkanDanNonaktifkan2FAAtomik(t *testing.T) {
+	t.Setenv("JWT_SECRET", "rahasia-uji-2fa")
+	tokens, err := services.NewTokenServiceFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTokenService(tokens)
+
+	repos := repository.NewMemoryRepositories()
+	SetRepositories(repos)
+	ctx := context.Background()
+	secret, err := services.BuatSecretTOTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Password: string(hash), Role: models.RoleUser, TOTPSecret: secret}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	lama := models.Session{ID: primitive.NewObjectID(), UserID: user.ID, RefreshTokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := sessionRepo.Create(ctx, &lama); err != nil {
+		t.Fatal(err)
+	}
+
+	app := appPengguna(user.ID)
+	app.Post("/auth/2fa/enable", Enable2FA)
+	app.Post("/auth/2fa/disable", Disable2FA)
+	kode := func(langkah int64) string {
+		k, err := services.KodeTOTP(secret, langkah)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	langkah := services.LangkahTOTP(time.Now())
+
+	// Pencabutan sesi gagal: 2FA tidak aktif dan tidak ada sesi baru
+	gagal := *repos
+	gagal.Session = sessionGagal{repos.Session}
+	SetRepositories(&gagal)
+	if status, body := kirimJSON(t, app, "POST", "/auth/2fa/enable", `{"kode":"`+kode(langkah)+`"}`); status != 500 {
+		t.Fatalf("status = %d, body = %v, ingin 500", status, body)
+	}
+	if tersimpan, _ := userRepo.FindByID(ctx, user.ID); tersimpan.TOTPAktif || len(tersimpan.KodePemulihan) != 0 {
+		t.Fatal("2FA aktif walau sesi lama gagal dicabut")
+	}
+	if aktif, _ := sessionRepo.FindAktifByUser(ctx, user.ID); len(aktif) != 1 {
+		t.Errorf("sesi aktif = %d, ingin hanya sesi lama", len(aktif))
+	}
+
+	SetRepositories(repos)
+	status, body := kirimJSON(t, app, "POST", "/auth/2fa/enable", `{"kode":"`+kode(langkah+1)+`"}`)
+	if status != 200 {
+		t.Fatalf("status = %d, body = %v, ingin 200", status, body)
+	}
+	pemulihan, _ := body["kode_pemulihan"].([]interface{})
+	if len(pemulihan) < 2 {
+		t.Fatalf("kode pemulihan = %v, ingin minimal 2", body["kode_pemulihan"])
+	}
+	if aktif, _ := sessionRepo.FindAktifByUser(ctx, user.ID); len(aktif) != 1 || aktif[0].ID == lama.ID || !aktif[0].Dengan2FA {
+		t.Errorf("sesi aktif = %+v, ingin satu sesi baru dengan 2FA", aktif)
+	}
+
+	// Audit gagal: 2FA tetap aktif
+	gagal = *repos
+	gagal.AuditLog = auditGagal{repos.AuditLog}
+	SetRepositories(&gagal)
+	if status, body := kirimJSON(t, app, "POST", "/auth/2fa/disable", `{"password":"rahasia123","kode":"`+pemulihan[0].(string)+`"}`); status != 500 {
+		t.Fatalf("status = %d, body = %v, ingin 500", status, body)
+	}
+	if tersimpan, _ := userRepo.FindByID(ctx, user.ID); !tersimpan.TOTPAktif {
+		t.Fatal("2FA nonaktif walau audit gagal")
+	}
+
+	SetRepositories(repos)
+	if status, body := kirimJSON(t, app, "POST", "/auth/2fa/disable", `{"password":"rahasia123","kode":"`+pemulihan[1].(string)+`"}`); status != 200 {
+		t.Fatalf("status = %d, body = %v, ingin 200", status, body)
+	}
+	if tersimpan, _ := userRepo.FindByID(ctx, user.ID); tersimpan.TOTPAktif || tersimpan.TOTPSecret != "" {
+		t.Error("2FA masih tersimpan setelah dinonaktifkan")
+	}
+	for _, aksi := range []string{models.AuditAktifkan2FA, models.AuditNonaktifkan2FA} {
+		if _, total, _ := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: aksi}, repository.ListOptions{}); total != 1 {
+			t.Errorf("audit %s = %d, ingin 1", aksi, total)
+		}
+	}
+}
@@ -0,0 +1,522 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"inventory-backend/validators"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CreateUserRequest adalah body untuk admin membuat user baru
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// SeedAdmin membuat akun admin awal dari ADMIN_EMAIL dan ADMIN_PASSWORD jika
+// belum ada, karena registrasi publik tidak bisa membuat admin
+func SeedAdmin(ctx context.Context, email, password string) error {
+	if email == "" || password == "" {
+		return nil
+	}
+	if _, err := userRepo.FindByEmail(ctx, email); err == nil {
+		return nil
+	} else if err != repository.ErrNotFound {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	username, _, _ := strings.Cut(email, "@")
+	admin := models.User{
+		ID:        primitive.NewObjectID(),
+		Username:  username,
+		Email:     email,
+		Password:  string(hashedPassword),
+		Role:      models.RoleAdmin,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := userRepo.Create(ctx, &admin); err != nil {
+		return err
+	}
+	log.Printf("Admin awal %s berhasil dibuat", email)
+	return nil
+}
+
+// cariUser mengambil user dari parameter :id. Jika user tidak didapat, response
+// error sudah ditulis dan handler cukup mengembalikan error kedua.
+func cariUser(c *fiber.Ctx) (*models.User, error) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	user, err := userRepo.FindByID(context.Background(), id)
+	if err == repository.ErrNotFound {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return user, nil
+}
+
+// cariUserDikelola mengambil user dari parameter :id seperti cariUser, lalu
+// menolak dengan 403 jika role user tersebut memiliki permission yang tidak
+// dimiliki pemanggil (lihat cekPengelolaanUser)
+func cariUserDikelola(c *fiber.Ctx) (*models.User, error) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	user, err := userDikelola(context.Background(), c, id)
+	if err != nil {
+		return nil, respondUserError(c, err)
+	}
+	return user, nil
+}
+
+// userDikelola mengambil user dengan ID tersebut dan memastikan pemanggil boleh
+// mengelolanya. User yang tidak ada atau tidak boleh dikelola dikembalikan
+// sebagai *fiber.Error 404 atau 403. Perubahan user harus memanggilnya dengan
+// ctx transaksi agar yang diubah adalah data terbaru.
+func userDikelola(ctx context.Context, c *fiber.Ctx, id primitive.ObjectID) (*models.User, error) {
+	user, err := userRepo.FindByID(ctx, id)
+	if err == repository.ErrNotFound {
+		return nil, fiber.NewError(404, "User tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	pesan, err := cekPengelolaanUser(ctx, c, user)
+	if err != nil {
+		return nil, err
+	}
+	if pesan != "" {
+		return nil, fiber.NewError(403, pesan)
+	}
+	return user, nil
+}
+
+// jagaAdminTerakhir mengembalikan *fiber.Error 409 berisi pesan jika user adalah
+// satu-satunya admin aktif, sehingga tidak boleh diturunkan, dinonaktifkan atau
+// dihapus. Harus dipanggil di dalam transaksi yang sama dengan perubahannya;
+// KunciAdminAktif memastikan dua admin yang saling menurunkan bersamaan tidak
+// sama-sama lolos.
+func jagaAdminTerakhir(ctx context.Context, user *models.User, pesan string) error {
+	if user.Role != models.RoleAdmin || user.Nonaktif {
+		return nil
+	}
+	if err := userRepo.KunciAdminAktif(ctx); err != nil {
+		return err
+	}
+
+	aktif := false
+	_, total, err := userRepo.FindAll(ctx, repository.UserFilter{Role: models.RoleAdmin, Nonaktif: &aktif}, repository.ListOptions{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if total <= 1 {
+		return fiber.NewError(409, pesan)
+	}
+	return nil
+}
+
+// respondUserError memetakan error dari transaksi perubahan user ke response HTTP
+func respondUserError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": fiberErr.Message})
+	}
+	if pesan := pesanAkunDipakai(err); pesan != "" {
+		return c.Status(400).JSON(fiber.Map{"error": pesan})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// GetAllUsers godoc
+// @Summary Get all users
+// @Description Mengambil daftar user dengan pencarian username/email, filter role dan status, serta pagination
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Cari potongan username atau email (case-insensitive)"
+// @Param role query string false "Filter nama role"
+// @Param status query string false "Filter status: aktif atau nonaktif"
+// @Param page query int false "Halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param sort query string false "Urutan: id, username, email atau created_at; awali dengan - untuk menurun"
+// @Param after query string false "ID user terakhir halaman sebelumnya (keyset pagination, menggantikan page)"
+// @Success 200 {object} map[string]interface{} "Daftar user"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users [get]
+func GetAllUsers(c *fiber.Ctx) error {
+	filter := repository.UserFilter{
+		Q:    strings.TrimSpace(c.Query("q")),
+		Role: c.Query("role"),
+	}
+	switch c.Query("status") {
+	case "":
+	case "aktif":
+		nonaktif := false
+		filter.Nonaktif = &nonaktif
+	case "nonaktif":
+		nonaktif := true
+		filter.Nonaktif = &nonaktif
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "status harus 'aktif' atau 'nonaktif'"})
+	}
+
+	opts, err := parseListOptions(c, map[string]string{
+		"id":         "_id",
+		"username":   "username",
+		"email":      "email",
+		"created_at": "created_at",
+	})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	users, total, err := userRepo.FindAll(context.Background(), filter, opts)
+	if err != nil {
+		return errorList(c, err, "Gagal mengambil data user")
+	}
+
+	for i := range users {
+		users[i].Password = ""
+	}
+	return c.JSON(paginated(c, users, opts, total, func(u models.User) primitive.ObjectID { return u.ID }))
+}
+
+// GetUserByID godoc
+// @Summary Get user by ID
+// @Description Mengambil data satu user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.User "Data user"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 404 {object} map[string]interface{} "User tidak ditemukan"
+// @Router /users/{id} [get]
+func GetUserByID(c *fiber.Ctx) error {
+	user, err := cariUser(c)
+	if user == nil {
+		return err
+	}
+
+	user.Password = ""
+	return c.JSON(user)
+}
+
+// CreateUser godoc
+// @Summary Create user
+// @Description Membuat user baru dengan role yang sudah terdaftar (default 'user'). Role hanya boleh berisi permission yang juga dimiliki pemanggil.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user body CreateUserRequest true "Data user baru"
+// @Success 201 {object} models.User "User berhasil dibuat"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Role melebihi permission pemanggil"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users [post]
+func CreateUser(c *fiber.Ctx) error {
+	var body CreateUserRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if body.Role == "" {
+		body.Role = models.RoleUser
+	}
+	if err := validators.ValidateUser(body.Username, body.Email, body.Password); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengenkripsi password"})
+	}
+
+	user := models.User{
+		ID:        primitive.NewObjectID(),
+		Username:  body.Username,
+		Email:     body.Email,
+		Password:  string(hashedPassword),
+		Role:      body.Role,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	// User dan audit disimpan bersama agar tidak ada akun tanpa jejak pembuatnya
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := cekPemberianRole(ctx, c, user.Role); err != nil {
+			return err
+		}
+		pesan, err := cekAkunUnik(ctx, user.Username, user.Email, primitive.NilObjectID)
+		if err != nil {
+			return err
+		}
+		if pesan != "" {
+			return fiber.NewError(400, pesan)
+		}
+		if err := userRepo.Create(ctx, &user); err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditBuatUser, models.ResourceUser, user.ID, nil, user, nil)
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	user.Password = ""
+	return c.Status(201).JSON(user)
+}
+
+// UpdateRoleUser godoc
+// @Summary Update role user
+// @Description Mengubah role user ke role lain yang sudah terdaftar. Role lama dan role baru hanya boleh berisi permission yang juga dimiliki pemanggil. Admin aktif terakhir tidak bisa diturunkan.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param role body object{role=string} true "Role baru"
+// @Success 200 {object} models.User "Role berhasil diubah"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Role lama atau role baru melebihi permission pemanggil"
+// @Failure 404 {object} map[string]interface{} "User tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Admin aktif terakhir"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/role [put]
+func UpdateRoleUser(c *fiber.Ctx) error {
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	var user *models.User
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := cekPemberianRole(ctx, c, body.Role); err != nil {
+			return err
+		}
+		sebelum, err := userDikelola(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		if body.Role != models.RoleAdmin {
+			if err := jagaAdminTerakhir(ctx, sebelum, "Tidak bisa menurunkan admin aktif terakhir"); err != nil {
+				return err
+			}
+		}
+
+		user, err = userRepo.Update(ctx, id, repository.UserUpdate{Role: &body.Role})
+		if err != nil {
+			return err
+		}
+		return catatPerubahan(ctx, c, models.AuditRoleUser, models.ResourceUser, id, sebelum, user, nil)
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	user.Password = ""
+	return c.JSON(user)
+}
+
+// UpdateStatusUser godoc
+// @Summary Aktifkan atau nonaktifkan user
+// @Description Menonaktifkan user (semua sesinya langsung dicabut dan token yang masih berlaku ditolak) atau mengaktifkannya kembali. Admin tidak bisa menonaktifkan dirinya sendiri maupun admin aktif terakhir.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param status body object{aktif=bool} true "Status baru"
+// @Success 200 {object} models.User "Status berhasil diubah"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Role user melebihi permission pemanggil"
+// @Failure 404 {object} map[string]interface{} "User tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Admin aktif terakhir"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/status [put]
+func UpdateStatusUser(c *fiber.Ctx) error {
+	var body struct {
+		Aktif *bool `json:"aktif"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Aktif == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "aktif wajib diisi (true atau false)"})
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	nonaktif := !*body.Aktif
+	var user *models.User
+	// Status, audit dan pencabutan sesi disimpan bersama agar user nonaktif
+	// tidak pernah tertinggal dengan sesi yang masih hidup
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		sebelum, err := userDikelola(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		if nonaktif {
+			if id == currentUserID(c) {
+				return fiber.NewError(400, "Tidak bisa menonaktifkan akun sendiri")
+			}
+			if err := jagaAdminTerakhir(ctx, sebelum, "Tidak bisa menonaktifkan admin aktif terakhir"); err != nil {
+				return err
+			}
+		}
+
+		user, err = userRepo.Update(ctx, id, repository.UserUpdate{Nonaktif: &nonaktif})
+		if err != nil {
+			return err
+		}
+		if err := catatPerubahan(ctx, c, models.AuditStatusUser, models.ResourceUser, id, sebelum, user, nil); err != nil {
+			return err
+		}
+		if nonaktif {
+			if _, err := sessionRepo.RevokeByUser(ctx, id, nil, models.SesiUserNonaktif); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	user.Password = ""
+	return c.JSON(user)
+}
+
+// ResetPasswordUser godoc
+// @Summary Reset password user
+// @Description Mengganti password user dengan password sementara acak, mencabut semua sesinya dan menandai user harus mengganti password. Password sementara hanya ditampilkan sekali di response ini.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "Password berhasil direset"
+// @Failure 400 {object} map[string]interface{} "ID tidak valid"
+// @Failure 403 {object} map[string]interface{} "Role user melebihi permission pemanggil"
+// @Failure 404 {object} map[string]interface{} "User tidak ditemukan"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/reset-password [post]
+func ResetPasswordUser(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	random := make([]byte, 9)
+	if _, err := rand.Read(random); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat password sementara"})
+	}
+	passwordSementara := base64.RawURLEncoding.EncodeToString(random)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(passwordSementara), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengenkripsi password"})
+	}
+
+	password, harusGanti := string(hashedPassword), true
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		sebelum, err := userDikelola(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		user, err := userRepo.Update(ctx, id, repository.UserUpdate{Password: &password, HarusGantiPassword: &harusGanti})
+		if err != nil {
+			return err
+		}
+		// Hash password tidak pernah masuk snapshot, jadi perubahannya hanya terlihat dari aksi ini
+		if err := catatPerubahan(ctx, c, models.AuditResetPasswordUser, models.ResourceUser, id, sebelum, user, nil); err != nil {
+			return err
+		}
+		_, err = sessionRepo.RevokeByUser(ctx, id, nil, models.SesiResetAdmin)
+		return err
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":            "Password berhasil direset",
+		"password_sementara": passwordSementara,
+	})
+}
+
+// DeleteUser godoc
+// @Summary Delete user
+// @Description Menghapus user dan mencabut semua sesinya. Admin tidak bisa menghapus dirinya sendiri maupun admin aktif terakhir.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "User berhasil dihapus"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Role user melebihi permission pemanggil"
+// @Failure 404 {object} map[string]interface{} "User tidak ditemukan"
+// @Failure 409 {object} map[string]interface{} "Admin aktif terakhir"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id} [delete]
+func DeleteUser(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	err = txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		user, err := userDikelola(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		if id == currentUserID(c) {
+			return fiber.NewError(400, "Tidak bisa menghapus akun sendiri")
+		}
+		if err := jagaAdminTerakhir(ctx, user, "Tidak bisa menghapus admin aktif terakhir"); err != nil {
+			return err
+		}
+
+		if err := userRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := catatPerubahan(ctx, c, models.AuditHapusUser, models.ResourceUser, id, user, nil, nil); err != nil {
+			return err
+		}
+		_, err = sessionRepo.RevokeByUser(ctx, id, nil, models.SesiUserDihapus)
+		return err
+	})
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "User berhasil dihapus"})
+}
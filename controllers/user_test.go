@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"inventory-backend/models"
+	"inventory-backend/repository"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCreateUserEmailBedaKapitalDitolak memastikan email yang hanya berbeda
+// huruf besar/kecil ditolak oleh index unik repository dengan status 400,
+// walaupun lolos pengecekan cekAkunUnik.
+func TestCreateUserEmailBedaKapitalDitolak(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	if err := SeedRoles(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	lama := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: models.RoleUser}
+	if err := userRepo.Create(ctx, &lama); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Post("/users", func(c *fiber.Ctx) error {
+		c.Locals("permissions", []string{models.PermUsersManage, models.PermPeminjamanCreate})
+		return c.Next()
+	}, CreateUser)
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"username":"budi2","email":"Budi@Example.com","password":"rahasia123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != 400 || body["error"] != "Email sudah terdaftar" {
+		t.Fatalf("status = %d, body = %v, ingin 400 Email sudah terdaftar", resp.StatusCode, body)
+	}
+	if _, total, _ := userRepo.FindAll(ctx, repository.UserFilter{}, repository.ListOptions{}); total != 1 {
+		t.Errorf("jumlah user = %d, ingin 1", total)
+	}
+}
+
+func TestKelolaUserDenganRoleLebihTinggiDitolak(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	if err := SeedRoles(ctx); err != nil {
+		t.Fatal(err)
+	}
+	admin := models.User{ID: primitive.NewObjectID(), Username: "admin", Email: "admin@example.com", Role: models.RoleAdmin}
+	biasa := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: models.RoleUser}
+	for _, u := range []*models.User{&admin, &biasa} {
+		if err := userRepo.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Manajer hanya memegang users:manage dan permission role user
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", primitive.NewObjectID().Hex())
+		c.Locals("permissions", []string{models.PermUsersManage, models.PermPeminjamanCreate})
+		return c.Next()
+	})
+	app.Put("/users/:id/role", UpdateRoleUser)
+	app.Put("/users/:id/status", UpdateStatusUser)
+	app.Post("/users/:id/reset-password", ResetPasswordUser)
+	app.Post("/users/:id/unlock", UnlockUser)
+	app.Post("/users/:id/reset-2fa", Reset2FAUser)
+	app.Delete("/users/:id", DeleteUser)
+
+	permintaan := []struct{ method, path, body string }{
+		{"PUT", "/role", `{"role":"user"}`},
+		{"PUT", "/status", `{"aktif":false}`},
+		{"POST", "/reset-password", ``},
+		{"POST", "/unlock", ``},
+		{"POST", "/reset-2fa", ``},
+		{"DELETE", "", ``},
+	}
+	kirim := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	for _, p := range permintaan {
+		if status := kirim(p.method, "/users/"+admin.ID.Hex()+p.path, p.body); status != 403 {
+			t.Errorf("%s admin%s: status = %d, ingin 403", p.method, p.path, status)
+		}
+	}
+	tersimpan, err := userRepo.FindByID(ctx, admin.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tersimpan.Role != models.RoleAdmin || tersimpan.Nonaktif || tersimpan.HarusGantiPassword {
+		t.Errorf("akun admin berubah: %+v", tersimpan)
+	}
+
+	for _, p := range permintaan {
+		if status := kirim(p.method, "/users/"+biasa.ID.Hex()+p.path, p.body); status != 200 {
+			t.Errorf("%s user%s: status = %d, ingin 200", p.method, p.path, status)
+		}
+	}
+}
+
+// sessionGagal menggagalkan pencabutan sesi untuk menguji rollback transaksi
+type sessionGagal struct {
+	repository.SessionRepository
+}
+
+func (sessionGagal) RevokeByUser(context.Context, primitive.ObjectID, *primitive.ObjectID, string) (int64, error) {
+	return 0, errors.New("gagal mencabut sesi")
+}
+
+func TestNonaktifkanUserAtomik(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	ctx := context.Background()
+	user := models.User{ID: primitive.NewObjectID(), Username: "budi", Email: "budi@example.com", Role: models.RoleUser}
+	if err := repos.User.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	session := models.Session{ID: primitive.NewObjectID(), UserID: user.ID, RefreshTokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repos.Session.Create(ctx, &session); err != nil {
+		t.Fatal(err)
+	}
+
+	nonaktifkan := func() int {
+		app := fiber.New()
+		app.Put("/users/:id/status", func(c *fiber.Ctx) error {
+			c.Locals("user_id", primitive.NewObjectID().Hex())
+			c.Locals("permissions", []string{models.PermUsersManage})
+			return c.Next()
+		}, UpdateStatusUser)
+		req := httptest.NewRequest("PUT", "/users/"+user.ID.Hex()+"/status", strings.NewReader(`{"aktif":false}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	// Pencabutan sesi gagal: status dan audit ikut dibatalkan
+	gagal := *repos
+	gagal.Session = sessionGagal{repos.Session}
+	SetRepositories(&gagal)
+	if status := nonaktifkan(); status != 500 {
+		t.Fatalf("status = %d, ingin 500", status)
+	}
+	if tersimpan, _ := userRepo.FindByID(ctx, user.ID); tersimpan.Nonaktif {
+		t.Error("user tetap dinonaktifkan walau sesinya gagal dicabut")
+	}
+	if _, total, _ := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: models.AuditStatusUser}, repository.ListOptions{}); total != 0 {
+		t.Errorf("audit log tercatat %d kali untuk perubahan yang dibatalkan", total)
+	}
+
+	SetRepositories(repos)
+	if status := nonaktifkan(); status != 200 {
+		t.Fatalf("status = %d, ingin 200", status)
+	}
+	if aktif, _ := sessionRepo.FindAktifByUser(ctx, user.ID); len(aktif) != 0 {
+		t.Errorf("sesi aktif = %d, ingin 0", len(aktif))
+	}
+	if _, total, _ := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: models.AuditStatusUser}, repository.ListOptions{}); total != 1 {
+		t.Errorf("audit log = %d, ingin 1", total)
+	}
+}
+
+// TestAdminSalingMenurunkanParalel menurunkan dua admin aktif terakhir secara
+// bersamaan. Hanya satu yang boleh berhasil agar selalu tersisa satu admin.
+func TestAdminSalingMenurunkanParalel(t *testing.T) {
+	SetRepositories(repository.NewMemoryRepositories())
+	ctx := context.Background()
+	if err := SeedRoles(ctx); err != nil {
+		t.Fatal(err)
+	}
+	admin := []models.User{
+		{ID: primitive.NewObjectID(), Username: "admin1", Email: "admin1@example.com", Role: models.RoleAdmin},
+		{ID: primitive.NewObjectID(), Username: "admin2", Email: "admin2@example.com", Role: models.RoleAdmin},
+	}
+	for i := range admin {
+		if err := userRepo.Create(ctx, &admin[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	role, err := roleRepo.FindByNama(ctx, models.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Setiap admin menurunkan admin yang lain
+	var wg sync.WaitGroup
+	status := make([]int, len(admin))
+	for i := range admin {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			app := fiber.New()
+			app.Put("/users/:id/role", func(c *fiber.Ctx) error {
+				c.Locals("user_id", admin[i].ID.Hex())
+				c.Locals("permissions", role.Permissions)
+				return c.Next()
+			}, UpdateRoleUser)
+			target := admin[1-i].ID.Hex()
+			req := httptest.NewRequest("PUT", "/users/"+target+"/role", strings.NewReader(`{"role":"user"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			status[i] = resp.StatusCode
+		}(i)
+	}
+	wg.Wait()
+
+	if !(status[0] == 200 && status[1] == 409 || status[0] == 409 && status[1] == 200) {
+		t.Errorf("status = %v, ingin satu 200 dan satu 409", status)
+	}
+	aktif := false
+	if _, total, _ := userRepo.FindAll(ctx, repository.UserFilter{Role: models.RoleAdmin, Nonaktif: &aktif}, repository.ListOptions{}); total != 1 {
+		t.Errorf("admin aktif = %d, ingin 1", total)
+	}
+}
+
+func TestBuatUserAtomik(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	SetRepositories(repos)
+	ctx := context.Background()
+	if err := SeedRoles(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Audit gagal: user dari CreateUser maupun Register tidak boleh tersimpan
+	gagal := *repos
+	gagal.AuditLog = auditGagal{repos.AuditLog}
+	SetRepositories(&gagal)
+
+	app := appPengguna(primitive.NewObjectID(), models.PermUsersManage, models.PermPeminjamanCreate)
+	app.Post("/users", CreateUser)
+	app.Post("/auth/register", func(c *fiber.Ctx) error {
+		c.Locals("userData", models.RegisterRequest{Username: "sari", Email: "sari@example.com", Password: "rahasia123", Role: models.RoleUser})
+		return c.Next()
+	}, Register)
+
+	if status, body := kirimJSON(t, app, "POST", "/users", `{"username":"budi","email":"budi@example.com","password":"rahasia123"}`); status != 500 {
+		t.Errorf("CreateUser: status = %d, body = %v, ingin 500", status, body)
+	}
+	if status, body := kirimJSON(t, app, "POST", "/auth/register", ""); status != 500 {
+		t.Errorf("Register: status = %d, body = %v, ingin 500", status, body)
+	}
+	if _, total, _ := userRepo.FindAll(ctx, repository.UserFilter{}, repository.ListOptions{}); total != 0 {
+		t.Errorf("jumlah user = %d, ingin 0 karena audit gagal", total)
+	}
+
+	SetRepositories(repos)
+	if status, body := kirimJSON(t, app, "POST", "/users", `{"username":"budi","email":"budi@example.com","password":"rahasia123"}`); status != 201 {
+		t.Fatalf("CreateUser: status = %d, body = %v, ingin 201", status, body)
+	}
+	if _, total, _ := auditRepo.FindAll(ctx, repository.AuditFilter{Aksi: models.AuditBuatUser}, repository.ListOptions{}); total != 1 {
+		t.Errorf("audit buat user = %d, ingin 1", total)
+	}
+}
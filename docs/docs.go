@@ -24,40 +24,100 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Login dengan email dan password, return JWT token",
-                "consumes": [
-                    "application/json"
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Mengambil audit log, yang terbaru lebih dulu. Catatan perubahan data berisi snapshot sebelum dan sesudah serta field yang berubah. Semua catatan dari satu request bisa diambil dengan request_id (header X-Request-ID). Nama, email dan telepon peminjam di catatan peminjaman disamarkan tanpa permission laporan:pii.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Audit"
                 ],
-                "summary": "Login user",
+                "summary": "Daftar audit log",
                 "parameters": [
                     {
-                        "description": "Data login",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Filter pelaku",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter aksi, mis. barang.diubah",
+                        "name": "aksi",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter jenis resource: barang, kategori, peminjaman, user atau role",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter ID resource",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter request ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal awal (YYYY-MM-DD, inklusif)",
+                        "name": "dari",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal akhir (YYYY-MM-DD, inklusif)",
+                        "name": "sampai",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID audit log terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login berhasil",
+                        "description": "Daftar audit log",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -73,24 +133,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/profile": {
-            "get": {
+        "/auth/2fa/disable": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mendapatkan data profile user yang sedang login",
+                "description": "Menonaktifkan 2FA dengan password dan kode TOTP atau kode pemulihan. Tidak bisa dilakukan jika role user wajib memakai 2FA.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Two-Factor Authentication"
+                ],
+                "summary": "Nonaktifkan 2FA",
+                "parameters": [
+                    {
+                        "description": "Password dan kode",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Disable2FARequest"
+                        }
+                    }
                 ],
-                "summary": "Get user profile",
                 "responses": {
                     "200": {
-                        "description": "Profile berhasil diambil",
+                        "description": "2FA dinonaktifkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Password atau kode salah",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -103,8 +184,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "User tidak ditemukan",
+                    "403": {
+                        "description": "Role wajib 2FA",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -113,9 +201,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
+        "/auth/2fa/enable": {
             "post": {
-                "description": "Mendaftarkan user baru dengan username, email, password, dan role",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengonfirmasi kode dari aplikasi authenticator lalu mengaktifkan 2FA. Semua sesi lain dicabut dan sesi baru yang sudah terverifikasi 2FA dikembalikan beserta kode pemulihan yang hanya ditampilkan sekali.",
                 "consumes": [
                     "application/json"
                 ],
@@ -123,71 +216,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Two-Factor Authentication"
                 ],
-                "summary": "Register user baru",
+                "summary": "Aktifkan 2FA",
                 "parameters": [
                     {
-                        "description": "Data user baru",
-                        "name": "user",
+                        "description": "Kode TOTP",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.RegisterRequest"
+                            "$ref": "#/definitions/controllers.KodeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User berhasil didaftarkan",
+                    "200": {
+                        "description": "2FA aktif",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Setup belum dilakukan atau kode salah",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/barang": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mengambil semua data barang",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Barang"
-                ],
-                "summary": "Get all barang",
-                "responses": {
-                    "200": {
-                        "description": "List semua barang",
+                    },
+                    "409": {
+                        "description": "2FA sudah aktif",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Barang"
-                            }
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "500": {
@@ -198,14 +267,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/2fa/recovery-codes": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Membuat data barang baru",
+                "description": "Mengganti semua kode pemulihan dengan yang baru. Membutuhkan kode TOTP dari aplikasi authenticator.",
                 "consumes": [
                     "application/json"
                 ],
@@ -213,29 +284,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Barang"
+                    "Two-Factor Authentication"
                 ],
-                "summary": "Create new barang",
+                "summary": "Buat ulang kode pemulihan",
                 "parameters": [
                     {
-                        "description": "Data barang baru",
-                        "name": "barang",
+                        "description": "Kode TOTP",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Barang"
+                            "$ref": "#/definitions/controllers.KodeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Barang berhasil dibuat",
+                    "200": {
+                        "description": "Kode pemulihan baru",
                         "schema": {
-                            "$ref": "#/definitions/models.Barang"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "2FA belum aktif atau kode salah",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -251,63 +330,56 @@ const docTemplate = `{
                 }
             }
         },
-        "/barang/{id}": {
-            "get": {
+        "/auth/2fa/setup": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil data barang berdasarkan ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Membuat secret TOTP baru dan mengembalikan URI otpauth untuk dipindai aplikasi authenticator. 2FA baru aktif setelah kode pertama dikonfirmasi lewat /auth/2fa/enable.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Barang"
-                ],
-                "summary": "Get barang by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Barang ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Two-Factor Authentication"
                 ],
+                "summary": "Mulai pendaftaran 2FA",
                 "responses": {
                     "200": {
-                        "description": "Data barang",
+                        "description": "Secret dan URI otpauth",
                         "schema": {
-                            "$ref": "#/definitions/models.Barang"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "ID tidak valid",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Barang tidak ditemukan",
+                    "409": {
+                        "description": "2FA sudah aktif",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mengupdate data barang berdasarkan ID",
+            }
+        },
+        "/auth/2fa/verify": {
+            "post": {
+                "description": "Langkah kedua login untuk user yang mengaktifkan 2FA. Menukar challenge token dari /auth/login dan kode TOTP (atau kode pemulihan) dengan access token dan refresh token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -315,30 +387,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Barang"
+                    "Two-Factor Authentication"
                 ],
-                "summary": "Update barang",
+                "summary": "Verifikasi kode 2FA saat login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Barang ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Data barang yang akan diupdate",
-                        "name": "barang",
+                        "description": "Challenge token dan kode",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Barang"
+                            "$ref": "#/definitions/controllers.Verify2FARequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Barang berhasil diupdate",
+                        "description": "Login berhasil",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -351,6 +416,20 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "401": {
+                        "description": "Challenge token atau kode tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak percobaan gagal",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -359,85 +438,39 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/api-keys": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Menghapus data barang berdasarkan ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mengambil semua API key milik user yang sedang login beserta waktu pemakaian terakhir. Kunci tidak pernah ditampilkan ulang.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Barang"
-                ],
-                "summary": "Delete barang",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Barang ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "API Keys"
                 ],
+                "summary": "Daftar API key",
                 "responses": {
                     "200": {
-                        "description": "Barang berhasil dihapus",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "ID tidak valid",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.APIKey"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/kategori": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mengambil semua data kategori",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Kategori"
-                ],
-                "summary": "Get all kategori",
-                "responses": {
-                    "200": {
-                        "description": "List semua kategori",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Kategori"
-                            }
-                        }
                     },
                     "500": {
                         "description": "Internal server error",
@@ -454,7 +487,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Membuat data kategori baru",
+                "description": "Membuat API key untuk script dan integrasi, dipakai lewat header X-API-Key. Scope hanya boleh berisi permission yang dimiliki role user. Kunci hanya ditampilkan sekali di response ini.",
                 "consumes": [
                     "application/json"
                 ],
@@ -462,25 +495,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Kategori"
+                    "API Keys"
                 ],
-                "summary": "Create new kategori",
+                "summary": "Buat API key",
                 "parameters": [
                     {
-                        "description": "Data kategori baru",
-                        "name": "kategori",
+                        "description": "Nama, scope dan waktu kedaluwarsa",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Kategori"
+                            "$ref": "#/definitions/models.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Kategori berhasil dibuat",
+                        "description": "API key berhasil dibuat",
                         "schema": {
-                            "$ref": "#/definitions/models.Kategori"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -490,6 +524,20 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Scope melebihi permission user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -500,28 +548,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/kategori/{id}": {
-            "get": {
+        "/auth/api-keys/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil data kategori berdasarkan ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mencabut API key milik user yang sedang login; request dengan kunci ini langsung ditolak",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Kategori"
+                    "API Keys"
                 ],
-                "summary": "Get kategori by ID",
+                "summary": "Cabut API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Kategori ID",
+                        "description": "API key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -529,9 +574,10 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data kategori",
+                        "description": "API key dicabut",
                         "schema": {
-                            "$ref": "#/definitions/models.Kategori"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -541,22 +587,33 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
-                        "description": "Kategori tidak ditemukan",
+                        "description": "API key tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mengupdate data kategori berdasarkan ID",
+            }
+        },
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Mengirim token reset password ke email jika email terdaftar. Response selalu sama agar tidak bisa dipakai untuk menebak email yang terdaftar.",
                 "consumes": [
                     "application/json"
                 ],
@@ -564,30 +621,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Kategori"
+                    "Authentication"
                 ],
-                "summary": "Update kategori",
+                "summary": "Lupa password",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Kategori ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Data kategori yang akan diupdate",
-                        "name": "kategori",
+                        "description": "Email akun",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Kategori"
+                            "$ref": "#/definitions/controllers.ForgotPasswordRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Kategori berhasil diupdate",
+                        "description": "Permintaan diterima",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -599,23 +649,13 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Menghapus data kategori berdasarkan ID",
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login dengan email dan password. Mengembalikan access token berumur pendek dan refresh token untuk /auth/refresh. Login gagal berulang per akun dan per IP diberi jeda progresif lalu dikunci sementara (429 dengan header Retry-After). Jika user mengaktifkan 2FA, response berisi challenge_token yang harus ditukar lewat /auth/2fa/verify.",
                 "consumes": [
                     "application/json"
                 ],
@@ -623,69 +663,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Kategori"
+                    "Authentication"
                 ],
-                "summary": "Delete kategori",
+                "summary": "Login user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Kategori ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Data login",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Kategori berhasil dihapus",
+                        "description": "Login berhasil",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "ID tidak valid",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Akun dinonaktifkan",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/laporan/peminjaman": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mengambil laporan peminjaman dengan detail barang dan kategori",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Laporan"
-                ],
-                "summary": "Get laporan peminjaman",
-                "responses": {
-                    "200": {
-                        "description": "Laporan peminjaman lengkap",
+                    },
+                    "429": {
+                        "description": "Terlalu banyak login gagal",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object"
-                            }
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "500": {
@@ -698,95 +716,91 @@ const docTemplate = `{
                 }
             }
         },
-        "/peminjaman": {
+        "/auth/login-history": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil semua data peminjaman dengan opsi pencarian exact match nama peminjam",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mengambil riwayat login, login gagal, refresh dan logout user yang sedang login, yang terbaru lebih dulu",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Peminjaman"
+                    "Authentication"
                 ],
-                "summary": "Get all peminjaman",
+                "summary": "Riwayat login sendiri",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Pencarian exact match berdasarkan nama peminjam (case-insensitive)",
-                        "name": "search",
+                        "description": "ID riwayat terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Daftar peminjaman",
+                        "description": "Riwayat login",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Peminjaman"
-                            }
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "500": {
-                        "description": "Terjadi kesalahan server",
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/logout": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Membuat data peminjaman baru",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mencabut sesi yang sedang dipakai sehingga access token dan refresh token sesi ini tidak berlaku lagi",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Peminjaman"
-                ],
-                "summary": "Create new peminjaman",
-                "parameters": [
-                    {
-                        "description": "Data peminjaman baru",
-                        "name": "peminjaman",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Peminjaman"
-                        }
-                    }
+                    "Authentication"
                 ],
+                "summary": "Logout",
                 "responses": {
-                    "201": {
-                        "description": "Peminjaman berhasil dibuat",
-                        "schema": {
-                            "$ref": "#/definitions/models.Peminjaman"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request",
+                    "200": {
+                        "description": "Logout berhasil",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Barang tidak ditemukan",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -802,49 +816,106 @@ const docTemplate = `{
                 }
             }
         },
-        "/peminjaman/{id}": {
-            "get": {
+        "/auth/logout-all": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil data peminjaman berdasarkan ID",
-                "consumes": [
+                "description": "Mencabut semua sesi milik user yang sedang login, termasuk sesi saat ini",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Logout dari semua perangkat",
+                "responses": {
+                    "200": {
+                        "description": "Semua sesi berhasil dicabut",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Menyelesaikan login SSO: menukar authorization code, memverifikasi ID token, lalu menghubungkan akun berdasarkan email atau membuat akun baru. Role diambil dari grup provider sesuai OIDC_ROLE_MAPPING. Jika FRONTEND_URL diisi, browser diarahkan ke FRONTEND_URL/oidc/callback dengan token di fragment URL; jika tidak, response sama seperti /auth/login.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Peminjaman"
+                    "Authentication"
                 ],
-                "summary": "Get peminjaman by ID",
+                "summary": "Callback login SSO",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Peminjaman ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Authorization code dari provider",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State dari /auth/oidc/login",
+                        "name": "state",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data peminjaman",
+                        "description": "Login berhasil atau perlu kode 2FA",
                         "schema": {
-                            "$ref": "#/definitions/models.Peminjaman"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
+                    "302": {
+                        "description": "Redirect ke frontend"
+                    },
                     "400": {
-                        "description": "ID tidak valid",
+                        "description": "State tidak valid atau login dibatalkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "ID token tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Email belum diverifikasi provider, akun nonaktif, atau akun belum terdaftar",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Data peminjaman tidak ditemukan",
+                        "description": "SSO tidak dikonfigurasi",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -858,14 +929,44 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Mengarahkan browser ke identity provider OpenID Connect. Setelah login di provider, browser kembali ke /auth/oidc/callback.",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Login SSO",
+                "responses": {
+                    "302": {
+                        "description": "Redirect ke identity provider"
+                    },
+                    "404": {
+                        "description": "SSO tidak dikonfigurasi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Identity provider tidak bisa dihubungi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Menghapus data peminjaman berdasarkan ID",
+                "description": "Mengganti password user yang sedang login dengan memasukkan password lama. Semua sesi lain dicabut; sesi saat ini tetap berlaku.",
                 "consumes": [
                     "application/json"
                 ],
@@ -873,35 +974,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Peminjaman"
+                    "Authentication"
                 ],
-                "summary": "Delete peminjaman",
+                "summary": "Ganti password sendiri",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Peminjaman ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Password lama dan baru",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangePasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data peminjaman berhasil dihapus",
+                        "description": "Password berhasil diganti",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "ID tidak valid",
+                        "description": "Password lama salah atau password baru tidak valid",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Data peminjaman tidak ditemukan",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak percobaan gagal",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -917,14 +1027,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/peminjaman/{id}/status": {
+        "/auth/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan data profile user yang sedang login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Get user profile",
+                "responses": {
+                    "200": {
+                        "description": "Profile berhasil diambil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengupdate status peminjaman (dipinjam/dikembalikan)",
+                "description": "Mengubah username, email, nama lengkap dan telepon user yang sedang login. Field yang tidak dikirim tidak diubah. Mengganti email wajib menyertakan password_sekarang dan email baru harus diverifikasi ulang.",
                 "consumes": [
                     "application/json"
                 ],
@@ -932,49 +1080,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Peminjaman"
+                    "Authentication"
                 ],
-                "summary": "Update status peminjaman",
+                "summary": "Update profile sendiri",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Peminjaman ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Status baru",
-                        "name": "status",
+                        "description": "Data profile",
+                        "name": "profile",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "status": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/models.UpdateProfileRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Status berhasil diperbarui",
+                        "description": "Profile berhasil diperbarui",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Bad request, password salah, atau username/email sudah dipakai",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Data tidak ditemukan",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -989,48 +1125,3545 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Barang": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "kategori_id": {
-                    "type": "string"
-                },
-                "nama": {
-                    "type": "string"
-                },
-                "stok": {
-                    "type": "integer"
-                },
-                "tanggal_buat": {
-                    "type": "string"
-                }
-            }
         },
-        "models.Kategori": {
-            "type": "object",
-            "properties": {
-                "deskripsi": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "nama": {
-                    "type": "string"
-                },
-                "tanggal_buat": {
-                    "type": "string"
+        "/auth/refresh": {
+            "post": {
+                "description": "Menukar refresh token dengan access token dan refresh token baru. Setiap refresh token hanya bisa dipakai sekali; refresh token lama yang dipakai ulang dianggap bocor sehingga seluruh sesinya dicabut.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token berhasil diperbarui",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh token tidak valid atau sesi sudah berakhir",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
                 }
             }
         },
-        "models.LoginRequest": {
-            "type": "object",
+        "/auth/register": {
+            "post": {
+                "description": "Mendaftarkan user baru dengan username, email dan password. Registrasi publik selalu membuat akun dengan role 'user' dan bisa dinonaktifkan dengan REGISTRATION_ENABLED=false. Token verifikasi dikirim ke email; akun yang belum terverifikasi bisa login tetapi belum bisa mengajukan peminjaman.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Register user baru",
+                "parameters": [
+                    {
+                        "description": "Data user baru",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User berhasil didaftarkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Registrasi dinonaktifkan atau role tidak diizinkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Mengganti password memakai token dari email lupa password. Token hanya bisa dipakai sekali dan semua sesi login user dicabut.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Token dan password baru",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password berhasil direset",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Token tidak valid atau password tidak memenuhi syarat",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil semua sesi login user yang masih aktif beserta IP, user agent dan waktu terakhir dipakai. Sesi yang sedang dipakai ditandai saat_ini.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Daftar sesi aktif",
+                "responses": {
+                    "200": {
+                        "description": "Daftar sesi aktif",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mencabut satu sesi milik user, mis. login di perangkat yang hilang. Mencabut sesi yang sedang dipakai sama dengan logout.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Cabut satu sesi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sesi dicabut",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Sesi tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "Menandai email akun sebagai terverifikasi memakai token yang dikirim saat registrasi",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Verifikasi email",
+                "parameters": [
+                    {
+                        "description": "Token verifikasi",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email berhasil diverifikasi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Token tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengirim ulang token verifikasi ke email user yang sedang login. Token sebelumnya tidak berlaku lagi.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Kirim ulang email verifikasi",
+                "responses": {
+                    "200": {
+                        "description": "Email verifikasi dikirim",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Email sudah terverifikasi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Gagal mengirim email",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/barang": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil data barang dengan filter, pencarian, pengurutan dan pagination. Response berisi data, meta (total) dan links ke halaman berikutnya. Dengan q dan tanpa sort, hasil diurutkan dari yang paling relevan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Barang"
+                ],
+                "summary": "Get all barang",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter kategori",
+                        "name": "kategori_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Stok minimal (inklusif)",
+                        "name": "stok_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Stok maksimal (inklusif)",
+                        "name": "stok_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari sebagian nama barang (case-insensitive)",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cara mencocokkan search: contains (bawaan) atau prefix",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pencarian kata kunci atas nama barang (text index)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan: id, nama, stok atau tanggal_buat; awali dengan - untuk menurun",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID barang terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar barang",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat data barang baru",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Barang"
+                ],
+                "summary": "Create new barang",
+                "parameters": [
+                    {
+                        "description": "Data barang baru",
+                        "name": "barang",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Barang"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Barang berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.Barang"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/barang/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil data barang berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Barang"
+                ],
+                "summary": "Get barang by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barang ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data barang",
+                        "schema": {
+                            "$ref": "#/definitions/models.Barang"
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Barang tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengupdate data barang berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Barang"
+                ],
+                "summary": "Update barang",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barang ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data barang yang akan diupdate",
+                        "name": "barang",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Barang"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Barang berhasil diupdate",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Barang tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memindahkan barang ke tempat sampah (soft delete). Barang yang masih dipakai peminjaman aktif tidak bisa dihapus; response 409 mencantumkan peminjaman tersebut. Riwayat peminjaman dan laporan tetap menampilkan barang yang dihapus.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Barang"
+                ],
+                "summary": "Delete barang",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barang ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Barang berhasil dihapus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Barang tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Barang masih dipakai peminjaman aktif",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/barang/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil semua perubahan satu barang dari audit log, yang terbaru lebih dulu, termasuk barang yang sudah dihapus",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Barang"
+                ],
+                "summary": "Riwayat perubahan barang",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barang ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID audit log terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Riwayat perubahan barang",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/barang/{id}/mutasi": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil riwayat mutasi stok sebuah barang, terbaru lebih dulu",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Barang"
+                ],
+                "summary": "Get mutasi stok barang",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barang ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID mutasi terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Riwayat mutasi stok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Barang tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/barang/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengeluarkan barang dari tempat sampah. Kategorinya harus dipulihkan lebih dulu jika ikut terhapus.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Barang"
+                ],
+                "summary": "Restore barang",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barang ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Barang berhasil dipulihkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Barang tidak ada di tempat sampah",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Kategori barang masih terhapus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/kategori": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil data kategori dengan pengurutan dan pagination. Response berisi data, meta (total) dan links ke halaman berikutnya.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kategori"
+                ],
+                "summary": "Get all kategori",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Urutan: id, nama atau tanggal_buat; awali dengan - untuk menurun",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID kategori terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar kategori",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat data kategori baru",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kategori"
+                ],
+                "summary": "Create new kategori",
+                "parameters": [
+                    {
+                        "description": "Data kategori baru",
+                        "name": "kategori",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Kategori"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Kategori berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.Kategori"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/kategori/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil data kategori berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kategori"
+                ],
+                "summary": "Get kategori by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kategori ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data kategori",
+                        "schema": {
+                            "$ref": "#/definitions/models.Kategori"
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Kategori tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengupdate data kategori berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kategori"
+                ],
+                "summary": "Update kategori",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kategori ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data kategori yang akan diupdate",
+                        "name": "kategori",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Kategori"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Kategori berhasil diupdate",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Kategori tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memindahkan kategori ke tempat sampah (soft delete). Kategori yang masih dipakai barang tidak bisa dihapus (409 beserta daftar barangnya) kecuali dengan cascade=true, yang ikut memindahkan barangnya ke tempat sampah, atau reassign_to, yang memindahkan barangnya ke kategori lain. Cascade ditolak jika ada barang yang masih dipakai peminjaman aktif.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kategori"
+                ],
+                "summary": "Delete kategori",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kategori ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Ikut hapus semua barang di kategori ini",
+                        "name": "cascade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID kategori tujuan untuk barang di kategori ini",
+                        "name": "reassign_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Kategori berhasil dihapus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID atau parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Kategori tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Kategori masih dipakai barang",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/kategori/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengeluarkan kategori dari tempat sampah. Barang yang ikut terhapus karena cascade dipulihkan terpisah.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kategori"
+                ],
+                "summary": "Restore kategori",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kategori ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Kategori berhasil dipulihkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Kategori tidak ada di tempat sampah",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/laporan/peminjaman": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil laporan peminjaman dengan detail barang dan kategori. Butuh permission laporan:read; nama, email dan telepon peminjam disamarkan kecuali pemanggil memiliki laporan:pii. Setiap pengambilan laporan dicatat di audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Laporan"
+                ],
+                "summary": "Get laporan peminjaman",
+                "responses": {
+                    "200": {
+                        "description": "Laporan peminjaman lengkap",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Tidak memiliki permission laporan:read",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/laporan/terlambat": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil peminjaman yang masih dipinjam dan sudah melewati tanggal jatuh tempo, paling lama terlambat lebih dulu. Butuh permission laporan:read; data pribadi peminjam disamarkan tanpa laporan:pii. Setiap pengambilan laporan dicatat di audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Laporan"
+                ],
+                "summary": "Get laporan peminjaman terlambat",
+                "responses": {
+                    "200": {
+                        "description": "Daftar peminjaman terlambat",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Tidak memiliki permission laporan:read",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/peminjaman": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil data peminjaman dengan pencarian parsial atas nama, email dan telepon peminjam serta nama barang. User dengan permission peminjaman:read_all melihat semua peminjaman, user lain hanya peminjaman miliknya sendiri.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Get all peminjaman",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cari di nama, email, telepon peminjam dan nama barang (case-insensitive)",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cara mencocokkan search: contains (bawaan) atau prefix",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status, boleh beberapa dipisah koma (mis. dipinjam,disetujui)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter peminjaman yang memuat barang ini",
+                        "name": "barang_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter email peminjam (exact match, case-insensitive)",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal pinjam mulai (YYYY-MM-DD, inklusif)",
+                        "name": "dari",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal pinjam sampai (YYYY-MM-DD, inklusif)",
+                        "name": "sampai",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan: id, tanggal_pinjam, tanggal_jatuh_tempo, status atau nama_peminjam; awali dengan - untuk menurun",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID peminjaman terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar peminjaman",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Terjadi kesalahan server",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengajukan peminjaman baru dengan status 'diajukan' untuk satu atau beberapa barang (items). Semua barang harus tersedia; stok belum dikurangi sampai peminjaman disetujui admin. Format lama dengan barang_id dan jumlah tetap diterima.\nPeminjaman otomatis terikat ke akun pengaju dan nama, email serta telepon peminjam diambil dari akun; telepon boleh kosong jika akun belum mengisinya. User dengan permission peminjaman:create_for_others boleh mengisi user_id untuk meminjam atas nama user lain, atau mengosongkannya dan mengisi nama_peminjam, email_peminjam dan telepon_peminjam untuk peminjam tamu.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Create new peminjaman",
+                "parameters": [
+                    {
+                        "description": "Data peminjaman baru",
+                        "name": "peminjaman",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Peminjaman"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Peminjaman berhasil diajukan",
+                        "schema": {
+                            "$ref": "#/definitions/models.Peminjaman"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Barang tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/peminjaman/saya": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil peminjaman yang terikat ke akun user yang sedang login. Filter, pengurutan dan pagination sama seperti GET /peminjaman.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Get peminjaman milik sendiri",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cari di nama, email, telepon peminjam dan nama barang",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cara mencocokkan search: contains (bawaan) atau prefix",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status, boleh beberapa dipisah koma",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan, lihat GET /peminjaman",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID peminjaman terakhir halaman sebelumnya",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar peminjaman milik user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Terjadi kesalahan server",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/peminjaman/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil data peminjaman berdasarkan ID. Tanpa permission peminjaman:read_all user hanya bisa melihat peminjaman miliknya sendiri.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Get peminjaman by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Peminjaman ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data peminjaman",
+                        "schema": {
+                            "$ref": "#/definitions/models.Peminjaman"
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Data peminjaman tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memindahkan status peminjaman sesuai alur: diajukan -\u003e disetujui/ditolak/dibatalkan, disetujui -\u003e dipinjam/dibatalkan, dipinjam -\u003e dikembalikan. Stok dipesan saat disetujui dan dikembalikan saat dibatalkan atau dikembalikan. Alasan wajib diisi saat menolak.\nMenandai dikembalikan butuh permission peminjaman:return, perpindahan lain butuh peminjaman:approve. Pemilik peminjaman boleh membatalkan pengajuannya sendiri selama masih berstatus diajukan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Update status peminjaman",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Peminjaman ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status baru dan alasan",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "alasan": {
+                                    "type": "string"
+                                },
+                                "status": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status berhasil diperbarui",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Tidak punya permission untuk perpindahan status ini",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Data tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Perpindahan status tidak diizinkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus data peminjaman berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Delete peminjaman",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Peminjaman ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data peminjaman berhasil dihapus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Data peminjaman tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/peminjaman/{id}/jumlah": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah jumlah satu barang di peminjaman berstatus diajukan, disetujui atau dipinjam. Untuk status disetujui dan dipinjam stok barang ikut disesuaikan. barang_id boleh dikosongkan jika peminjaman hanya berisi satu barang. Jumlah tidak boleh kurang dari unit yang sudah dikembalikan; jika jumlah diturunkan sampai semua unit tercatat kembali, peminjaman otomatis berstatus dikembalikan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Ubah jumlah item peminjaman",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Peminjaman ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Barang dan jumlah baru",
+                        "name": "jumlah",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "barang_id": {
+                                    "type": "string"
+                                },
+                                "jumlah": {
+                                    "type": "integer"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Jumlah berhasil diubah atau tidak berubah",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, barang tidak ada di peminjaman, jumlah kurang dari yang sudah dikembalikan, atau stok tidak mencukupi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Data peminjaman tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Status peminjaman sudah selesai (ditolak, dikembalikan atau dibatalkan)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/peminjaman/{id}/pengembalian": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mencatat pengembalian sebagian atau seluruh unit, boleh dalam beberapa kali, dengan kondisi baik, rusak_ringan, rusak_berat atau hilang. Hanya unit berkondisi baik yang kembali ke stok; unit rusak dan hilang dicatat di stok_rusak dan stok_hilang barang. Peminjaman otomatis selesai ('dikembalikan') jika semua unit sudah tercatat kembali.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Catat pengembalian peminjaman",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Peminjaman ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Item yang dikembalikan",
+                        "name": "pengembalian",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PengembalianRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Peminjaman setelah pengembalian",
+                        "schema": {
+                            "$ref": "#/definitions/models.Peminjaman"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Data peminjaman tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Peminjaman tidak sedang dipinjam",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/peminjaman/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengeluarkan peminjaman dari tempat sampah. Peminjaman berstatus disetujui atau dipinjam memesan stoknya lagi, sehingga ditolak jika stok tidak cukup. Semua barangnya harus sudah dipulihkan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Peminjaman"
+                ],
+                "summary": "Restore peminjaman",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Peminjaman ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Peminjaman berhasil dipulihkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid atau stok tidak mencukupi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Peminjaman tidak ada di tempat sampah",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Barang peminjaman masih terhapus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil semua role beserta permission-nya",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Get all roles",
+                "responses": {
+                    "200": {
+                        "description": "Daftar role",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Role"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat role baru dengan kumpulan permission. Nama role tidak bisa diubah setelah dibuat. Permission hanya boleh berisi permission yang juga dimiliki pemanggil.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Create role",
+                "parameters": [
+                    {
+                        "description": "Data role baru",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Role berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Permission melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Nama role sudah dipakai",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil semua permission yang dikenal beserta keterangannya",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Get all permissions",
+                "responses": {
+                    "200": {
+                        "description": "Daftar permission",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah deskripsi dan permission role. Permission role admin tidak bisa diubah. Pemanggil harus memiliki semua permission lama dan permission baru role tersebut.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Update role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Deskripsi dan permission baru (nama diabaikan)",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role berhasil diubah",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Permission role melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Role admin tidak bisa diubah",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus role. Role bawaan sistem, role yang masih dipakai user dan role dengan permission yang tidak dimiliki pemanggil tidak bisa dihapus.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Delete role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role berhasil dihapus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Permission role melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Role sistem atau masih dipakai",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/stok/rekonsiliasi": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menampilkan barang yang field stok-nya tidak sama dengan jumlah ledger mutasi stok",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stok"
+                ],
+                "summary": "Rekonsiliasi stok",
+                "responses": {
+                    "200": {
+                        "description": "Daftar barang yang tidak sinkron",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menambahkan mutasi 'correction' agar ledger sama dengan stok saat ini (misalnya untuk barang lama sebelum ada ledger). Stok barang tidak diubah.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stok"
+                ],
+                "summary": "Koreksi ledger stok",
+                "responses": {
+                    "200": {
+                        "description": "Ledger berhasil dikoreksi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil barang, kategori dan peminjaman yang sudah dihapus (soft delete), terbaru dihapus lebih dulu. Setiap jenis dipaginasi sendiri dengan page dan limit yang sama. Butuh permission trash:manage.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Get tempat sampah",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Jenis data, boleh beberapa dipisah koma: barang, kategori, peminjaman (default semua)",
+                        "name": "jenis",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan: id atau deleted_at; awali dengan - untuk menurun (default -deleted_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Isi tempat sampah per jenis",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Tidak memiliki permission trash:manage",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil daftar user dengan pencarian username/email, filter role dan status, serta pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get all users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cari potongan username atau email (case-insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter nama role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status: aktif atau nonaktif",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan: id, username, email atau created_at; awali dengan - untuk menurun",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID user terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat user baru dengan role yang sudah terdaftar (default 'user'). Role hanya boleh berisi permission yang juga dimiliki pemanggil.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "Data user baru",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Role melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/unlock-ip": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus hitungan login gagal dan kunci sementara pada satu alamat IP",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Buka kunci login IP",
+                "parameters": [
+                    {
+                        "description": "Alamat IP",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "ip": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Kunci login dibuka",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil data satu user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data user",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus user dan mencabut semua sesinya. Admin tidak bisa menghapus dirinya sendiri maupun admin aktif terakhir.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User berhasil dihapus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Role user melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Admin aktif terakhir",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/login-history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil riwayat login, login gagal, refresh dan logout satu user untuk review keamanan, yang terbaru lebih dulu",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Riwayat login user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID riwayat terakhir halaman sebelumnya (keyset pagination, menggantikan page)",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Riwayat login",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reset-2fa": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus 2FA user yang kehilangan aplikasi authenticator dan kode pemulihannya, lalu mencabut semua sesinya. User dengan role wajib 2FA harus mendaftarkan ulang setelah login.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Reset 2FA user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "2FA direset",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid atau akun sendiri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Role user melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reset-password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengganti password user dengan password sementara acak, mencabut semua sesinya dan menandai user harus mengganti password. Password sementara hanya ditampilkan sekali di response ini.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Reset password user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password berhasil direset",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Role user melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah role user ke role lain yang sudah terdaftar. Role lama dan role baru hanya boleh berisi permission yang juga dimiliki pemanggil. Admin aktif terakhir tidak bisa diturunkan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update role user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role baru",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "role": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role berhasil diubah",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Role lama atau role baru melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Admin aktif terakhir",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menonaktifkan user (semua sesinya langsung dicabut dan token yang masih berlaku ditolak) atau mengaktifkannya kembali. Admin tidak bisa menonaktifkan dirinya sendiri maupun admin aktif terakhir.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Aktifkan atau nonaktifkan user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status baru",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "aktif": {
+                                    "type": "boolean"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status berhasil diubah",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Role user melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Admin aktif terakhir",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus hitungan login gagal dan kunci sementara pada akun user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Buka kunci login user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Kunci login dibuka",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Role user melebihi permission pemanggil",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.CreateUserRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.Disable2FARequest": {
+            "type": "object",
+            "properties": {
+                "kode": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ItemPengembalian": {
+            "type": "object",
+            "properties": {
+                "barang_id": {
+                    "type": "string"
+                },
+                "catatan": {
+                    "type": "string"
+                },
+                "jumlah": {
+                    "type": "integer"
+                },
+                "kondisi": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.KodeRequest": {
+            "type": "object",
+            "properties": {
+                "kode": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.PengembalianRequest": {
+            "type": "object",
+            "properties": {
+                "barang_ids": {
+                    "description": "BarangIDs adalah bentuk singkat: seluruh sisa unit barang tersebut kembali dalam kondisi baik",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ItemPengembalian"
+                    }
+                }
+            }
+        },
+        "controllers.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.RoleRequest": {
+            "type": "object",
+            "properties": {
+                "deskripsi": {
+                    "type": "string"
+                },
+                "nama": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.Verify2FARequest": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "kode": {
+                    "description": "kode TOTP 6 digit atau kode pemulihan",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.VerifyEmailRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "nil berarti tidak kedaluwarsa",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "type": "string"
+                },
+                "nama": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Barang": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "deleted_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kategori_id": {
+                    "type": "string"
+                },
+                "nama": {
+                    "type": "string"
+                },
+                "stok": {
+                    "type": "integer"
+                },
+                "stok_hilang": {
+                    "description": "unit hilang, tidak termasuk stok",
+                    "type": "integer"
+                },
+                "stok_rusak": {
+                    "description": "unit kembali rusak, tidak termasuk stok",
+                    "type": "integer"
+                },
+                "tanggal_buat": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password_baru": {
+                    "type": "string"
+                },
+                "password_lama": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "RFC 3339, kosong berarti tidak kedaluwarsa",
+                    "type": "string"
+                },
+                "nama": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ItemPeminjaman": {
+            "type": "object",
+            "properties": {
+                "barang_id": {
+                    "type": "string"
+                },
+                "jumlah": {
+                    "type": "integer"
+                },
+                "jumlah_kembali": {
+                    "type": "integer"
+                },
+                "tanggal_kembali": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Kategori": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "deleted_by": {
+                    "type": "string"
+                },
+                "deskripsi": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "nama": {
+                    "type": "string"
+                },
+                "tanggal_buat": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
             "required": [
                 "email",
                 "password"
@@ -1048,28 +4681,92 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "barang_id": {
+                    "description": "Format lama dengan satu barang per peminjaman. Hanya dipakai saat membaca\ndata lama atau request lama, lalu diubah menjadi Items oleh Normalisasi.",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "deleted_by": {
                     "type": "string"
                 },
                 "email_peminjam": {
                     "type": "string"
                 },
+                "hari_terlambat": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "string"
                 },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItemPeminjaman"
+                    }
+                },
                 "jumlah": {
                     "type": "integer"
                 },
                 "nama_peminjam": {
                     "type": "string"
                 },
+                "pengembalian": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Pengembalian"
+                    }
+                },
+                "riwayat_status": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RiwayatStatus"
+                    }
+                },
                 "status": {
                     "type": "string"
                 },
+                "tanggal_jatuh_tempo": {
+                    "type": "string"
+                },
+                "tanggal_kembali": {
+                    "type": "string"
+                },
                 "tanggal_pinjam": {
                     "type": "string"
                 },
                 "telepon_peminjam": {
                     "type": "string"
+                },
+                "terlambat": {
+                    "description": "Field turunan, dihitung saat dibaca dan tidak disimpan",
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Pengembalian": {
+            "type": "object",
+            "properties": {
+                "barang_id": {
+                    "type": "string"
+                },
+                "catatan": {
+                    "type": "string"
+                },
+                "diterima_oleh": {
+                    "type": "string"
+                },
+                "jumlah": {
+                    "type": "integer"
+                },
+                "kondisi": {
+                    "type": "string"
+                },
+                "tanggal": {
+                    "type": "string"
                 }
             }
         },
@@ -1078,7 +4775,6 @@ const docTemplate = `{
             "required": [
                 "email",
                 "password",
-                "role",
                 "username"
             ],
             "properties": {
@@ -1092,7 +4788,6 @@ const docTemplate = `{
                 "role": {
                     "type": "string",
                     "enum": [
-                        "admin",
                         "user"
                     ]
                 },
@@ -1102,9 +4797,143 @@ const docTemplate = `{
                     "minLength": 3
                 }
             }
+        },
+        "models.RiwayatStatus": {
+            "type": "object",
+            "properties": {
+                "alasan": {
+                    "type": "string"
+                },
+                "dari": {
+                    "type": "string"
+                },
+                "ke": {
+                    "type": "string"
+                },
+                "oleh": {
+                    "type": "string"
+                },
+                "tanggal": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Role": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deskripsi": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "nama": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sistem": {
+                    "description": "role bawaan yang tidak bisa dihapus",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "nama_lengkap": {
+                    "type": "string"
+                },
+                "password_sekarang": {
+                    "type": "string"
+                },
+                "telepon": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "role",
+                "username"
+            ],
+            "properties": {
+                "belum_verifikasi": {
+                    "description": "email belum diverifikasi; akun lama dianggap sudah terverifikasi",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "harus_ganti_password": {
+                    "description": "password direset admin",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "nama_lengkap": {
+                    "type": "string"
+                },
+                "nonaktif": {
+                    "description": "akun dinonaktifkan admin, tidak bisa login",
+                    "type": "boolean"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "role": {
+                    "description": "nama role di koleksi roles; keberadaannya dicek lewat RoleRepository",
+                    "type": "string"
+                },
+                "telepon": {
+                    "type": "string"
+                },
+                "totp_aktif": {
+                    "description": "login wajib memakai kode 2FA",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 3
+                }
+            }
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key dari /auth/api-keys, bisa dipakai sebagai pengganti Bearer token",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "JWT token dengan format: Bearer {token}",
             "type": "apiKey",
@@ -19,14 +19,22 @@
 // @name Authorization
 // @description JWT token dengan format: Bearer {token}
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key dari /auth/api-keys, bisa dipakai sebagai pengganti Bearer token
+
 package main
 
 import (
+	"context"
 	"inventory-backend/config"
 	"inventory-backend/controllers"
 	_ "inventory-backend/docs" // Import swagger docs
 	"inventory-backend/middlewares"
+	"inventory-backend/repository"
 	"inventory-backend/routes"
+	"inventory-backend/services"
 	"log"
 	"os"
 
@@ -42,20 +50,71 @@ func main() {
 		log.Printf("Warning: .env file not found, using default values")
 	}
 
-	app := fiber.New()
+	// Token service dibuat setelah .env dimuat agar konfigurasi JWT terbaca
+	tokens, err := services.NewTokenServiceFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mailer, err := services.NewMailerFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// SSO hanya aktif jika OIDC_ISSUER diisi
+	oidc, err := services.NewOIDCProviderFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// IP client dipakai untuk membatasi login gagal per IP
+	if err := config.CekProxyHeader(); err != nil {
+		log.Fatal(err)
+	}
+	app := fiber.New(fiber.Config{
+		ProxyHeader: config.ProxyHeader(),
+	})
 
 	// Middleware
 	middlewares.SetupMiddleware(app)
 
-	// Connect DB
-	config.ConnectDB()
+	// Pilih backend penyimpanan
+	var repos *repository.Repositories
+	if config.UseMemoryStorage() {
+		log.Printf("Using in-memory storage, data will be lost on restart")
+		repos = repository.NewMemoryRepositories()
+	} else {
+		// Connect DB
+		if err := config.ConnectDB(); err != nil {
+			log.Fatal(err)
+		}
+		if err := repository.EnsureMongoIndexes(context.Background(), config.DB); err != nil {
+			log.Printf("Warning: gagal membuat index MongoDB: %v", err)
+		}
+		repos = repository.NewMongoRepositories(config.DB)
+	}
 
-	// ✅ Set DB ke controller setelah terkoneksi
-	controllers.SetUserCollection(config.DB)
-	controllers.SetKategoriCollection(config.DB)
-	controllers.SetBarangCollection(config.DB)
-	controllers.SetPeminjamanCollection(config.DB)
-	controllers.SetLaporanCollection(config.DB)
+	// ✅ Set repository ke controller setelah terkoneksi
+	controllers.SetRepositories(repos)
+	middlewares.SetRepositories(repos)
+	controllers.SetTokenService(tokens)
+	middlewares.SetTokenService(tokens)
+	controllers.SetMailer(mailer)
+	controllers.SetOIDCProvider(oidc)
+
+	if err := controllers.SeedRoles(context.Background()); err != nil {
+		log.Fatalf("Gagal membuat role bawaan: %v", err)
+	}
+
+	// Registrasi publik tidak bisa membuat admin, jadi admin awal dibuat dari env
+	if err := controllers.SeedAdmin(context.Background(), os.Getenv("ADMIN_EMAIL"), os.Getenv("ADMIN_PASSWORD")); err != nil {
+		log.Printf("Warning: gagal membuat admin awal: %v", err)
+	}
+
+	// Isi tempat sampah yang melewati masa retensi dihapus permanen secara berkala
+	if retensi := config.TrashRetensi(); retensi > 0 {
+		go controllers.JalankanPembersihanTrash(retensi, config.TrashIntervalPembersihan())
+	}
 
 	// Routes
 	routes.SetupRoutes(app)
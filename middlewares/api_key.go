@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/subtle"
+	"inventory-backend/config"
+	"inventory-backend/services"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// intervalCatatPemakaian membatasi penulisan last_used_at agar tidak setiap request
+const intervalCatatPemakaian = time.Minute
+
+// apiKeyAuth mengautentikasi request dengan header X-API-Key dan mengisi locals
+// yang sama seperti JWTMiddleware, ditambah api_key_id
+func apiKeyAuth(c *fiber.Ctx, rawKey string) error {
+	id, err := services.IDDariAPIKey(rawKey)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "API key tidak valid",
+		})
+	}
+
+	ctx := context.Background()
+	key, err := apiKeyRepo.FindByID(ctx, id)
+	if err != nil || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(services.HashAPIKey(rawKey))) != 1 {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "API key tidak valid",
+		})
+	}
+	now := time.Now()
+	if !key.Aktif(now) {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "API key sudah dicabut atau kedaluwarsa",
+		})
+	}
+
+	user, err := userRepo.FindByID(ctx, key.UserID)
+	if err != nil || user.Nonaktif {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Akun tidak aktif",
+		})
+	}
+
+	// Permission kunci adalah scope yang masih dimiliki role user. Role yang
+	// wajib 2FA tidak mendapat permission jika pemiliknya belum mengaktifkan 2FA.
+	var permissions []string
+	wajib2FA := config.Wajib2FA(user.Role) && !user.TOTPAktif
+	if role, err := roleRepo.FindByNama(ctx, user.Role); err == nil && !wajib2FA {
+		for _, scope := range key.Scopes {
+			if slices.Contains(role.Permissions, scope) {
+				permissions = append(permissions, scope)
+			}
+		}
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= intervalCatatPemakaian {
+		if err := apiKeyRepo.TandaiDipakai(ctx, key.ID, c.IP(), now); err != nil {
+			log.Printf("Gagal mencatat pemakaian API key %s: %v", key.ID.Hex(), err)
+		}
+	}
+
+	c.Locals("api_key_id", key.ID)
+	c.Locals("user_id", user.ID.Hex())
+	c.Locals("email", user.Email)
+	c.Locals("role", user.Role)
+	c.Locals("permissions", permissions)
+	c.Locals("belum_verifikasi", user.BelumVerifikasi)
+	c.Locals("wajib_2fa", wajib2FA)
+
+	return c.Next()
+}
+
+// RequireSesi menolak request yang diautentikasi dengan API key. Dipakai untuk
+// endpoint pengelolaan akun (password, 2FA, API key, logout) yang hanya boleh
+// dilakukan dari sesi login.
+func RequireSesi(c *fiber.Ctx) error {
+	if c.Locals("api_key_id") != nil {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Endpoint ini tidak bisa diakses dengan API key",
+		})
+	}
+	return c.Next()
+}
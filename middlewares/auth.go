@@ -1,19 +1,24 @@
 package middlewares
 
 import (
+	"context"
+	"inventory-backend/config"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// JWT Secret - should match with auth controller
-var jwtSecret = []byte("your-secret-key-change-this-in-production")
-
-// JWT Middleware untuk memverifikasi token
+// JWT Middleware untuk memverifikasi token. Request tanpa header Authorization
+// boleh memakai header X-API-Key sebagai gantinya.
 func JWTMiddleware(c *fiber.Ctx) error {
 	// Get Authorization header
 	authHeader := c.Get("Authorization")
+	if apiKey := c.Get("X-API-Key"); apiKey != "" && authHeader == "" {
+		return apiKeyAuth(c, apiKey)
+	}
 	if authHeader == "" {
 		return c.Status(401).JSON(fiber.Map{
 			"error": "Token tidak ditemukan",
@@ -30,92 +35,89 @@ func JWTMiddleware(c *fiber.Ctx) error {
 	// Extract token
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-	// Parse and validate token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fiber.NewError(401, "Metode signing token tidak valid")
-		}
-		return jwtSecret, nil
-	})
-
+	// Parse and validate token (signature, kid, algoritma, issuer dan expiry)
+	claims, err := tokenService.Parse(tokenString)
 	if err != nil {
 		return c.Status(401).JSON(fiber.Map{
 			"error": "Token tidak valid",
 		})
 	}
 
-	// Check if token is valid
-	if !token.Valid {
+	// Token harus terikat ke sesi yang masih aktif, sehingga logout dan
+	// pencabutan sesi langsung berlaku walau token belum kedaluwarsa
+	sid, _ := claims["sid"].(string)
+	sessionID, err := primitive.ObjectIDFromHex(sid)
+	if err != nil {
 		return c.Status(401).JSON(fiber.Map{
 			"error": "Token tidak valid",
 		})
 	}
+	session, err := sessionRepo.FindByID(context.Background(), sessionID)
+	if err != nil || !session.Aktif(time.Now()) || session.UserID.Hex() != claims["user_id"] {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Sesi sudah berakhir, silakan login kembali",
+		})
+	}
 
-	// Extract claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
+	// User yang dinonaktifkan atau dihapus ditolak walau tokennya masih berlaku.
+	// Role diambil dari database agar perubahan role langsung berlaku.
+	user, err := userRepo.FindByID(context.Background(), session.UserID)
+	if err != nil || user.Nonaktif {
 		return c.Status(401).JSON(fiber.Map{
-			"error": "Claims token tidak valid",
+			"error": "Akun tidak aktif",
 		})
 	}
 
+	// Permission diambil dari role di database; role yang sudah dihapus
+	// tidak memberi permission apa pun. Role yang wajib 2FA juga tidak mendapat
+	// permission sampai login dengan 2FA, tetapi tetap bisa mendaftarkan 2FA.
+	var permissions []string
+	wajib2FA := config.Wajib2FA(user.Role) && !session.Dengan2FA
+	if role, err := roleRepo.FindByNama(context.Background(), user.Role); err == nil && !wajib2FA {
+		permissions = role.Permissions
+	}
+
 	// Store user info in context
-	c.Locals("user_id", claims["user_id"])
-	c.Locals("email", claims["email"])
-	c.Locals("role", claims["role"])
+	c.Locals("session_id", sessionID)
+	c.Locals("user_id", user.ID.Hex())
+	c.Locals("email", user.Email)
+	c.Locals("role", user.Role)
+	c.Locals("permissions", permissions)
+	c.Locals("belum_verifikasi", user.BelumVerifikasi)
+	c.Locals("wajib_2fa", wajib2FA)
 
 	return c.Next()
 }
 
-// Middleware untuk role-based access
-func RequireRole(requiredRole string) fiber.Handler {
+// RequirePermission hanya meneruskan request jika role user memiliki permission tersebut
+func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		role := c.Locals("role")
-		if role == nil {
+		if wajib, _ := c.Locals("wajib_2fa").(bool); wajib {
 			return c.Status(403).JSON(fiber.Map{
-				"error": "Role tidak ditemukan",
+				"error": "Role Anda wajib memakai 2FA. Aktifkan lewat /auth/2fa/setup dan /auth/2fa/enable",
 			})
 		}
-
-		userRole, ok := role.(string)
-		if !ok {
+		if !HasPermission(c, permission) {
 			return c.Status(403).JSON(fiber.Map{
-				"error": "Role tidak valid",
+				"error": "Akses ditolak. Membutuhkan permission " + permission,
 			})
 		}
-
-		// Admin can access everything
-		if userRole == "admin" {
-			return c.Next()
-		}
-
-		// Check if user has required role
-		if userRole != requiredRole {
-			return c.Status(403).JSON(fiber.Map{
-				"error": "Akses ditolak. Role tidak mencukupi",
-			})
-		}
-
 		return c.Next()
 	}
 }
 
-// Middleware untuk admin only access
-func RequireAdmin(c *fiber.Ctx) error {
-	role := c.Locals("role")
-	if role == nil {
-		return c.Status(403).JSON(fiber.Map{
-			"error": "Role tidak ditemukan",
-		})
-	}
-
-	userRole, ok := role.(string)
-	if !ok || userRole != "admin" {
+// RequireEmailTerverifikasi menolak user yang emailnya belum diverifikasi
+func RequireEmailTerverifikasi(c *fiber.Ctx) error {
+	if belum, _ := c.Locals("belum_verifikasi").(bool); belum {
 		return c.Status(403).JSON(fiber.Map{
-			"error": "Akses ditolak. Hanya admin yang dapat mengakses",
+			"error": "Email belum diverifikasi. Verifikasi email terlebih dahulu",
 		})
 	}
-
 	return c.Next()
 }
+
+// HasPermission bernilai true jika role user yang sedang login memiliki permission tersebut
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	permissions, _ := c.Locals("permissions").([]string)
+	return slices.Contains(permissions, permission)
+}
@@ -4,13 +4,21 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/gofiber/fiber/v2/utils"
 )
 
 func SetupMiddleware(app *fiber.App) {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "https://feinventory-production.up.railway.app, http://localhost:5173, https://beinventory-production.up.railway.app",
 		AllowCredentials: true,
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-API-Key, X-Request-ID",
+		ExposeHeaders:    "X-Request-ID",
+	}))
+	// Setiap request mendapat X-Request-ID (atau memakai kiriman client) yang
+	// ikut tercatat di log dan audit log
+	app.Use(requestid.New(requestid.Config{Generator: utils.UUIDv4}))
+	app.Use(logger.New(logger.Config{
+		Format: "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${locals:requestid} | ${error}\n",
 	}))
-	app.Use(logger.New())
 }
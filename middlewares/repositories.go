@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"inventory-backend/repository"
+	"inventory-backend/services"
+)
+
+var (
+	sessionRepo repository.SessionRepository
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+	apiKeyRepo  repository.APIKeyRepository
+)
+
+// SetRepositories menghubungkan middleware ke backend penyimpanan
+func SetRepositories(repos *repository.Repositories) {
+	sessionRepo = repos.Session
+	userRepo = repos.User
+	roleRepo = repos.Role
+	apiKeyRepo = repos.APIKey
+}
+
+// tokenService memverifikasi access token dengan kunci yang sama seperti saat login
+var tokenService *services.TokenService
+
+// SetTokenService mengatur token service yang dipakai JWTMiddleware
+func SetTokenService(tokens *services.TokenService) {
+	tokenService = tokens
+}
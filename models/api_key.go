@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey adalah kunci akses milik user untuk script dan integrasi, dikirim lewat
+// header X-API-Key. Permission kunci adalah irisan Scopes dengan permission role
+// user saat request, sehingga perubahan role langsung berlaku. Yang disimpan
+// hanya hash kunci.
+type APIKey struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Nama       string             `json:"nama" bson:"nama"`
+	KeyHash    string             `json:"-" bson:"key_hash"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt  *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"` // nil berarti tidak kedaluwarsa
+	LastUsedAt *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	LastUsedIP string             `json:"last_used_ip,omitempty" bson:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Aktif bernilai true jika kunci belum dicabut dan belum kedaluwarsa
+func (k *APIKey) Aktif(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// CreateAPIKeyRequest adalah body untuk membuat API key
+type CreateAPIKeyRequest struct {
+	Nama      string     `json:"nama"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // RFC 3339, kosong berarti tidak kedaluwarsa
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Aksi yang dicatat di audit log
+const (
+	AuditLaporanPeminjaman = "laporan.peminjaman"
+	AuditLaporanTerlambat  = "laporan.terlambat"
+	AuditLoginDikunci      = "auth.login_dikunci"
+	AuditBukaKunciLogin    = "auth.buka_kunci_login"
+	AuditAktifkan2FA       = "auth.2fa_aktif"
+	AuditNonaktifkan2FA    = "auth.2fa_nonaktif"
+	AuditReset2FA          = "users.reset_2fa"
+	AuditBuatAPIKey        = "auth.api_key_dibuat"
+	AuditCabutAPIKey       = "auth.api_key_dicabut"
+	AuditBuatUserSSO       = "auth.sso_user_dibuat"
+	AuditRoleSSO           = "auth.sso_role_diubah"
+	AuditRegistrasi        = "auth.registrasi"
+	AuditUbahProfil        = "auth.profil_diubah"
+	AuditGantiPassword     = "auth.password_diganti"
+	AuditLupaPassword      = "auth.password_direset"
+	AuditVerifikasiEmail   = "auth.email_diverifikasi"
+
+	AuditBuatBarang         = "barang.dibuat"
+	AuditUbahBarang         = "barang.diubah"
+	AuditHapusBarang        = "barang.dihapus"
+	AuditPulihkanBarang     = "barang.dipulihkan"
+	AuditKoreksiStok        = "barang.stok_dikoreksi"
+	AuditBuatKategori       = "kategori.dibuat"
+	AuditUbahKategori       = "kategori.diubah"
+	AuditHapusKategori      = "kategori.dihapus"
+	AuditPulihkanKategori   = "kategori.dipulihkan"
+	AuditBuatPeminjaman     = "peminjaman.dibuat"
+	AuditStatusPeminjaman   = "peminjaman.status_diubah"
+	AuditJumlahPeminjaman   = "peminjaman.jumlah_diubah"
+	AuditPengembalian       = "peminjaman.pengembalian"
+	AuditHapusPeminjaman    = "peminjaman.dihapus"
+	AuditPulihkanPeminjaman = "peminjaman.dipulihkan"
+	AuditBuatUser           = "users.dibuat"
+	AuditRoleUser           = "users.role_diubah"
+	AuditStatusUser         = "users.status_diubah"
+	AuditResetPasswordUser  = "users.password_direset"
+	AuditHapusUser          = "users.dihapus"
+	AuditBuatRole           = "roles.dibuat"
+	AuditUbahRole           = "roles.diubah"
+	AuditHapusRole          = "roles.dihapus"
+)
+
+// Jenis resource yang perubahannya dicatat di audit log
+const (
+	ResourceBarang     = "barang"
+	ResourceKategori   = "kategori"
+	ResourcePeminjaman = "peminjaman"
+	ResourceUser       = "user"
+	ResourceRole       = "role"
+)
+
+// AuditLog mencatat siapa melakukan apa dan kapan. Data ini tidak pernah diubah.
+// Untuk perubahan data, Sebelum dan Sesudah berisi snapshot resource (Sebelum
+// kosong saat dibuat, Sesudah kosong saat dihapus) dan Perubahan berisi field
+// yang nilainya berbeda di antara keduanya.
+type AuditLog struct {
+	ID         primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Aksi       string                 `json:"aksi" bson:"aksi"`
+	UserID     *primitive.ObjectID    `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	Resource   string                 `json:"resource,omitempty" bson:"resource,omitempty"`
+	ResourceID *primitive.ObjectID    `json:"resource_id,omitempty" bson:"resource_id,omitempty"`
+	Sebelum    map[string]interface{} `json:"sebelum,omitempty" bson:"sebelum,omitempty"`
+	Sesudah    map[string]interface{} `json:"sesudah,omitempty" bson:"sesudah,omitempty"`
+	Perubahan  map[string]Perubahan   `json:"perubahan,omitempty" bson:"perubahan,omitempty"`
+	Detail     map[string]interface{} `json:"detail,omitempty" bson:"detail,omitempty"`
+	IP         string                 `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	Tanggal    time.Time              `json:"tanggal" bson:"tanggal"`
+}
+
+// Perubahan adalah nilai satu field sebelum dan sesudah diubah
+type Perubahan struct {
+	Dari interface{} `json:"dari" bson:"dari"`
+	Ke   interface{} `json:"ke" bson:"ke"`
+}
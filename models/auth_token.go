@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis token sekali pakai
+const (
+	TokenResetPassword   = "reset_password"
+	TokenVerifikasiEmail = "verifikasi_email"
+)
+
+// AuthToken adalah token sekali pakai yang dikirim lewat email untuk reset
+// password atau verifikasi email. Yang disimpan hanya hash token.
+type AuthToken struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Jenis     string             `json:"jenis" bson:"jenis"`
+	Email     string             `json:"email" bson:"email"` // email tujuan, token batal jika email user berubah
+	TokenHash string             `json:"-" bson:"token_hash"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"`
+	UsedAt    *time.Time         `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
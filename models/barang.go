@@ -7,5 +7,8 @@ type Barang struct {
 	Nama        string             `json:"nama" bson:"nama"`
 	KategoriID  primitive.ObjectID `json:"kategori_id" bson:"kategori_id"`
 	Stok        int                `json:"stok" bson:"stok"`
+	StokRusak   int                `json:"stok_rusak" bson:"stok_rusak"`   // unit kembali rusak, tidak termasuk stok
+	StokHilang  int                `json:"stok_hilang" bson:"stok_hilang"` // unit hilang, tidak termasuk stok
 	TanggalBuat string             `json:"tanggal_buat" bson:"tanggal_buat"`
+	Terhapus    `bson:",inline"`
 }
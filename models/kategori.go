@@ -7,4 +7,5 @@ type Kategori struct {
 	Nama        string             `json:"nama" bson:"nama"`
 	Deskripsi   string             `json:"deskripsi" bson:"deskripsi"`
 	TanggalBuat string             `json:"tanggal_buat" bson:"tanggal_buat"`
+	Terhapus    `bson:",inline"`
 }
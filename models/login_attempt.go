@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LoginAttempt menghitung login gagal untuk satu kunci, yaitu satu email
+// ("email:<email>") atau satu alamat IP ("ip:<ip>"). Hitungan dimulai ulang
+// jika login gagal terakhir sudah di luar jendela waktu.
+type LoginAttempt struct {
+	Kunci          string     `json:"kunci" bson:"_id"`
+	Gagal          int        `json:"gagal" bson:"gagal"`
+	TerakhirGagal  time.Time  `json:"terakhir_gagal" bson:"terakhir_gagal"`
+	TerkunciSampai *time.Time `json:"terkunci_sampai,omitempty" bson:"terkunci_sampai,omitempty"`
+	ExpiresAt      time.Time  `json:"-" bson:"expires_at"` // dokumen dihapus otomatis setelah waktu ini
+}
+
+// Terkunci bernilai true jika kunci sedang dikunci sementara
+func (a *LoginAttempt) Terkunci(now time.Time) bool {
+	return a.TerkunciSampai != nil && now.Before(*a.TerkunciSampai)
+}
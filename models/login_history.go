@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis kejadian di riwayat login
+const (
+	RiwayatLogin          = "login"
+	RiwayatLoginGagal     = "login_gagal"
+	RiwayatRefresh        = "refresh"
+	RiwayatRefreshDitolak = "refresh_ditolak" // refresh token lama dipakai ulang
+	RiwayatLogout         = "logout"
+	RiwayatLogoutSemua    = "logout_semua"
+	RiwayatSesiDicabut    = "sesi_dicabut"
+)
+
+// Metode login sebuah sesi
+const (
+	MetodePassword = "password"
+	MetodeSSO      = "sso"
+)
+
+// LoginHistory mencatat login, refresh dan logout beserta asal request-nya.
+// Berbeda dengan Session yang dihapus setelah kedaluwarsa, riwayat ini disimpan
+// untuk keperluan audit keamanan.
+type LoginHistory struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Jenis      string              `json:"jenis" bson:"jenis"`
+	UserID     *primitive.ObjectID `json:"user_id,omitempty" bson:"user_id,omitempty"` // kosong jika login gagal dengan email tidak terdaftar
+	SessionID  *primitive.ObjectID `json:"session_id,omitempty" bson:"session_id,omitempty"`
+	Email      string              `json:"email,omitempty" bson:"email,omitempty"`
+	Metode     string              `json:"metode,omitempty" bson:"metode,omitempty"`
+	Dengan2FA  bool                `json:"dengan_2fa,omitempty" bson:"dengan_2fa,omitempty"`
+	Keterangan string              `json:"keterangan,omitempty" bson:"keterangan,omitempty"`
+	IP         string              `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent  string              `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	Tanggal    time.Time           `json:"tanggal" bson:"tanggal"`
+}
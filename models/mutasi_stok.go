@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Alasan mutasi stok
+const (
+	MutasiAwal              = "initial"
+	MutasiPenyesuaian       = "manual_adjustment"
+	MutasiPinjam            = "loan_out"
+	MutasiKembali           = "loan_return"
+	MutasiPeminjamanDihapus = "loan_deleted"
+	MutasiPeminjamanBatal   = "loan_cancelled"
+	MutasiPeminjamanPulih   = "loan_restored"
+	MutasiKoreksi           = "correction"
+)
+
+// MutasiStok adalah satu entri ledger stok yang tidak pernah diubah setelah dibuat.
+// Jumlah semua Delta untuk satu barang sama dengan stok barang tersebut.
+type MutasiStok struct {
+	ID           primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	BarangID     primitive.ObjectID  `json:"barang_id" bson:"barang_id"`
+	Delta        int                 `json:"delta" bson:"delta"`
+	Alasan       string              `json:"alasan" bson:"alasan"`
+	PeminjamanID *primitive.ObjectID `json:"peminjaman_id,omitempty" bson:"peminjaman_id,omitempty"`
+	UserID       primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	Tanggal      time.Time           `json:"tanggal" bson:"tanggal"`
+}
@@ -1,15 +1,157 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
 
-type Peminjaman struct {
-	ID             primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	NamaPeminjam   string             `json:"nama_peminjam" bson:"nama_peminjam"`
-	EmailPeminjam  string             `json:"email_peminjam" bson:"email_peminjam"`
-	TeleponPeminjam string            `json:"telepon_peminjam" bson:"telepon_peminjam"`
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Format tanggal yang dipakai di peminjaman
+const (
+	FormatTanggal      = "2006-01-02"
+	FormatTanggalWaktu = "2006-01-02 15:04:05"
+)
+
+// Status peminjaman
+const (
+	StatusDiajukan     = "diajukan"
+	StatusDisetujui    = "disetujui"
+	StatusDitolak      = "ditolak"
+	StatusDipinjam     = "dipinjam"
+	StatusDikembalikan = "dikembalikan"
+	StatusDibatalkan   = "dibatalkan"
+)
+
+// SemuaStatus berisi semua status peminjaman yang dikenal
+var SemuaStatus = []string{
+	StatusDiajukan,
+	StatusDisetujui,
+	StatusDitolak,
+	StatusDipinjam,
+	StatusDikembalikan,
+	StatusDibatalkan,
+}
+
+// MenahanStok bernilai true untuk status yang stok barangnya sudah dikurangi
+// (dipesan saat disetujui dan tetap tertahan selama dipinjam)
+func MenahanStok(status string) bool {
+	return status == StatusDisetujui || status == StatusDipinjam
+}
+
+// RiwayatStatus mencatat satu perpindahan status beserta pelaku dan alasannya
+type RiwayatStatus struct {
+	Dari    string             `json:"dari" bson:"dari"`
+	Ke      string             `json:"ke" bson:"ke"`
+	Oleh    primitive.ObjectID `json:"oleh" bson:"oleh"`
+	Alasan  string             `json:"alasan,omitempty" bson:"alasan,omitempty"`
+	Tanggal string             `json:"tanggal" bson:"tanggal"`
+}
+
+// Kondisi barang saat dikembalikan
+const (
+	KondisiBaik        = "baik"
+	KondisiRusakRingan = "rusak_ringan"
+	KondisiRusakBerat  = "rusak_berat"
+	KondisiHilang      = "hilang"
+)
+
+// Pengembalian mencatat satu kejadian pengembalian sebagian atau seluruh unit
+// sebuah barang beserta kondisinya
+type Pengembalian struct {
+	BarangID     primitive.ObjectID `json:"barang_id" bson:"barang_id"`
+	Jumlah       int                `json:"jumlah" bson:"jumlah"`
+	Kondisi      string             `json:"kondisi" bson:"kondisi"`
+	Catatan      string             `json:"catatan,omitempty" bson:"catatan,omitempty"`
+	DiterimaOleh primitive.ObjectID `json:"diterima_oleh" bson:"diterima_oleh"`
+	Tanggal      string             `json:"tanggal" bson:"tanggal"`
+}
+
+// ItemPeminjaman adalah satu baris barang di dalam peminjaman
+// JumlahKembali menghitung semua unit yang sudah diterima kembali dalam kondisi apa pun.
+type ItemPeminjaman struct {
 	BarangID       primitive.ObjectID `json:"barang_id" bson:"barang_id"`
 	Jumlah         int                `json:"jumlah" bson:"jumlah"`
-	TanggalPinjam  string             `json:"tanggal_pinjam" bson:"tanggal_pinjam"`
-	Status         string             `json:"status" bson:"status"`
+	JumlahKembali  int                `json:"jumlah_kembali" bson:"jumlah_kembali"`
+	TanggalKembali string             `json:"tanggal_kembali,omitempty" bson:"tanggal_kembali,omitempty"`
+}
+
+// SisaPinjam adalah jumlah unit yang belum dikembalikan
+func (i ItemPeminjaman) SisaPinjam() int {
+	return i.Jumlah - i.JumlahKembali
+}
+
+// Peminjaman terikat ke akun peminjam lewat UserID, atau kosong untuk peminjam
+// tamu di luar sistem. Nama, email dan telepon tetap disimpan sebagai data
+// peminjam saat pengajuan.
+type Peminjaman struct {
+	ID                primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID            *primitive.ObjectID `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	NamaPeminjam      string              `json:"nama_peminjam" bson:"nama_peminjam"`
+	EmailPeminjam     string              `json:"email_peminjam" bson:"email_peminjam"`
+	TeleponPeminjam   string              `json:"telepon_peminjam" bson:"telepon_peminjam"`
+	Items             []ItemPeminjaman    `json:"items" bson:"items"`
+	TanggalPinjam     string              `json:"tanggal_pinjam" bson:"tanggal_pinjam"`
+	TanggalJatuhTempo string              `json:"tanggal_jatuh_tempo" bson:"tanggal_jatuh_tempo"`
+	TanggalKembali    string              `json:"tanggal_kembali,omitempty" bson:"tanggal_kembali,omitempty"`
+	Status            string              `json:"status" bson:"status"`
+	RiwayatStatus     []RiwayatStatus     `json:"riwayat_status,omitempty" bson:"riwayat_status,omitempty"`
+	Pengembalian      []Pengembalian      `json:"pengembalian,omitempty" bson:"pengembalian,omitempty"`
+	Terhapus          `bson:",inline"`
+
+	// Format lama dengan satu barang per peminjaman. Hanya dipakai saat membaca
+	// data lama atau request lama, lalu diubah menjadi Items oleh Normalisasi.
+	BarangID *primitive.ObjectID `json:"barang_id,omitempty" bson:"barang_id,omitempty"`
+	Jumlah   int                 `json:"jumlah,omitempty" bson:"jumlah,omitempty"`
+
+	// Field turunan, dihitung saat dibaca dan tidak disimpan
+	Terlambat     bool `json:"terlambat" bson:"-"`
+	HariTerlambat int  `json:"hari_terlambat,omitempty" bson:"-"`
+}
+
+// Normalisasi mengubah peminjaman format lama (satu barang_id dan jumlah)
+// menjadi satu baris Items
+func (p *Peminjaman) Normalisasi() {
+	if len(p.Items) == 0 && p.BarangID != nil && !p.BarangID.IsZero() {
+		item := ItemPeminjaman{BarangID: *p.BarangID, Jumlah: p.Jumlah}
+		if p.Status == StatusDikembalikan {
+			item.JumlahKembali = p.Jumlah
+			item.TanggalKembali = p.TanggalKembali
+		}
+		p.Items = []ItemPeminjaman{item}
+	}
+	p.BarangID = nil
+	p.Jumlah = 0
+}
+
+// SemuaKembali bernilai true jika semua unit di semua baris sudah diterima kembali
+func (p *Peminjaman) SemuaKembali() bool {
+	for _, item := range p.Items {
+		if item.SisaPinjam() > 0 {
+			return false
+		}
+	}
+	return true
 }
 
+// HitungKeterlambatan mengisi Terlambat dan HariTerlambat untuk peminjaman yang
+// masih dipinjam dan sudah melewati tanggal jatuh tempo
+func (p *Peminjaman) HitungKeterlambatan(now time.Time) {
+	p.Terlambat = false
+	p.HariTerlambat = 0
+
+	if p.Status != StatusDipinjam || p.TanggalJatuhTempo == "" {
+		return
+	}
+
+	jatuhTempo, err := time.ParseInLocation(FormatTanggal, p.TanggalJatuhTempo, now.Location())
+	if err != nil {
+		return
+	}
+
+	hariIni := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	hari := int(hariIni.Sub(jatuhTempo).Hours() / 24)
+	if hari > 0 {
+		p.Terlambat = true
+		p.HariTerlambat = hari
+	}
+}
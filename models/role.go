@@ -0,0 +1,76 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Permission yang dikenali aplikasi
+const (
+	PermBarangWrite               = "barang:write"
+	PermKategoriWrite             = "kategori:write"
+	PermStokRead                  = "stok:read"
+	PermStokWrite                 = "stok:write"
+	PermPeminjamanCreate          = "peminjaman:create"
+	PermPeminjamanCreateForOthers = "peminjaman:create_for_others"
+	PermPeminjamanReadAll         = "peminjaman:read_all"
+	PermPeminjamanApprove         = "peminjaman:approve"
+	PermPeminjamanUpdate          = "peminjaman:update"
+	PermPeminjamanReturn          = "peminjaman:return"
+	PermPeminjamanDelete          = "peminjaman:delete"
+	PermLaporanRead               = "laporan:read"
+	PermLaporanPII                = "laporan:pii"
+	PermUsersManage               = "users:manage"
+	PermRolesManage               = "roles:manage"
+	PermTrashManage               = "trash:manage"
+	PermAuditRead                 = "audit:read"
+)
+
+// SemuaPermission berisi semua permission beserta keterangannya
+var SemuaPermission = map[string]string{
+	PermBarangWrite:               "Membuat, mengubah dan menghapus barang termasuk stoknya",
+	PermKategoriWrite:             "Membuat, mengubah dan menghapus kategori",
+	PermStokRead:                  "Melihat rekonsiliasi stok",
+	PermStokWrite:                 "Mencatat koreksi ledger stok",
+	PermPeminjamanCreate:          "Mengajukan peminjaman atas nama sendiri",
+	PermPeminjamanCreateForOthers: "Mengajukan peminjaman atas nama user lain atau peminjam tamu",
+	PermPeminjamanReadAll:         "Melihat peminjaman semua user",
+	PermPeminjamanApprove:         "Mengubah status peminjaman (menyetujui, menolak, meminjamkan, membatalkan)",
+	PermPeminjamanUpdate:          "Mengubah jumlah barang di peminjaman",
+	PermPeminjamanReturn:          "Mencatat pengembalian barang",
+	PermPeminjamanDelete:          "Menghapus peminjaman",
+	PermLaporanRead:               "Melihat laporan",
+	PermLaporanPII:                "Melihat nama, email dan telepon peminjam tanpa disamarkan di laporan",
+	PermUsersManage:               "Mengelola user",
+	PermRolesManage:               "Mengelola role dan permission",
+	PermTrashManage:               "Melihat tempat sampah berisi barang, kategori dan peminjaman yang dihapus",
+	PermAuditRead:                 "Melihat audit log dan riwayat perubahan data",
+}
+
+// Role bawaan. Role admin selalu memiliki semua permission.
+const (
+	RoleAdmin         = "admin"
+	RoleUser          = "user"
+	RolePetugasGudang = "petugas_gudang"
+	RoleApprover      = "approver"
+	RoleViewer        = "viewer"
+)
+
+// Role adalah kumpulan permission yang bisa diatur admin. User mereferensikan
+// role lewat Nama, sehingga nama role tidak bisa diubah setelah dibuat.
+type Role struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Nama        string             `json:"nama" bson:"nama"`
+	Deskripsi   string             `json:"deskripsi" bson:"deskripsi"`
+	Permissions []string           `json:"permissions" bson:"permissions"`
+	Sistem      bool               `json:"sistem" bson:"sistem"` // role bawaan yang tidak bisa dihapus
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Punya bernilai true jika role memiliki permission tersebut
+func (r *Role) Punya(permission string) bool {
+	return slices.Contains(r.Permissions, permission)
+}
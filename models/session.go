@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Alasan sesi dicabut
+const (
+	SesiLogout        = "logout"
+	SesiLogoutSemua   = "logout_all"
+	SesiTokenDipakai  = "refresh_token_reuse"
+	SesiUserNonaktif  = "user_deactivated"
+	SesiResetAdmin    = "password_reset_by_admin"
+	SesiUserDihapus   = "user_deleted"
+	SesiResetEmail    = "password_reset_by_email"
+	SesiAktifkan2FA   = "2fa_enabled"
+	SesiReset2FA      = "2fa_reset_by_admin"
+	SesiGantiPassword = "password_changed"
+	SesiDicabutUser   = "revoked_by_user"
+)
+
+// MaksRefreshTokenLama membatasi jumlah hash refresh token lama yang disimpan
+// per sesi agar dokumen sesi yang sering dirotasi tidak terus membesar. Reuse
+// token yang lebih lama dari batas ini tidak lagi terdeteksi.
+const MaksRefreshTokenLama = 100
+
+// Session adalah satu sesi login. Refresh token dirotasi setiap kali dipakai;
+// semua refresh token hasil rotasi dari satu login membentuk satu family, yaitu
+// sesi ini. Yang disimpan hanya hash token, bukan tokennya.
+type Session struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID           primitive.ObjectID `json:"user_id" bson:"user_id"`
+	RefreshTokenHash string             `json:"-" bson:"refresh_token_hash"`
+	RefreshTokenLama []string           `json:"-" bson:"refresh_token_lama,omitempty"` // hash token yang sudah dirotasi (maksimal MaksRefreshTokenLama terakhir), untuk mendeteksi reuse
+	UserAgent        string             `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	IP               string             `json:"ip,omitempty" bson:"ip,omitempty"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	LastUsedAt       time.Time          `json:"last_used_at" bson:"last_used_at"`
+	ExpiresAt        time.Time          `json:"expires_at" bson:"expires_at"`
+	RevokedAt        *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	AlasanRevoke     string             `json:"alasan_revoke,omitempty" bson:"alasan_revoke,omitempty"`
+	Dengan2FA        bool               `json:"dengan_2fa" bson:"dengan_2fa"`             // login diverifikasi dengan kode 2FA
+	Metode           string             `json:"metode,omitempty" bson:"metode,omitempty"` // MetodePassword atau MetodeSSO
+}
+
+// Aktif bernilai true jika sesi belum dicabut dan belum kedaluwarsa
+func (s *Session) Aktif(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Terhapus menandai data yang dihapus dengan soft delete. Data yang terhapus
+// tidak muncul di list dan pencarian, tetapi masih tersimpan di tempat sampah
+// sampai dipulihkan atau dibersihkan permanen.
+type Terhapus struct {
+	DeletedAt *time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy *primitive.ObjectID `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+// SudahDihapus bernilai true jika data ada di tempat sampah
+func (t Terhapus) SudahDihapus() bool {
+	return t.DeletedAt != nil
+}
+
+// DihapusSebelum bernilai true jika data dihapus pada atau sebelum batas
+func (t Terhapus) DihapusSebelum(batas time.Time) bool {
+	return t.DeletedAt != nil && !t.DeletedAt.After(batas)
+}
+
+// TandaiTerhapus memindahkan data ke tempat sampah
+func (t *Terhapus) TandaiTerhapus(oleh primitive.ObjectID, waktu time.Time) {
+	t.DeletedAt = &waktu
+	t.DeletedBy = &oleh
+}
+
+// Pulihkan mengeluarkan data dari tempat sampah
+func (t *Terhapus) Pulihkan() {
+	t.DeletedAt = nil
+	t.DeletedBy = nil
+}
@@ -7,13 +7,23 @@ import (
 )
 
 type User struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Username  string             `json:"username" bson:"username" validate:"required,min=3,max=50"`
-	Email     string             `json:"email" bson:"email" validate:"required,email"`
-	Password  string             `json:"password,omitempty" bson:"password" validate:"required,min=6"`
-	Role      string             `json:"role" bson:"role" validate:"required,oneof=admin user"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Username           string             `json:"username" bson:"username" validate:"required,min=3,max=50"`
+	Email              string             `json:"email" bson:"email" validate:"required,email"`
+	NamaLengkap        string             `json:"nama_lengkap,omitempty" bson:"nama_lengkap,omitempty"`
+	Telepon            string             `json:"telepon,omitempty" bson:"telepon,omitempty"`
+	Password           string             `json:"password,omitempty" bson:"password" validate:"required,min=6"`
+	Role               string             `json:"role" bson:"role" validate:"required"`             // nama role di koleksi roles; keberadaannya dicek lewat RoleRepository
+	Nonaktif           bool               `json:"nonaktif" bson:"nonaktif"`                         // akun dinonaktifkan admin, tidak bisa login
+	HarusGantiPassword bool               `json:"harus_ganti_password" bson:"harus_ganti_password"` // password direset admin
+	BelumVerifikasi    bool               `json:"belum_verifikasi" bson:"belum_verifikasi"`         // email belum diverifikasi; akun lama dianggap sudah terverifikasi
+	TOTPAktif          bool               `json:"totp_aktif" bson:"totp_aktif"`                     // login wajib memakai kode 2FA
+	TOTPSecret         string             `json:"-" bson:"totp_secret,omitempty"`                   // terisi sejak setup walau 2FA belum diaktifkan
+	TOTPLangkah        int64              `json:"-" bson:"totp_langkah,omitempty"`                  // langkah waktu kode terakhir yang dipakai, mencegah replay
+	KodePemulihan      []string           `json:"-" bson:"kode_pemulihan,omitempty"`                // hash kode pemulihan yang belum dipakai
+	OIDCSubject        string             `json:"-" bson:"oidc_subject,omitempty"`                  // claim sub dari identity provider jika akun terhubung SSO
+	CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
 type LoginRequest struct {
@@ -21,19 +31,43 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// RegisterRequest untuk registrasi publik. Role hanya boleh kosong atau "user";
+// akun admin dibuat lewat seed ADMIN_EMAIL atau diangkat oleh admin lain.
 type RegisterRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
-	Role     string `json:"role" validate:"required,oneof=admin user"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=user"`
+}
+
+// UpdateProfileRequest untuk mengubah profile sendiri. Field yang tidak dikirim
+// tidak diubah. Mengganti email wajib menyertakan password saat ini.
+type UpdateProfileRequest struct {
+	Username         *string `json:"username,omitempty"`
+	Email            *string `json:"email,omitempty"`
+	NamaLengkap      *string `json:"nama_lengkap,omitempty"`
+	Telepon          *string `json:"telepon,omitempty"`
+	PasswordSekarang string  `json:"password_sekarang,omitempty"`
+}
+
+// ChangePasswordRequest untuk mengganti password sendiri
+type ChangePasswordRequest struct {
+	PasswordLama string `json:"password_lama"`
+	PasswordBaru string `json:"password_baru"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  struct {
-		ID       primitive.ObjectID `json:"id"`
-		Username string             `json:"username"`
-		Email    string             `json:"email"`
-		Role     string             `json:"role"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // masa berlaku token dalam detik
+	User         struct {
+		ID                 primitive.ObjectID `json:"id"`
+		Username           string             `json:"username"`
+		Email              string             `json:"email"`
+		Role               string             `json:"role"`
+		HarusGantiPassword bool               `json:"harus_ganti_password"`
+		BelumVerifikasi    bool               `json:"belum_verifikasi"`
+		TOTPAktif          bool               `json:"totp_aktif"`
+		Wajib2FA           bool               `json:"wajib_2fa"` // role wajib 2FA tetapi 2FA belum diaktifkan
 	} `json:"user"`
 }
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.APIKey, error)
+	// FindByUser mengembalikan semua API key milik user, yang terbaru lebih dulu
+	FindByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error)
+	// Revoke mencabut API key milik user; ErrNotFound jika tidak ada atau bukan miliknya
+	Revoke(ctx context.Context, id, userID primitive.ObjectID) error
+	// TandaiDipakai menyimpan waktu dan IP pemakaian terakhir
+	TandaiDipakai(ctx context.Context, id primitive.ObjectID, ip string, waktu time.Time) error
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditFilter membatasi hasil FindAll. Field kosong berarti tidak difilter.
+type AuditFilter struct {
+	// UserID membatasi hasil ke aksi yang dilakukan satu akun
+	UserID *primitive.ObjectID
+	Aksi   string
+	// Resource dan ResourceID membatasi hasil ke perubahan satu jenis atau satu data
+	Resource   string
+	ResourceID *primitive.ObjectID
+	// RequestID mengambil semua catatan dari satu request
+	RequestID string
+	// Dari (inklusif) dan Sampai (eksklusif) membatasi waktu pencatatan
+	Dari   time.Time
+	Sampai time.Time
+}
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+	// FindAll mengembalikan audit log yang cocok dengan filter, yang terbaru lebih dulu
+	FindAll(ctx context.Context, filter AuditFilter, opts ListOptions) ([]models.AuditLog, int64, error)
+}
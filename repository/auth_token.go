@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuthTokenRepository interface {
+	Create(ctx context.Context, token *models.AuthToken) error
+	// Pakai menandai token sebagai terpakai secara atomik dan mengembalikannya.
+	// Mengembalikan ErrNotFound jika token tidak ada, sudah dipakai atau sudah
+	// kedaluwarsa, sehingga satu token hanya bisa dipakai sekali.
+	Pakai(ctx context.Context, jenis, tokenHash string) (*models.AuthToken, error)
+	// BatalkanMilikUser menandai semua token user dengan jenis tersebut yang
+	// belum dipakai sebagai terpakai, dipanggil sebelum token baru dibuat
+	BatalkanMilikUser(ctx context.Context, userID primitive.ObjectID, jenis string) error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BarangFilter membatasi hasil FindAll. Field kosong berarti tidak difilter.
+type BarangFilter struct {
+	KategoriID *primitive.ObjectID
+	// StokMin dan StokMax membatasi stok tersedia, keduanya inklusif
+	StokMin *int
+	StokMax *int
+	// TermasukTerhapus ikut mengembalikan barang yang ada di tempat sampah
+	TermasukTerhapus bool
+	// Nama mencari sebagian nama barang
+	Nama Pencarian
+	// Teks adalah query pencarian teks (kata kunci) atas nama barang. Hasilnya
+	// bisa diurutkan berdasarkan relevansi dengan UrutRelevansi.
+	Teks string
+}
+
+type BarangRepository interface {
+	FindAll(ctx context.Context, filter BarangFilter, opts ListOptions) ([]models.Barang, int64, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Barang, error)
+	Create(ctx context.Context, barang *models.Barang) error
+	// Update mengubah data barang kecuali stok; stok hanya berubah lewat IncrementStok
+	Update(ctx context.Context, barang *models.Barang) error
+	// Delete memindahkan barang ke tempat sampah (soft delete)
+	Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error
+	// Restore mengeluarkan barang dari tempat sampah
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	// FindTerhapus mengambil isi tempat sampah yang dihapus pada atau sebelum waktu sebelum
+	FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Barang, int64, error)
+	// HapusPermanen menghapus barang yang sudah ada di tempat sampah
+	HapusPermanen(ctx context.Context, id primitive.ObjectID) error
+	// PindahKategori memindahkan semua barang di kategori dari ke kategori ke
+	// dan mengembalikan jumlah barang yang dipindah
+	PindahKategori(ctx context.Context, dari, ke primitive.ObjectID) (int64, error)
+	// DeleteByKategori memindahkan semua barang di satu kategori ke tempat sampah
+	// dan mengembalikan jumlahnya
+	DeleteByKategori(ctx context.Context, kategoriID, oleh primitive.ObjectID) (int64, error)
+	// IncrementStok menambah (delta positif) atau mengurangi (delta negatif) stok barang.
+	// Pengurangan dijaga secara atomik dan mengembalikan ErrStokTidakCukup jika stok
+	// tidak cukup, sehingga stok tidak pernah negatif walau ada request paralel.
+	IncrementStok(ctx context.Context, id primitive.ObjectID, delta int) error
+	// IncrementStokTidakTersedia menambah jumlah unit rusak dan hilang
+	IncrementStokTidakTersedia(ctx context.Context, id primitive.ObjectID, rusak, hilang int) error
+}
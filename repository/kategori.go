@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type KategoriRepository interface {
+	FindAll(ctx context.Context, opts ListOptions) ([]models.Kategori, int64, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Kategori, error)
+	Create(ctx context.Context, kategori *models.Kategori) error
+	Update(ctx context.Context, kategori *models.Kategori) error
+	// Delete memindahkan kategori ke tempat sampah (soft delete)
+	Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error
+	// Restore mengeluarkan kategori dari tempat sampah
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	// FindTerhapus mengambil isi tempat sampah yang dihapus pada atau sebelum waktu sebelum
+	FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Kategori, int64, error)
+	// HapusPermanen menghapus kategori yang sudah ada di tempat sampah
+	HapusPermanen(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	// Find mengembalikan ErrNotFound jika kunci belum pernah gagal login
+	Find(ctx context.Context, kunci string) (*models.LoginAttempt, error)
+	// CatatGagal menambah hitungan login gagal secara atomik dan mengembalikan
+	// hasilnya. Hitungan dimulai dari 1 lagi jika gagal terakhir lebih lama dari
+	// jendela. Dokumen disimpan sampai expiresAt.
+	CatatGagal(ctx context.Context, kunci string, jendela time.Duration, expiresAt time.Time) (*models.LoginAttempt, error)
+	// Kunci mengunci kunci login sampai waktu tertentu
+	Kunci(ctx context.Context, kunci string, sampai time.Time) error
+	// Reset menghapus hitungan dan kunci; kunci yang tidak ada dibiarkan
+	Reset(ctx context.Context, kunci string) error
+}
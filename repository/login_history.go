@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LoginHistoryRepository interface {
+	Create(ctx context.Context, history *models.LoginHistory) error
+	// FindByUser mengembalikan riwayat login user, yang terbaru lebih dulu
+	FindByUser(ctx context.Context, userID primitive.ObjectID, opts ListOptions) ([]models.LoginHistory, int64, error)
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"inventory-backend/models"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore menyimpan semua koleksi in-memory di balik satu mutex,
+// sehingga operasi yang menyentuh beberapa koleksi tetap konsisten.
+type memoryStore struct {
+	mu           sync.Mutex
+	barang       map[primitive.ObjectID]models.Barang
+	kategori     map[primitive.ObjectID]models.Kategori
+	peminjaman   map[primitive.ObjectID]models.Peminjaman
+	users        map[primitive.ObjectID]models.User
+	mutasi       map[primitive.ObjectID]models.MutasiStok
+	sessions     map[primitive.ObjectID]models.Session
+	roles        map[primitive.ObjectID]models.Role
+	audit        map[primitive.ObjectID]models.AuditLog
+	authTokens   map[primitive.ObjectID]models.AuthToken
+	apiKeys      map[primitive.ObjectID]models.APIKey
+	loginHistory map[primitive.ObjectID]models.LoginHistory
+	// loginAttempts memakai kunci string (email atau IP), bukan ObjectID
+	loginAttempts map[string]models.LoginAttempt
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		barang:        map[primitive.ObjectID]models.Barang{},
+		kategori:      map[primitive.ObjectID]models.Kategori{},
+		peminjaman:    map[primitive.ObjectID]models.Peminjaman{},
+		users:         map[primitive.ObjectID]models.User{},
+		mutasi:        map[primitive.ObjectID]models.MutasiStok{},
+		sessions:      map[primitive.ObjectID]models.Session{},
+		roles:         map[primitive.ObjectID]models.Role{},
+		audit:         map[primitive.ObjectID]models.AuditLog{},
+		authTokens:    map[primitive.ObjectID]models.AuthToken{},
+		apiKeys:       map[primitive.ObjectID]models.APIKey{},
+		loginHistory:  map[primitive.ObjectID]models.LoginHistory{},
+		loginAttempts: map[string]models.LoginAttempt{},
+	}
+}
+
+type memoryTxKey struct{}
+
+// lock mengunci store dan mengembalikan fungsi untuk membukanya.
+// Di dalam WithTransaction kunci sudah dipegang, jadi tidak dikunci ulang.
+func (s *memoryStore) lock(ctx context.Context) func() {
+	if ctx.Value(memoryTxKey{}) == s {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// WithTransaction menjalankan fn sambil memegang kunci store. Jika fn
+// mengembalikan error, semua perubahan dikembalikan ke kondisi semula.
+func (s *memoryStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memoryTxKey{}) == s {
+		// Transaksi bersarang ikut transaksi luar
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.clone()
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, s)); err != nil {
+		s.restore(snapshot)
+		return err
+	}
+	return nil
+}
+
+func (s *memoryStore) clone() *memoryStore {
+	return &memoryStore{
+		barang:        cloneMap(s.barang),
+		kategori:      cloneMap(s.kategori),
+		peminjaman:    cloneMap(s.peminjaman),
+		users:         cloneMap(s.users),
+		mutasi:        cloneMap(s.mutasi),
+		sessions:      cloneMap(s.sessions),
+		roles:         cloneMap(s.roles),
+		audit:         cloneMap(s.audit),
+		authTokens:    cloneMap(s.authTokens),
+		apiKeys:       cloneMap(s.apiKeys),
+		loginHistory:  cloneMap(s.loginHistory),
+		loginAttempts: cloneMap(s.loginAttempts),
+	}
+}
+
+func (s *memoryStore) restore(snapshot *memoryStore) {
+	s.barang = snapshot.barang
+	s.kategori = snapshot.kategori
+	s.peminjaman = snapshot.peminjaman
+	s.users = snapshot.users
+	s.mutasi = snapshot.mutasi
+	s.sessions = snapshot.sessions
+	s.roles = snapshot.roles
+	s.audit = snapshot.audit
+	s.authTokens = snapshot.authTokens
+	s.apiKeys = snapshot.apiKeys
+	s.loginHistory = snapshot.loginHistory
+	s.loginAttempts = snapshot.loginAttempts
+}
+
+// cloneMap menyalin map. Nilai disimpan sebagai struct dan selalu diganti
+// utuh oleh repository, sehingga salinan dangkal sudah cukup.
+func cloneMap[K comparable, T any](data map[K]T) map[K]T {
+	copied := make(map[K]T, len(data))
+	for id, v := range data {
+		copied[id] = v
+	}
+	return copied
+}
+
+// sortedIDs mengurutkan ID dari yang paling lama dibuat, meniru urutan natural MongoDB
+func sortedIDs[T any](data map[primitive.ObjectID]T) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+	return ids
+}
+
+// toBsonM mengubah struct menjadi bson.M dengan bentuk yang sama seperti hasil decode MongoDB
+func toBsonM(v interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// halaman mengurutkan items sesuai opts, menerapkan After, lalu memotongnya
+// seperti findHalaman. Nilai field urutan dibaca dari bentuk bson item.
+func halaman[T any](items []T, opts ListOptions) ([]T, error) {
+	field := opts.Sort
+	if field == "" {
+		field = "_id"
+	}
+
+	type baris struct {
+		item  T
+		id    primitive.ObjectID
+		nilai interface{}
+	}
+	rows := make([]baris, 0, len(items))
+	for _, item := range items {
+		doc, err := toBsonM(item)
+		if err != nil {
+			return nil, err
+		}
+		id, _ := doc["_id"].(primitive.ObjectID)
+		rows = append(rows, baris{item: item, id: id, nilai: doc[field]})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		c := bandingkanNilai(rows[i].nilai, rows[j].nilai)
+		if c == 0 {
+			c = bytes.Compare(rows[i].id[:], rows[j].id[:])
+		}
+		if opts.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	if opts.After != nil {
+		idx := slices.IndexFunc(rows, func(b baris) bool { return b.id == *opts.After })
+		if idx < 0 {
+			return nil, ErrCursorTidakValid
+		}
+		rows = rows[idx+1:]
+	}
+
+	hasil := make([]T, 0, len(rows))
+	for _, b := range rows {
+		hasil = append(hasil, b.item)
+	}
+	return paginate(hasil, opts), nil
+}
+
+// bandingkanNilai membandingkan dua nilai bson untuk pengurutan. Nilai kosong
+// diletakkan paling awal seperti urutan MongoDB.
+func bandingkanNilai(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	if x, ok := angka(a); ok {
+		if y, ok := angka(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case primitive.ObjectID:
+		if y, ok := b.(primitive.ObjectID); ok {
+			return bytes.Compare(x[:], y[:])
+		}
+	case primitive.DateTime:
+		if y, ok := b.(primitive.DateTime); ok {
+			return cmp.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok && x != y {
+			if x {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+// angka mengubah nilai numerik bson menjadi float64
+func angka(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// paginate memotong hasil sesuai ListOptions
+func paginate[T any](items []T, opts ListOptions) []T {
+	start := opts.Skip()
+	if start >= len(items) {
+		return []T{}
+	}
+	end := len(items)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+	return items[start:end]
+}
+
+// cocokCari bernilai true jika nilai cocok dengan teks pencarian, sama seperti regexCari
+func cocokCari(nilai string, cari Pencarian) bool {
+	nilai, teks := strings.ToLower(nilai), strings.ToLower(cari.Teks)
+	if cari.Awalan {
+		return strings.HasPrefix(nilai, teks)
+	}
+	return strings.Contains(nilai, teks)
+}
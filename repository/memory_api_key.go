@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAPIKeyRepository struct {
+	store *memoryStore
+}
+
+// copyAPIKey menyalin slice scopes agar data di store tidak ikut berubah
+func copyAPIKey(k models.APIKey) models.APIKey {
+	k.Scopes = append([]string(nil), k.Scopes...)
+	return k
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	defer r.store.lock(ctx)()
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	r.store.apiKeys[key.ID] = copyAPIKey(*key)
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.APIKey, error) {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	key = copyAPIKey(key)
+	return &key, nil
+}
+
+func (r *memoryAPIKeyRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error) {
+	defer r.store.lock(ctx)()
+
+	keys := []models.APIKey{}
+	for _, id := range sortedIDs(r.store.apiKeys) {
+		if key := r.store.apiKeys[id]; key.UserID == userID {
+			keys = append(keys, copyAPIKey(key))
+		}
+	}
+	slices.Reverse(keys)
+	return keys, nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(ctx context.Context, id, userID primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok || key.UserID != userID {
+		return ErrNotFound
+	}
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		r.store.apiKeys[id] = key
+	}
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) TandaiDipakai(ctx context.Context, id primitive.ObjectID, ip string, waktu time.Time) error {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return nil
+	}
+	key.LastUsedAt = &waktu
+	key.LastUsedIP = ip
+	r.store.apiKeys[id] = key
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAuditLogRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryAuditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	defer r.store.lock(ctx)()
+
+	if log.ID.IsZero() {
+		log.ID = primitive.NewObjectID()
+	}
+	r.store.audit[log.ID] = *log
+	return nil
+}
+
+func (r *memoryAuditLogRepository) FindAll(ctx context.Context, filter AuditFilter, opts ListOptions) ([]models.AuditLog, int64, error) {
+	defer r.store.lock(ctx)()
+
+	logs := []models.AuditLog{}
+	for _, id := range sortedIDs(r.store.audit) {
+		l := r.store.audit[id]
+		if filter.UserID != nil && (l.UserID == nil || *l.UserID != *filter.UserID) {
+			continue
+		}
+		if filter.Aksi != "" && l.Aksi != filter.Aksi {
+			continue
+		}
+		if filter.Resource != "" && l.Resource != filter.Resource {
+			continue
+		}
+		if filter.ResourceID != nil && (l.ResourceID == nil || *l.ResourceID != *filter.ResourceID) {
+			continue
+		}
+		if filter.RequestID != "" && l.RequestID != filter.RequestID {
+			continue
+		}
+		if !filter.Dari.IsZero() && l.Tanggal.Before(filter.Dari) {
+			continue
+		}
+		if !filter.Sampai.IsZero() && !l.Tanggal.Before(filter.Sampai) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+
+	hasil, err := halaman(logs, opts.DenganUrutan("tanggal", true))
+	if err != nil {
+		return nil, 0, err
+	}
+	return hasil, int64(len(logs)), nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAuthTokenRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryAuthTokenRepository) Create(ctx context.Context, token *models.AuthToken) error {
+	defer r.store.lock(ctx)()
+
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	r.store.authTokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryAuthTokenRepository) Pakai(ctx context.Context, jenis, tokenHash string) (*models.AuthToken, error) {
+	defer r.store.lock(ctx)()
+
+	now := time.Now()
+	for id, token := range r.store.authTokens {
+		if token.TokenHash != tokenHash || token.Jenis != jenis || token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+			continue
+		}
+		token.UsedAt = &now
+		r.store.authTokens[id] = token
+		return &token, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryAuthTokenRepository) BatalkanMilikUser(ctx context.Context, userID primitive.ObjectID, jenis string) error {
+	defer r.store.lock(ctx)()
+
+	now := time.Now()
+	for id, token := range r.store.authTokens {
+		if token.UserID == userID && token.Jenis == jenis && token.UsedAt == nil {
+			token.UsedAt = &now
+			r.store.authTokens[id] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryBarangRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryBarangRepository) FindAll(ctx context.Context, filter BarangFilter, opts ListOptions) ([]models.Barang, int64, error) {
+	defer r.store.lock(ctx)()
+
+	barang := []models.Barang{}
+	for _, id := range sortedIDs(r.store.barang) {
+		b := r.store.barang[id]
+		if b.SudahDihapus() && !filter.TermasukTerhapus {
+			continue
+		}
+		if filter.KategoriID != nil && b.KategoriID != *filter.KategoriID {
+			continue
+		}
+		if filter.StokMin != nil && b.Stok < *filter.StokMin {
+			continue
+		}
+		if filter.StokMax != nil && b.Stok > *filter.StokMax {
+			continue
+		}
+		if filter.Nama.Teks != "" && !cocokCari(b.Nama, filter.Nama) {
+			continue
+		}
+		if filter.Teks != "" && skorTeks(b.Nama, filter.Teks) == 0 {
+			continue
+		}
+		barang = append(barang, b)
+	}
+
+	if opts.Sort == UrutRelevansi {
+		if opts.After != nil {
+			return nil, 0, ErrCursorTidakValid
+		}
+		// Urutan stabil menjaga urutan _id untuk skor yang sama
+		sort.SliceStable(barang, func(i, j int) bool {
+			return skorTeks(barang[i].Nama, filter.Teks) > skorTeks(barang[j].Nama, filter.Teks)
+		})
+		return paginate(barang, opts), int64(len(barang)), nil
+	}
+
+	hasil, err := halaman(barang, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hasil, int64(len(barang)), nil
+}
+
+func (r *memoryBarangRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Barang, error) {
+	defer r.store.lock(ctx)()
+
+	barang, ok := r.store.barang[id]
+	if !ok || barang.SudahDihapus() {
+		return nil, ErrNotFound
+	}
+	return &barang, nil
+}
+
+func (r *memoryBarangRepository) Create(ctx context.Context, barang *models.Barang) error {
+	defer r.store.lock(ctx)()
+
+	if barang.ID.IsZero() {
+		barang.ID = primitive.NewObjectID()
+	}
+	r.store.barang[barang.ID] = *barang
+	return nil
+}
+
+func (r *memoryBarangRepository) Update(ctx context.Context, barang *models.Barang) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.barang[barang.ID]
+	if !ok || existing.SudahDihapus() {
+		return ErrNotFound
+	}
+	existing.Nama = barang.Nama
+	existing.KategoriID = barang.KategoriID
+	existing.TanggalBuat = barang.TanggalBuat
+	r.store.barang[barang.ID] = existing
+	return nil
+}
+
+func (r *memoryBarangRepository) Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return tandaiTerhapusMemory(r.store.barang, id, oleh)
+}
+
+func (r *memoryBarangRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return pulihkanMemory(r.store.barang, id)
+}
+
+func (r *memoryBarangRepository) FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Barang, int64, error) {
+	defer r.store.lock(ctx)()
+
+	return findTerhapusMemory(r.store.barang, sebelum, opts)
+}
+
+func (r *memoryBarangRepository) HapusPermanen(ctx context.Context, id primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return hapusPermanenMemory(r.store.barang, id)
+}
+
+func (r *memoryBarangRepository) PindahKategori(ctx context.Context, dari, ke primitive.ObjectID) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var jumlah int64
+	for id, b := range r.store.barang {
+		if b.KategoriID == dari {
+			b.KategoriID = ke
+			r.store.barang[id] = b
+			jumlah++
+		}
+	}
+	return jumlah, nil
+}
+
+func (r *memoryBarangRepository) DeleteByKategori(ctx context.Context, kategoriID, oleh primitive.ObjectID) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var jumlah int64
+	now := time.Now()
+	for id, b := range r.store.barang {
+		if b.KategoriID == kategoriID && !b.SudahDihapus() {
+			b.TandaiTerhapus(oleh, now)
+			r.store.barang[id] = b
+			jumlah++
+		}
+	}
+	return jumlah, nil
+}
+
+func (r *memoryBarangRepository) IncrementStok(ctx context.Context, id primitive.ObjectID, delta int) error {
+	defer r.store.lock(ctx)()
+
+	barang, ok := r.store.barang[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if barang.Stok+delta < 0 {
+		return ErrStokTidakCukup
+	}
+	barang.Stok += delta
+	r.store.barang[id] = barang
+	return nil
+}
+
+func (r *memoryBarangRepository) IncrementStokTidakTersedia(ctx context.Context, id primitive.ObjectID, rusak, hilang int) error {
+	defer r.store.lock(ctx)()
+
+	barang, ok := r.store.barang[id]
+	if !ok {
+		return ErrNotFound
+	}
+	barang.StokRusak += rusak
+	barang.StokHilang += hilang
+	r.store.barang[id] = barang
+	return nil
+}
+
+// skorTeks meniru pencarian $text MongoDB secara sederhana: jumlah kata pada
+// nilai yang sama dengan salah satu kata query, tanpa membedakan huruf besar/kecil
+func skorTeks(nilai, query string) int {
+	kata := strings.Fields(strings.ToLower(query))
+	skor := 0
+	for _, k := range strings.Fields(strings.ToLower(nilai)) {
+		if slices.Contains(kata, k) {
+			skor++
+		}
+	}
+	return skor
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryKategoriRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryKategoriRepository) FindAll(ctx context.Context, opts ListOptions) ([]models.Kategori, int64, error) {
+	defer r.store.lock(ctx)()
+
+	kategori := []models.Kategori{}
+	for _, id := range sortedIDs(r.store.kategori) {
+		if k := r.store.kategori[id]; !k.SudahDihapus() {
+			kategori = append(kategori, k)
+		}
+	}
+
+	hasil, err := halaman(kategori, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hasil, int64(len(kategori)), nil
+}
+
+func (r *memoryKategoriRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Kategori, error) {
+	defer r.store.lock(ctx)()
+
+	kategori, ok := r.store.kategori[id]
+	if !ok || kategori.SudahDihapus() {
+		return nil, ErrNotFound
+	}
+	return &kategori, nil
+}
+
+func (r *memoryKategoriRepository) Create(ctx context.Context, kategori *models.Kategori) error {
+	defer r.store.lock(ctx)()
+
+	if kategori.ID.IsZero() {
+		kategori.ID = primitive.NewObjectID()
+	}
+	r.store.kategori[kategori.ID] = *kategori
+	return nil
+}
+
+func (r *memoryKategoriRepository) Update(ctx context.Context, kategori *models.Kategori) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.kategori[kategori.ID]
+	if !ok || existing.SudahDihapus() {
+		return ErrNotFound
+	}
+	existing.Nama = kategori.Nama
+	existing.Deskripsi = kategori.Deskripsi
+	existing.TanggalBuat = kategori.TanggalBuat
+	r.store.kategori[kategori.ID] = existing
+	return nil
+}
+
+func (r *memoryKategoriRepository) Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return tandaiTerhapusMemory(r.store.kategori, id, oleh)
+}
+
+func (r *memoryKategoriRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return pulihkanMemory(r.store.kategori, id)
+}
+
+func (r *memoryKategoriRepository) FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Kategori, int64, error) {
+	defer r.store.lock(ctx)()
+
+	return findTerhapusMemory(r.store.kategori, sebelum, opts)
+}
+
+func (r *memoryKategoriRepository) HapusPermanen(ctx context.Context, id primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return hapusPermanenMemory(r.store.kategori, id)
+}
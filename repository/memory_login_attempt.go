@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+)
+
+type memoryLoginAttemptRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryLoginAttemptRepository) Find(ctx context.Context, kunci string) (*models.LoginAttempt, error) {
+	defer r.store.lock(ctx)()
+
+	attempt, ok := r.store.loginAttempts[kunci]
+	if !ok || !time.Now().Before(attempt.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return &attempt, nil
+}
+
+func (r *memoryLoginAttemptRepository) CatatGagal(ctx context.Context, kunci string, jendela time.Duration, expiresAt time.Time) (*models.LoginAttempt, error) {
+	defer r.store.lock(ctx)()
+
+	now := time.Now()
+	attempt, ok := r.store.loginAttempts[kunci]
+	if !ok || !now.Before(attempt.ExpiresAt) {
+		attempt = models.LoginAttempt{Kunci: kunci}
+	}
+	if attempt.TerakhirGagal.Before(now.Add(-jendela)) {
+		attempt.Gagal = 0
+	}
+	attempt.Gagal++
+	attempt.TerakhirGagal = now
+	attempt.ExpiresAt = expiresAt
+	r.store.loginAttempts[kunci] = attempt
+	return &attempt, nil
+}
+
+func (r *memoryLoginAttemptRepository) Kunci(ctx context.Context, kunci string, sampai time.Time) error {
+	defer r.store.lock(ctx)()
+
+	attempt, ok := r.store.loginAttempts[kunci]
+	if !ok {
+		return nil
+	}
+	attempt.TerkunciSampai = &sampai
+	if sampai.After(attempt.ExpiresAt) {
+		attempt.ExpiresAt = sampai
+	}
+	r.store.loginAttempts[kunci] = attempt
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) Reset(ctx context.Context, kunci string) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.loginAttempts, kunci)
+	return nil
+}
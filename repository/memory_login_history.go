@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryLoginHistoryRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryLoginHistoryRepository) Create(ctx context.Context, history *models.LoginHistory) error {
+	defer r.store.lock(ctx)()
+
+	if history.ID.IsZero() {
+		history.ID = primitive.NewObjectID()
+	}
+	r.store.loginHistory[history.ID] = *history
+	return nil
+}
+
+func (r *memoryLoginHistoryRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, opts ListOptions) ([]models.LoginHistory, int64, error) {
+	defer r.store.lock(ctx)()
+
+	riwayat := []models.LoginHistory{}
+	for _, id := range sortedIDs(r.store.loginHistory) {
+		if h := r.store.loginHistory[id]; h.UserID != nil && *h.UserID == userID {
+			riwayat = append(riwayat, h)
+		}
+	}
+
+	hasil, err := halaman(riwayat, opts.DenganUrutan("tanggal", true))
+	if err != nil {
+		return nil, 0, err
+	}
+	return hasil, int64(len(riwayat)), nil
+}
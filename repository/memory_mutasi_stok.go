@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryMutasiStokRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryMutasiStokRepository) Create(ctx context.Context, mutasi *models.MutasiStok) error {
+	defer r.store.lock(ctx)()
+
+	if mutasi.ID.IsZero() {
+		mutasi.ID = primitive.NewObjectID()
+	}
+	r.store.mutasi[mutasi.ID] = *mutasi
+	return nil
+}
+
+func (r *memoryMutasiStokRepository) FindByBarang(ctx context.Context, barangID primitive.ObjectID, opts ListOptions) ([]models.MutasiStok, int64, error) {
+	defer r.store.lock(ctx)()
+
+	mutasi := []models.MutasiStok{}
+	for _, id := range sortedIDs(r.store.mutasi) {
+		if m := r.store.mutasi[id]; m.BarangID == barangID {
+			mutasi = append(mutasi, m)
+		}
+	}
+
+	hasil, err := halaman(mutasi, opts.DenganUrutan("tanggal", true))
+	if err != nil {
+		return nil, 0, err
+	}
+	return hasil, int64(len(mutasi)), nil
+}
+
+func (r *memoryMutasiStokRepository) SumPerBarang(ctx context.Context) (map[primitive.ObjectID]int, error) {
+	defer r.store.lock(ctx)()
+
+	total := map[primitive.ObjectID]int{}
+	for _, m := range r.store.mutasi {
+		total[m.BarangID] += m.Delta
+	}
+	return total, nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPeminjamanRepository struct {
+	store *memoryStore
+}
+
+// copyPeminjaman menyalin slice di dalam peminjaman agar data di store
+// tidak ikut berubah ketika pemanggil memodifikasi hasilnya
+func copyPeminjaman(p models.Peminjaman) models.Peminjaman {
+	p.Items = append([]models.ItemPeminjaman(nil), p.Items...)
+	p.RiwayatStatus = append([]models.RiwayatStatus(nil), p.RiwayatStatus...)
+	p.Pengembalian = append([]models.Pengembalian(nil), p.Pengembalian...)
+	return p
+}
+
+func (r *memoryPeminjamanRepository) FindAll(ctx context.Context, filter PeminjamanFilter, opts ListOptions) ([]models.Peminjaman, int64, error) {
+	defer r.store.lock(ctx)()
+
+	peminjaman := []models.Peminjaman{}
+	for _, id := range sortedIDs(r.store.peminjaman) {
+		p := r.store.peminjaman[id]
+		if p.SudahDihapus() && !filter.TermasukTerhapus {
+			continue
+		}
+		if filter.Cari.Teks != "" && !r.cocokPencarian(p, filter.Cari) {
+			continue
+		}
+		if filter.UserID != nil && (p.UserID == nil || *p.UserID != *filter.UserID) {
+			continue
+		}
+		if len(filter.Status) > 0 && !slices.Contains(filter.Status, p.Status) {
+			continue
+		}
+		if len(filter.BarangID) > 0 && !slices.ContainsFunc(filter.BarangID, func(id primitive.ObjectID) bool { return memuatBarang(p, id) }) {
+			continue
+		}
+		if filter.EmailPeminjam != "" && !strings.EqualFold(p.EmailPeminjam, filter.EmailPeminjam) {
+			continue
+		}
+		if filter.TanggalDari != "" && p.TanggalPinjam < filter.TanggalDari {
+			continue
+		}
+		if filter.TanggalSampai != "" && p.TanggalPinjam > filter.TanggalSampai+" 23:59:59" {
+			continue
+		}
+		peminjaman = append(peminjaman, copyPeminjaman(p))
+	}
+
+	hasil, err := halaman(peminjaman, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hasil, int64(len(peminjaman)), nil
+}
+
+// cocokPencarian bernilai true jika data peminjam atau nama salah satu barang
+// yang dipinjam cocok dengan cari
+func (r *memoryPeminjamanRepository) cocokPencarian(p models.Peminjaman, cari Pencarian) bool {
+	if cocokCari(p.NamaPeminjam, cari) || cocokCari(p.EmailPeminjam, cari) || cocokCari(p.TeleponPeminjam, cari) {
+		return true
+	}
+	for id, b := range r.store.barang {
+		if cocokCari(b.Nama, cari) && memuatBarang(p, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// memuatBarang bernilai true jika peminjaman memuat barangID, termasuk data format lama
+func memuatBarang(p models.Peminjaman, barangID primitive.ObjectID) bool {
+	if p.BarangID != nil && *p.BarangID == barangID {
+		return true
+	}
+	for _, item := range p.Items {
+		if item.BarangID == barangID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryPeminjamanRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Peminjaman, error) {
+	defer r.store.lock(ctx)()
+
+	peminjaman, ok := r.store.peminjaman[id]
+	if !ok || peminjaman.SudahDihapus() {
+		return nil, ErrNotFound
+	}
+	peminjaman = copyPeminjaman(peminjaman)
+	return &peminjaman, nil
+}
+
+func (r *memoryPeminjamanRepository) Create(ctx context.Context, peminjaman *models.Peminjaman) error {
+	defer r.store.lock(ctx)()
+
+	if peminjaman.ID.IsZero() {
+		peminjaman.ID = primitive.NewObjectID()
+	}
+	r.store.peminjaman[peminjaman.ID] = copyPeminjaman(*peminjaman)
+	return nil
+}
+
+func (r *memoryPeminjamanRepository) Update(ctx context.Context, peminjaman *models.Peminjaman) error {
+	defer r.store.lock(ctx)()
+
+	if existing, ok := r.store.peminjaman[peminjaman.ID]; !ok || existing.SudahDihapus() {
+		return ErrNotFound
+	}
+	r.store.peminjaman[peminjaman.ID] = copyPeminjaman(*peminjaman)
+	return nil
+}
+
+func (r *memoryPeminjamanRepository) Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return tandaiTerhapusMemory(r.store.peminjaman, id, oleh)
+}
+
+func (r *memoryPeminjamanRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return pulihkanMemory(r.store.peminjaman, id)
+}
+
+func (r *memoryPeminjamanRepository) FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Peminjaman, int64, error) {
+	defer r.store.lock(ctx)()
+
+	peminjaman, total, err := findTerhapusMemory(r.store.peminjaman, sebelum, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range peminjaman {
+		peminjaman[i] = copyPeminjaman(peminjaman[i])
+	}
+	return peminjaman, total, nil
+}
+
+func (r *memoryPeminjamanRepository) HapusPermanen(ctx context.Context, id primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	return hapusPermanenMemory(r.store.peminjaman, id)
+}
+
+func (r *memoryPeminjamanRepository) FindTerlambat(ctx context.Context, hariIni string) ([]models.Peminjaman, error) {
+	defer r.store.lock(ctx)()
+
+	peminjaman := []models.Peminjaman{}
+	for _, id := range sortedIDs(r.store.peminjaman) {
+		p := r.store.peminjaman[id]
+		if !p.SudahDihapus() && p.Status == models.StatusDipinjam && p.TanggalJatuhTempo != "" && p.TanggalJatuhTempo < hariIni {
+			peminjaman = append(peminjaman, copyPeminjaman(p))
+		}
+	}
+	sort.SliceStable(peminjaman, func(i, j int) bool {
+		return peminjaman[i].TanggalJatuhTempo < peminjaman[j].TanggalJatuhTempo
+	})
+	return peminjaman, nil
+}
+
+func (r *memoryPeminjamanRepository) Laporan(ctx context.Context) ([]bson.M, error) {
+	defer r.store.lock(ctx)()
+
+	hasil := []bson.M{}
+	for _, id := range sortedIDs(r.store.peminjaman) {
+		p := r.store.peminjaman[id]
+		if p.SudahDihapus() {
+			continue
+		}
+
+		// Satu baris per item, sama seperti $unwind items
+		for _, item := range p.Items {
+			doc, err := toBsonM(p)
+			if err != nil {
+				return nil, err
+			}
+			if doc["items"], err = toBsonM(item); err != nil {
+				return nil, err
+			}
+			doc["barang_id"] = item.BarangID
+			doc["jumlah"] = item.Jumlah
+
+			// Sama seperti $unwind dengan preserveNullAndEmptyArrays, barang atau
+			// kategori yang tidak ada hanya membuat field info-nya kosong
+			if barang, ok := r.store.barang[item.BarangID]; ok {
+				if doc["barang_info"], err = toBsonM(barang); err != nil {
+					return nil, err
+				}
+				if kategori, ok := r.store.kategori[barang.KategoriID]; ok {
+					if doc["kategori_info"], err = toBsonM(kategori); err != nil {
+						return nil, err
+					}
+				}
+			}
+			hasil = append(hasil, doc)
+		}
+	}
+	return hasil, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRoleRepository struct {
+	store *memoryStore
+}
+
+// copyRole menyalin slice permission agar data di store tidak ikut berubah
+func copyRole(r models.Role) models.Role {
+	r.Permissions = append([]string(nil), r.Permissions...)
+	return r
+}
+
+func (r *memoryRoleRepository) FindAll(ctx context.Context) ([]models.Role, error) {
+	defer r.store.lock(ctx)()
+
+	roles := []models.Role{}
+	for _, role := range r.store.roles {
+		roles = append(roles, copyRole(role))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Nama < roles[j].Nama })
+	return roles, nil
+}
+
+func (r *memoryRoleRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Role, error) {
+	defer r.store.lock(ctx)()
+
+	role, ok := r.store.roles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	role = copyRole(role)
+	return &role, nil
+}
+
+func (r *memoryRoleRepository) FindByNama(ctx context.Context, nama string) (*models.Role, error) {
+	defer r.store.lock(ctx)()
+
+	for _, role := range r.store.roles {
+		if role.Nama == nama {
+			role = copyRole(role)
+			return &role, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryRoleRepository) Create(ctx context.Context, role *models.Role) error {
+	defer r.store.lock(ctx)()
+
+	if role.ID.IsZero() {
+		role.ID = primitive.NewObjectID()
+	}
+	r.store.roles[role.ID] = copyRole(*role)
+	return nil
+}
+
+func (r *memoryRoleRepository) Update(ctx context.Context, role *models.Role) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.roles[role.ID]; !ok {
+		return ErrNotFound
+	}
+	r.store.roles[role.ID] = copyRole(*role)
+	return nil
+}
+
+func (r *memoryRoleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.roles, id)
+	return nil
+}
+
+func (r *memoryRoleRepository) KunciRole(ctx context.Context, nama string) error {
+	// Transaksi in-memory sudah berjalan satu per satu di bawah kunci store
+	return nil
+}
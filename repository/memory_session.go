@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memorySessionRepository struct {
+	store *memoryStore
+}
+
+// copySession menyalin slice hash lama agar data di store tidak ikut berubah
+func copySession(s models.Session) models.Session {
+	s.RefreshTokenLama = append([]string(nil), s.RefreshTokenLama...)
+	return s
+}
+
+func (r *memorySessionRepository) Create(ctx context.Context, session *models.Session) error {
+	defer r.store.lock(ctx)()
+
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	r.store.sessions[session.ID] = copySession(*session)
+	return nil
+}
+
+func (r *memorySessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	defer r.store.lock(ctx)()
+
+	session, ok := r.store.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	session = copySession(session)
+	return &session, nil
+}
+
+func (r *memorySessionRepository) FindAktifByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error) {
+	defer r.store.lock(ctx)()
+
+	now := time.Now()
+	sessions := []models.Session{}
+	for _, id := range sortedIDs(r.store.sessions) {
+		if session := r.store.sessions[id]; session.UserID == userID && session.Aktif(now) {
+			sessions = append(sessions, copySession(session))
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+func (r *memorySessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, hashLama, hashBaru string, expiresAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	session, ok := r.store.sessions[id]
+	if !ok || session.RevokedAt != nil || session.RefreshTokenHash != hashLama {
+		return ErrNotFound
+	}
+	session = copySession(session)
+	session.RefreshTokenLama = append(session.RefreshTokenLama, hashLama)
+	if n := len(session.RefreshTokenLama); n > models.MaksRefreshTokenLama {
+		session.RefreshTokenLama = session.RefreshTokenLama[n-models.MaksRefreshTokenLama:]
+	}
+	session.RefreshTokenHash = hashBaru
+	session.ExpiresAt = expiresAt
+	session.LastUsedAt = time.Now()
+	r.store.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) Revoke(ctx context.Context, id primitive.ObjectID, alasan string) error {
+	defer r.store.lock(ctx)()
+
+	session, ok := r.store.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.AlasanRevoke = alasan
+	r.store.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) RevokeByUser(ctx context.Context, userID primitive.ObjectID, kecuali *primitive.ObjectID, alasan string) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	now := time.Now()
+	var jumlah int64
+	for id, session := range r.store.sessions {
+		if session.UserID != userID || session.RevokedAt != nil || (kecuali != nil && id == *kecuali) {
+			continue
+		}
+		session.RevokedAt = &now
+		session.AlasanRevoke = alasan
+		r.store.sessions[id] = session
+		jumlah++
+	}
+	return jumlah, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"inventory-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemoryIncrementStok(t *testing.T) {
+	repos := NewMemoryRepositories()
+	ctx := context.Background()
+
+	barang := models.Barang{Nama: "Proyektor", KategoriID: primitive.NewObjectID(), Stok: 2}
+	if err := repos.Barang.Create(ctx, &barang); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		delta     int
+		err       error
+		stokAkhir int
+	}{
+		{-2, nil, 0},
+		// Pengurangan yang membuat stok negatif ditolak tanpa mengubah stok
+		{-1, ErrStokTidakCukup, 0},
+		{3, nil, 3},
+		{-3, nil, 0},
+	}
+	for _, tt := range tests {
+		if err := repos.Barang.IncrementStok(ctx, barang.ID, tt.delta); !errors.Is(err, tt.err) {
+			t.Errorf("IncrementStok(%d) error = %v, ingin %v", tt.delta, err, tt.err)
+		}
+		b, err := repos.Barang.FindByID(ctx, barang.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Stok != tt.stokAkhir {
+			t.Errorf("stok setelah IncrementStok(%d) = %d, ingin %d", tt.delta, b.Stok, tt.stokAkhir)
+		}
+	}
+
+	if err := repos.Barang.IncrementStok(ctx, primitive.NewObjectID(), 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("IncrementStok barang tidak ada error = %v, ingin ErrNotFound", err)
+	}
+}
+
+// TestMemoryUpdateBarangTidakMengubahStok memastikan stok hanya berubah lewat IncrementStok
+func TestMemoryUpdateBarangTidakMengubahStok(t *testing.T) {
+	repos := NewMemoryRepositories()
+	ctx := context.Background()
+
+	barang := models.Barang{Nama: "Proyektor", KategoriID: primitive.NewObjectID(), Stok: 5}
+	if err := repos.Barang.Create(ctx, &barang); err != nil {
+		t.Fatal(err)
+	}
+	ubah := barang
+	ubah.Nama = "Proyektor LCD"
+	ubah.Stok = 100
+	if err := repos.Barang.Update(ctx, &ubah); err != nil {
+		t.Fatal(err)
+	}
+	b, err := repos.Barang.FindByID(ctx, barang.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Nama != "Proyektor LCD" || b.Stok != 5 {
+		t.Errorf("barang = %q stok %d, ingin %q stok 5", b.Nama, b.Stok, "Proyektor LCD")
+	}
+}
+
+// TestMemoryTransaksiRollback memastikan semua perubahan di dalam transaksi
+// yang gagal dikembalikan, termasuk perubahan di koleksi lain
+func TestMemoryTransaksiRollback(t *testing.T) {
+	repos := NewMemoryRepositories()
+	ctx := context.Background()
+
+	barang := models.Barang{Nama: "Proyektor", KategoriID: primitive.NewObjectID(), Stok: 3}
+	if err := repos.Barang.Create(ctx, &barang); err != nil {
+		t.Fatal(err)
+	}
+
+	gagal := errors.New("gagal")
+	err := repos.Tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := repos.Barang.IncrementStok(ctx, barang.ID, -2); err != nil {
+			return err
+		}
+		p := models.Peminjaman{NamaPeminjam: "Tamu", Items: []models.ItemPeminjaman{{BarangID: barang.ID, Jumlah: 2}}}
+		if err := repos.Peminjaman.Create(ctx, &p); err != nil {
+			return err
+		}
+		return gagal
+	})
+	if !errors.Is(err, gagal) {
+		t.Fatalf("WithTransaction error = %v, ingin %v", err, gagal)
+	}
+
+	b, err := repos.Barang.FindByID(ctx, barang.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Stok != 3 {
+		t.Errorf("stok setelah rollback = %d, ingin 3", b.Stok)
+	}
+	if _, total, err := repos.Peminjaman.FindAll(ctx, PeminjamanFilter{}, ListOptions{}); err != nil || total != 0 {
+		t.Errorf("peminjaman setelah rollback = %d (error %v), ingin 0", total, err)
+	}
+}
+
+// TestMemoryPeminjamanTerisolasi memastikan hasil FindByID bisa diubah
+// pemanggil tanpa ikut mengubah data tersimpan
+func TestMemoryPeminjamanTerisolasi(t *testing.T) {
+	repos := NewMemoryRepositories()
+	ctx := context.Background()
+
+	p := models.Peminjaman{NamaPeminjam: "Tamu", Items: []models.ItemPeminjaman{{BarangID: primitive.NewObjectID(), Jumlah: 1}}}
+	if err := repos.Peminjaman.Create(ctx, &p); err != nil {
+		t.Fatal(err)
+	}
+	hasil, err := repos.Peminjaman.FindByID(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasil.Items[0].Jumlah = 99
+
+	lagi, err := repos.Peminjaman.FindByID(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lagi.Items[0].Jumlah != 1 {
+		t.Errorf("jumlah tersimpan = %d, ingin 1", lagi.Items[0].Jumlah)
+	}
+}
+
+func TestMemoryBarangTerhapusTidakDitemukan(t *testing.T) {
+	repos := NewMemoryRepositories()
+	ctx := context.Background()
+
+	barang := models.Barang{Nama: "Proyektor", KategoriID: primitive.NewObjectID(), Stok: 1}
+	if err := repos.Barang.Create(ctx, &barang); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Barang.Delete(ctx, barang.ID, primitive.NewObjectID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.Barang.FindByID(ctx, barang.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByID barang terhapus error = %v, ingin ErrNotFound", err)
+	}
+	if _, total, _ := repos.Barang.FindAll(ctx, BarangFilter{}, ListOptions{}); total != 0 {
+		t.Errorf("FindAll tanpa TermasukTerhapus = %d, ingin 0", total)
+	}
+	if _, total, _ := repos.Barang.FindAll(ctx, BarangFilter{TermasukTerhapus: true}, ListOptions{}); total != 1 {
+		t.Errorf("FindAll dengan TermasukTerhapus = %d, ingin 1", total)
+	}
+}
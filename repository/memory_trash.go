@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dapatDihapus adalah pointer ke model yang menyematkan models.Terhapus
+type dapatDihapus[T any] interface {
+	*T
+	SudahDihapus() bool
+	DihapusSebelum(batas time.Time) bool
+	TandaiTerhapus(oleh primitive.ObjectID, waktu time.Time)
+	Pulihkan()
+}
+
+// tandaiTerhapusMemory sama seperti tandaiTerhapus untuk koleksi in-memory
+func tandaiTerhapusMemory[T any, PT dapatDihapus[T]](data map[primitive.ObjectID]T, id, oleh primitive.ObjectID) error {
+	item, ok := data[id]
+	if !ok || PT(&item).SudahDihapus() {
+		return ErrNotFound
+	}
+	PT(&item).TandaiTerhapus(oleh, time.Now())
+	data[id] = item
+	return nil
+}
+
+// pulihkanMemory sama seperti pulihkanDokumen untuk koleksi in-memory
+func pulihkanMemory[T any, PT dapatDihapus[T]](data map[primitive.ObjectID]T, id primitive.ObjectID) error {
+	item, ok := data[id]
+	if !ok || !PT(&item).SudahDihapus() {
+		return ErrNotFound
+	}
+	PT(&item).Pulihkan()
+	data[id] = item
+	return nil
+}
+
+// findTerhapusMemory sama seperti findTerhapus untuk koleksi in-memory
+func findTerhapusMemory[T any, PT dapatDihapus[T]](data map[primitive.ObjectID]T, sebelum time.Time, opts ListOptions) ([]T, int64, error) {
+	items := []T{}
+	for _, id := range sortedIDs(data) {
+		item := data[id]
+		if PT(&item).DihapusSebelum(sebelum) {
+			items = append(items, item)
+		}
+	}
+	hasil, err := halaman(items, opts.DenganUrutan("deleted_at", true))
+	if err != nil {
+		return nil, 0, err
+	}
+	return hasil, int64(len(items)), nil
+}
+
+// hapusPermanenMemory sama seperti hapusPermanen untuk koleksi in-memory
+func hapusPermanenMemory[T any, PT dapatDihapus[T]](data map[primitive.ObjectID]T, id primitive.ObjectID) error {
+	item, ok := data[id]
+	if !ok || !PT(&item).SudahDihapus() {
+		return ErrNotFound
+	}
+	delete(data, id)
+	return nil
+}
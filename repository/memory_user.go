@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryUserRepository struct {
+	store *memoryStore
+}
+
+// copyUser menyalin slice kode pemulihan agar data di store tidak ikut berubah
+func copyUser(u models.User) models.User {
+	u.KodePemulihan = append([]string(nil), u.KodePemulihan...)
+	return u
+}
+
+func (r *memoryUserRepository) FindAll(ctx context.Context, filter UserFilter, opts ListOptions) ([]models.User, int64, error) {
+	defer r.store.lock(ctx)()
+
+	q := strings.ToLower(filter.Q)
+	users := []models.User{}
+	for _, id := range sortedIDs(r.store.users) {
+		user := r.store.users[id]
+		if q != "" && !strings.Contains(strings.ToLower(user.Username), q) && !strings.Contains(strings.ToLower(user.Email), q) {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if filter.Nonaktif != nil && user.Nonaktif != *filter.Nonaktif {
+			continue
+		}
+		users = append(users, copyUser(user))
+	}
+
+	hasil, err := halaman(users, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hasil, int64(len(users)), nil
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	user = copyUser(user)
+	return &user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	defer r.store.lock(ctx)()
+
+	for _, id := range sortedIDs(r.store.users) {
+		if user := r.store.users[id]; strings.EqualFold(user.Email, email) {
+			user = copyUser(user)
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	defer r.store.lock(ctx)()
+
+	for _, id := range sortedIDs(r.store.users) {
+		if user := r.store.users[id]; strings.EqualFold(user.Username, username) {
+			user = copyUser(user)
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	defer r.store.lock(ctx)()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if err := r.cekUnik(user); err != nil {
+		return err
+	}
+	r.store.users[user.ID] = copyUser(*user)
+	return nil
+}
+
+// cekUnik meniru index unik username dan email di MongoDB
+func (r *memoryUserRepository) cekUnik(user *models.User) error {
+	for id, lain := range r.store.users {
+		if id == user.ID {
+			continue
+		}
+		if strings.EqualFold(lain.Username, user.Username) {
+			return ErrUsernameDipakai
+		}
+		if strings.EqualFold(lain.Email, user.Email) {
+			return ErrEmailDipakai
+		}
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, id primitive.ObjectID, ubah UserUpdate) (*models.User, error) {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	user = copyUser(user)
+	terapkanUserUpdate(&user, ubah)
+	if err := r.cekUnik(&user); err != nil {
+		return nil, err
+	}
+	r.store.users[id] = user
+	user = copyUser(user)
+	return &user, nil
+}
+
+// terapkanUserUpdate mengisi field user yang diisi di ubah
+func terapkanUserUpdate(user *models.User, ubah UserUpdate) {
+	if ubah.Username != nil {
+		user.Username = *ubah.Username
+	}
+	if ubah.Email != nil {
+		user.Email = *ubah.Email
+	}
+	if ubah.NamaLengkap != nil {
+		user.NamaLengkap = *ubah.NamaLengkap
+	}
+	if ubah.Telepon != nil {
+		user.Telepon = *ubah.Telepon
+	}
+	if ubah.Password != nil {
+		user.Password = *ubah.Password
+	}
+	if ubah.Role != nil {
+		user.Role = *ubah.Role
+	}
+	if ubah.OIDCSubject != nil {
+		user.OIDCSubject = *ubah.OIDCSubject
+	}
+	if ubah.Nonaktif != nil {
+		user.Nonaktif = *ubah.Nonaktif
+	}
+	if ubah.HarusGantiPassword != nil {
+		user.HarusGantiPassword = *ubah.HarusGantiPassword
+	}
+	if ubah.BelumVerifikasi != nil {
+		user.BelumVerifikasi = *ubah.BelumVerifikasi
+	}
+	user.UpdatedAt = time.Now()
+}
+
+func (r *memoryUserRepository) PakaiLangkahTOTP(ctx context.Context, id primitive.ObjectID, langkah int64) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok || user.TOTPLangkah >= langkah {
+		return false, nil
+	}
+	user.TOTPLangkah = langkah
+	user.UpdatedAt = time.Now()
+	r.store.users[id] = user
+	return true, nil
+}
+
+func (r *memoryUserRepository) PakaiKodePemulihan(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return false, nil
+	}
+	i := slices.Index(user.KodePemulihan, hash)
+	if i < 0 {
+		return false, nil
+	}
+	user.KodePemulihan = slices.Delete(slices.Clone(user.KodePemulihan), i, i+1)
+	user.UpdatedAt = time.Now()
+	r.store.users[id] = user
+	return true, nil
+}
+
+func (r *memoryUserRepository) SimpanSecretTOTP(ctx context.Context, id primitive.ObjectID, secret string) error {
+	return r.ubah2FA(ctx, id, func(user *models.User) bool {
+		if user.TOTPAktif {
+			return false
+		}
+		user.TOTPSecret = secret
+		user.TOTPLangkah = 0
+		return true
+	})
+}
+
+func (r *memoryUserRepository) AktifkanTOTP(ctx context.Context, id primitive.ObjectID, kodePemulihan []string) error {
+	return r.ubah2FA(ctx, id, func(user *models.User) bool {
+		if user.TOTPAktif {
+			return false
+		}
+		user.TOTPAktif = true
+		user.KodePemulihan = append([]string(nil), kodePemulihan...)
+		return true
+	})
+}
+
+func (r *memoryUserRepository) GantiKodePemulihan(ctx context.Context, id primitive.ObjectID, kodePemulihan []string) error {
+	return r.ubah2FA(ctx, id, func(user *models.User) bool {
+		if !user.TOTPAktif {
+			return false
+		}
+		user.KodePemulihan = append([]string(nil), kodePemulihan...)
+		return true
+	})
+}
+
+func (r *memoryUserRepository) Hapus2FA(ctx context.Context, id primitive.ObjectID) error {
+	return r.ubah2FA(ctx, id, func(user *models.User) bool {
+		user.TOTPAktif = false
+		user.TOTPSecret = ""
+		user.TOTPLangkah = 0
+		user.KodePemulihan = nil
+		return true
+	})
+}
+
+// ubah2FA menerapkan ubah pada user jika ada dan ubah mengembalikan true,
+// selain itu mengembalikan ErrNotFound
+func (r *memoryUserRepository) ubah2FA(ctx context.Context, id primitive.ObjectID, ubah func(user *models.User) bool) error {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user = copyUser(user)
+	if !ubah(&user) {
+		return ErrNotFound
+	}
+	user.UpdatedAt = time.Now()
+	r.store.users[id] = user
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.users, id)
+	return nil
+}
+
+func (r *memoryUserRepository) KunciAdminAktif(ctx context.Context) error {
+	// Transaksi in-memory sudah berjalan satu per satu di bawah kunci store
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoAPIKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (r *mongoAPIKeyRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *mongoAPIKeyRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *mongoAPIKeyRepository) Revoke(ctx context.Context, id, userID primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID},
+		bson.A{bson.M{"$set": bson.M{"revoked_at": bson.M{"$ifNull": bson.A{"$revoked_at", time.Now()}}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoAPIKeyRepository) TandaiDipakai(ctx context.Context, id primitive.ObjectID, ip string, waktu time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"last_used_at": waktu, "last_used_ip": ip}},
+	)
+	return err
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoAuditLogRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAuditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	if log.ID.IsZero() {
+		log.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, log)
+	return err
+}
+
+func (r *mongoAuditLogRepository) FindAll(ctx context.Context, filter AuditFilter, opts ListOptions) ([]models.AuditLog, int64, error) {
+	query := bson.M{}
+	if filter.UserID != nil {
+		query["user_id"] = *filter.UserID
+	}
+	if filter.Aksi != "" {
+		query["aksi"] = filter.Aksi
+	}
+	if filter.Resource != "" {
+		query["resource"] = filter.Resource
+	}
+	if filter.ResourceID != nil {
+		query["resource_id"] = *filter.ResourceID
+	}
+	if filter.RequestID != "" {
+		query["request_id"] = filter.RequestID
+	}
+	tanggal := bson.M{}
+	if !filter.Dari.IsZero() {
+		tanggal["$gte"] = filter.Dari
+	}
+	if !filter.Sampai.IsZero() {
+		tanggal["$lt"] = filter.Sampai
+	}
+	if len(tanggal) > 0 {
+		query["tanggal"] = tanggal
+	}
+
+	logs := []models.AuditLog{}
+	total, err := findHalaman(ctx, r.collection, query, opts.DenganUrutan("tanggal", true), &logs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
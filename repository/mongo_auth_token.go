@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoAuthTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAuthTokenRepository) Create(ctx context.Context, token *models.AuthToken) error {
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *mongoAuthTokenRepository) Pakai(ctx context.Context, jenis, tokenHash string) (*models.AuthToken, error) {
+	now := time.Now()
+	filter := bson.M{
+		"token_hash": tokenHash,
+		"jenis":      jenis,
+		"used_at":    nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	var token models.AuthToken
+	err := r.collection.FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"used_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *mongoAuthTokenRepository) BatalkanMilikUser(ctx context.Context, userID primitive.ObjectID, jenis string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "jenis": jenis, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	)
+	return err
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoBarangRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoBarangRepository) FindAll(ctx context.Context, filter BarangFilter, opts ListOptions) ([]models.Barang, int64, error) {
+	query := bson.M{}
+	if !filter.TermasukTerhapus {
+		belumDihapus(query)
+	}
+	if filter.KategoriID != nil {
+		query["kategori_id"] = *filter.KategoriID
+	}
+	stok := bson.M{}
+	if filter.StokMin != nil {
+		stok["$gte"] = *filter.StokMin
+	}
+	if filter.StokMax != nil {
+		stok["$lte"] = *filter.StokMax
+	}
+	if len(stok) > 0 {
+		query["stok"] = stok
+	}
+	if filter.Nama.Teks != "" {
+		query["nama"] = regexCari(filter.Nama)
+	}
+	if filter.Teks != "" {
+		// Memakai text index atas nama barang
+		query["$text"] = bson.M{"$search": filter.Teks}
+	}
+
+	barang := []models.Barang{}
+	total, err := findHalaman(ctx, r.collection, query, opts, &barang)
+	if err != nil {
+		return nil, 0, err
+	}
+	return barang, total, nil
+}
+
+func (r *mongoBarangRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Barang, error) {
+	var barang models.Barang
+	err := r.collection.FindOne(ctx, belumDihapus(bson.M{"_id": id})).Decode(&barang)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &barang, nil
+}
+
+func (r *mongoBarangRepository) Create(ctx context.Context, barang *models.Barang) error {
+	_, err := r.collection.InsertOne(ctx, barang)
+	return err
+}
+
+func (r *mongoBarangRepository) Update(ctx context.Context, barang *models.Barang) error {
+	update := bson.M{
+		"$set": bson.M{
+			"nama":         barang.Nama,
+			"kategori_id":  barang.KategoriID,
+			"tanggal_buat": barang.TanggalBuat,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, belumDihapus(bson.M{"_id": barang.ID}), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoBarangRepository) Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error {
+	return tandaiTerhapus(ctx, r.collection, id, oleh)
+}
+
+func (r *mongoBarangRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return pulihkanDokumen(ctx, r.collection, id)
+}
+
+func (r *mongoBarangRepository) FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Barang, int64, error) {
+	barang := []models.Barang{}
+	total, err := findTerhapus(ctx, r.collection, sebelum, opts, &barang)
+	if err != nil {
+		return nil, 0, err
+	}
+	return barang, total, nil
+}
+
+func (r *mongoBarangRepository) HapusPermanen(ctx context.Context, id primitive.ObjectID) error {
+	return hapusPermanen(ctx, r.collection, id)
+}
+
+func (r *mongoBarangRepository) PindahKategori(ctx context.Context, dari, ke primitive.ObjectID) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx, bson.M{"kategori_id": dari}, bson.M{"$set": bson.M{"kategori_id": ke}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *mongoBarangRepository) DeleteByKategori(ctx context.Context, kategoriID, oleh primitive.ObjectID) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		belumDihapus(bson.M{"kategori_id": kategoriID}),
+		bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": oleh}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// filterIncrementStok mencocokkan barang id hanya jika stoknya cukup untuk
+// ditambah delta. Kondisi stok dicek di server MongoDB, bukan di Go.
+func filterIncrementStok(id primitive.ObjectID, delta int) bson.M {
+	filter := bson.M{"_id": id}
+	if delta < 0 {
+		filter["stok"] = bson.M{"$gte": -delta}
+	}
+	return filter
+}
+
+// updateIncrementStok menambah stok dengan delta secara atomik
+func updateIncrementStok(delta int) bson.M {
+	return bson.M{"$inc": bson.M{"stok": delta}}
+}
+
+func (r *mongoBarangRepository) IncrementStok(ctx context.Context, id primitive.ObjectID, delta int) error {
+	result, err := r.collection.UpdateOne(ctx, filterIncrementStok(id, delta), updateIncrementStok(delta))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	// Bedakan barang yang tidak ada dengan stok yang tidak cukup
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return ErrStokTidakCukup
+}
+
+func (r *mongoBarangRepository) IncrementStokTidakTersedia(ctx context.Context, id primitive.ObjectID, rusak, hilang int) error {
+	update := bson.M{"$inc": bson.M{"stok_rusak": rusak, "stok_hilang": hilang}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFilterIncrementStok(t *testing.T) {
+	id := primitive.NewObjectID()
+	tests := []struct {
+		delta int
+		ingin bson.M
+	}{
+		// Pengurangan hanya cocok jika stok tidak akan menjadi negatif
+		{-3, bson.M{"_id": id, "stok": bson.M{"$gte": 3}}},
+		{0, bson.M{"_id": id}},
+		{2, bson.M{"_id": id}},
+	}
+	for _, tt := range tests {
+		if got := filterIncrementStok(id, tt.delta); !reflect.DeepEqual(got, tt.ingin) {
+			t.Errorf("filterIncrementStok(%d) = %v, ingin %v", tt.delta, got, tt.ingin)
+		}
+	}
+}
+
+func TestUpdateIncrementStok(t *testing.T) {
+	ingin := bson.M{"$inc": bson.M{"stok": -3}}
+	if got := updateIncrementStok(-3); !reflect.DeepEqual(got, ingin) {
+		t.Errorf("updateIncrementStok(-3) = %v, ingin %v", got, ingin)
+	}
+}
+
+// TestGuardIncrementStok memeriksa pasangan filter dan update untuk kombinasi
+// stok dan delta: setiap dokumen yang cocok dengan filter tidak boleh punya
+// stok negatif setelah $inc diterapkan, dan dokumen yang stoknya cukup harus cocok.
+func TestGuardIncrementStok(t *testing.T) {
+	id := primitive.NewObjectID()
+	for stok := 0; stok <= 5; stok++ {
+		for delta := -6; delta <= 6; delta++ {
+			filter := filterIncrementStok(id, delta)
+			cocok := true
+			if kondisi, ada := filter["stok"].(bson.M); ada {
+				cocok = stok >= kondisi["$gte"].(int)
+			}
+			inc := updateIncrementStok(delta)["$inc"].(bson.M)["stok"].(int)
+
+			if cocok && stok+inc < 0 {
+				t.Errorf("stok %d delta %d: filter cocok tapi stok menjadi %d", stok, delta, stok+inc)
+			}
+			if !cocok && stok+delta >= 0 {
+				t.Errorf("stok %d delta %d: stok cukup tapi filter tidak cocok", stok, delta)
+			}
+		}
+	}
+}
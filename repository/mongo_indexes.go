@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureMongoIndexes membuat index yang dibutuhkan repository jika belum ada
+func EnsureMongoIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		"api_keys": {
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		},
+		"audit_logs": {
+			{Keys: bson.D{{Key: "tanggal", Value: -1}}},
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "tanggal", Value: -1}}},
+			// Riwayat perubahan satu data dan semua catatan dari satu request
+			{Keys: bson.D{{Key: "resource", Value: 1}, {Key: "resource_id", Value: 1}, {Key: "tanggal", Value: -1}}},
+			{Keys: bson.D{{Key: "request_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		},
+		"auth_tokens": {
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}},
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "jenis", Value: 1}}},
+			// Token dihapus otomatis oleh MongoDB setelah kedaluwarsa
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"barang": {
+			{Keys: bson.D{{Key: "nama", Value: 1}}},
+			{Keys: bson.D{{Key: "stok", Value: 1}}},
+			{Keys: bson.D{{Key: "tanggal_buat", Value: 1}}},
+			{Keys: bson.D{{Key: "kategori_id", Value: 1}}},
+			// Text index untuk parameter q; tanpa stemming karena nama barang berbahasa Indonesia
+			{Keys: bson.D{{Key: "nama", Value: "text"}}, Options: options.Index().SetDefaultLanguage("none")},
+			// Isi tempat sampah
+			{Keys: bson.D{{Key: "deleted_at", Value: -1}}, Options: options.Index().SetSparse(true)},
+		},
+		"kategori": {
+			{Keys: bson.D{{Key: "nama", Value: 1}}},
+			{Keys: bson.D{{Key: "tanggal_buat", Value: 1}}},
+			// Isi tempat sampah
+			{Keys: bson.D{{Key: "deleted_at", Value: -1}}, Options: options.Index().SetSparse(true)},
+		},
+		"login_attempts": {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"login_history": {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "tanggal", Value: -1}}},
+		},
+		"peminjaman": {
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "tanggal_jatuh_tempo", Value: 1}}},
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			{Keys: bson.D{{Key: "tanggal_pinjam", Value: 1}}},
+			{Keys: bson.D{{Key: "tanggal_jatuh_tempo", Value: 1}}},
+			{Keys: bson.D{{Key: "nama_peminjam", Value: 1}}},
+			{Keys: bson.D{{Key: "email_peminjam", Value: 1}}},
+			{Keys: bson.D{{Key: "items.barang_id", Value: 1}}},
+			// Isi tempat sampah
+			{Keys: bson.D{{Key: "deleted_at", Value: -1}}, Options: options.Index().SetSparse(true)},
+		},
+		"roles": {
+			{Keys: bson.D{{Key: "nama", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		"sessions": {
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			// Sesi dihapus otomatis oleh MongoDB setelah kedaluwarsa
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"stock_movements": {
+			{Keys: bson.D{{Key: "barang_id", Value: 1}, {Key: "tanggal", Value: -1}}},
+		},
+		"users": {
+			// Username dan email unik tanpa membedakan huruf besar/kecil
+			{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetName(indexUsernameUnik).SetUnique(true).SetCollation(collationTanpaKapital)},
+			{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetName(indexEmailUnik).SetUnique(true).SetCollation(collationTanpaKapital)},
+			{Keys: bson.D{{Key: "created_at", Value: 1}}},
+		},
+	}
+
+	// Index lama yang sudah digantikan index lain
+	usang := map[string][]string{
+		"users": {"username_1", "email_1"},
+	}
+
+	var errs []error
+	for collection, nama := range usang {
+		for _, n := range nama {
+			if _, err := db.Collection(collection).Indexes().DropOne(ctx, n); err != nil && !indexTidakAda(err) {
+				errs = append(errs, fmt.Errorf("%s.%s: %w", collection, n, err))
+			}
+		}
+	}
+	// Kegagalan satu collection (mis. data duplikat yang menghalangi index unik)
+	// tidak menghentikan pembuatan index di collection lain
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", collection, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// indexTidakAda bernilai true untuk error drop index yang index atau
+// collection-nya memang belum ada
+func indexTidakAda(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 26 || cmdErr.Code == 27)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoKategoriRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoKategoriRepository) FindAll(ctx context.Context, opts ListOptions) ([]models.Kategori, int64, error) {
+	kategori := []models.Kategori{}
+	total, err := findHalaman(ctx, r.collection, belumDihapus(bson.M{}), opts, &kategori)
+	if err != nil {
+		return nil, 0, err
+	}
+	return kategori, total, nil
+}
+
+func (r *mongoKategoriRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Kategori, error) {
+	var kategori models.Kategori
+	err := r.collection.FindOne(ctx, belumDihapus(bson.M{"_id": id})).Decode(&kategori)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &kategori, nil
+}
+
+func (r *mongoKategoriRepository) Create(ctx context.Context, kategori *models.Kategori) error {
+	_, err := r.collection.InsertOne(ctx, kategori)
+	return err
+}
+
+func (r *mongoKategoriRepository) Update(ctx context.Context, kategori *models.Kategori) error {
+	update := bson.M{
+		"$set": bson.M{
+			"nama":         kategori.Nama,
+			"deskripsi":    kategori.Deskripsi,
+			"tanggal_buat": kategori.TanggalBuat,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, belumDihapus(bson.M{"_id": kategori.ID}), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoKategoriRepository) Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error {
+	return tandaiTerhapus(ctx, r.collection, id, oleh)
+}
+
+func (r *mongoKategoriRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return pulihkanDokumen(ctx, r.collection, id)
+}
+
+func (r *mongoKategoriRepository) FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Kategori, int64, error) {
+	kategori := []models.Kategori{}
+	total, err := findTerhapus(ctx, r.collection, sebelum, opts, &kategori)
+	if err != nil {
+		return nil, 0, err
+	}
+	return kategori, total, nil
+}
+
+func (r *mongoKategoriRepository) HapusPermanen(ctx context.Context, id primitive.ObjectID) error {
+	return hapusPermanen(ctx, r.collection, id)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findHalaman menjalankan query dengan urutan, keyset dan pagination dari opts
+// lalu men-decode hasilnya ke hasil (pointer ke slice). Mengembalikan jumlah
+// seluruh dokumen yang cocok dengan query, tanpa memperhitungkan halaman.
+func findHalaman(ctx context.Context, collection *mongo.Collection, query bson.M, opts ListOptions, hasil interface{}) (int64, error) {
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	field := opts.Sort
+	if field == "" {
+		field = "_id"
+	}
+	arah, op := 1, "$gt"
+	if opts.Desc {
+		arah, op = -1, "$lt"
+	}
+	urutan := bson.D{{Key: field, Value: arah}}
+	if field == UrutRelevansi {
+		// Skor relevansi hanya ada untuk query $text dan tidak bisa dipakai sebagai keyset
+		if opts.After != nil {
+			return 0, ErrCursorTidakValid
+		}
+		urutan = bson.D{{Key: "skor", Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: 1}}
+	} else if field != "_id" {
+		urutan = append(urutan, bson.E{Key: "_id", Value: arah})
+	}
+
+	filter := query
+	if opts.After != nil {
+		// Nilai field urutan dokumen after menjadi titik awal halaman berikutnya
+		var after bson.M
+		err := collection.FindOne(ctx,
+			bson.M{"$and": bson.A{query, bson.M{"_id": *opts.After}}},
+			options.FindOne().SetProjection(bson.M{field: 1}),
+		).Decode(&after)
+		if err == mongo.ErrNoDocuments {
+			return 0, ErrCursorTidakValid
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		setelah := bson.M{"_id": bson.M{op: *opts.After}}
+		if field != "_id" {
+			setelah = bson.M{"$or": bson.A{
+				bson.M{field: bson.M{op: after[field]}},
+				bson.M{field: after[field], "_id": bson.M{op: *opts.After}},
+			}}
+		}
+		filter = bson.M{"$and": bson.A{query, setelah}}
+	}
+
+	findOptions := options.Find().
+		SetSort(urutan).
+		SetSkip(int64(opts.Skip()))
+	if opts.Limit > 0 {
+		findOptions.SetLimit(int64(opts.Limit))
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, hasil); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// regexCari membuat regex dari teks pencarian. Karakter khusus regex di-escape
+// sehingga teks selalu dicocokkan apa adanya.
+func regexCari(cari Pencarian) primitive.Regex {
+	pola := regexp.QuoteMeta(cari.Teks)
+	if cari.Awalan {
+		pola = "^" + pola
+	}
+	return primitive.Regex{Pattern: pola, Options: "i"}
+}
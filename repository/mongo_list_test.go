@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+)
+
+// TestRegexCari memastikan karakter khusus regex di teks pencarian selalu
+// dicocokkan apa adanya
+func TestRegexCari(t *testing.T) {
+	tests := []struct {
+		cari  Pencarian
+		pola  string
+		cocok []string
+		tidak []string
+	}{
+		{Pencarian{Teks: "proy"}, "proy", []string{"Proyektor", "LCD proyektor"}, []string{"Laptop"}},
+		{Pencarian{Teks: "proy", Awalan: true}, "^proy", []string{"Proyektor"}, []string{"LCD proyektor"}},
+		{Pencarian{Teks: "a.c"}, `a\.c`, []string{"a.c"}, []string{"abc"}},
+		{Pencarian{Teks: "kabel (hdmi"}, `kabel \(hdmi`, []string{"Kabel (HDMI) 2m"}, []string{"Kabel HDMI"}},
+		{Pencarian{Teks: ".*"}, `\.\*`, []string{"nilai .* aneh"}, []string{"Proyektor"}},
+	}
+	for _, tt := range tests {
+		got := regexCari(tt.cari)
+		if got.Pattern != tt.pola || got.Options != "i" {
+			t.Errorf("regexCari(%+v) = %q/%q, ingin %q/i", tt.cari, got.Pattern, got.Options, tt.pola)
+			continue
+		}
+		re := regexp.MustCompile("(?i)" + got.Pattern)
+		for _, nilai := range tt.cocok {
+			if !re.MatchString(nilai) || !cocokCari(nilai, tt.cari) {
+				t.Errorf("%q harus cocok dengan %+v", nilai, tt.cari)
+			}
+		}
+		for _, nilai := range tt.tidak {
+			if re.MatchString(nilai) || cocokCari(nilai, tt.cari) {
+				t.Errorf("%q tidak boleh cocok dengan %+v", nilai, tt.cari)
+			}
+		}
+	}
+}
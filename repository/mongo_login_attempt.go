@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoLoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoLoginAttemptRepository) Find(ctx context.Context, kunci string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := r.collection.FindOne(ctx, bson.M{"_id": kunci}).Decode(&attempt)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *mongoLoginAttemptRepository) CatatGagal(ctx context.Context, kunci string, jendela time.Duration, expiresAt time.Time) (*models.LoginAttempt, error) {
+	now := time.Now()
+	// Update pipeline agar cek jendela dan penambahan hitungan terjadi dalam
+	// satu operasi atomik; dokumen baru dibuat lewat upsert
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"gagal": bson.M{"$cond": bson.A{
+				bson.M{"$lt": bson.A{"$terakhir_gagal", now.Add(-jendela)}},
+				1,
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$gagal", 0}}, 1}},
+			}},
+			"terakhir_gagal": now,
+			"expires_at":     expiresAt,
+		}}},
+	}
+
+	var attempt models.LoginAttempt
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": kunci}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempt)
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *mongoLoginAttemptRepository) Kunci(ctx context.Context, kunci string, sampai time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": kunci},
+		bson.M{"$set": bson.M{"terkunci_sampai": sampai}, "$max": bson.M{"expires_at": sampai}},
+	)
+	return err
+}
+
+func (r *mongoLoginAttemptRepository) Reset(ctx context.Context, kunci string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": kunci})
+	return err
+}
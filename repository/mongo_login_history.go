@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoLoginHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoLoginHistoryRepository) Create(ctx context.Context, history *models.LoginHistory) error {
+	if history.ID.IsZero() {
+		history.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, history)
+	return err
+}
+
+func (r *mongoLoginHistoryRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, opts ListOptions) ([]models.LoginHistory, int64, error) {
+	riwayat := []models.LoginHistory{}
+	total, err := findHalaman(ctx, r.collection, bson.M{"user_id": userID}, opts.DenganUrutan("tanggal", true), &riwayat)
+	if err != nil {
+		return nil, 0, err
+	}
+	return riwayat, total, nil
+}
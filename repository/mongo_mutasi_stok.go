@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoMutasiStokRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoMutasiStokRepository) Create(ctx context.Context, mutasi *models.MutasiStok) error {
+	if mutasi.ID.IsZero() {
+		mutasi.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, mutasi)
+	return err
+}
+
+func (r *mongoMutasiStokRepository) FindByBarang(ctx context.Context, barangID primitive.ObjectID, opts ListOptions) ([]models.MutasiStok, int64, error) {
+	mutasi := []models.MutasiStok{}
+	total, err := findHalaman(ctx, r.collection, bson.M{"barang_id": barangID}, opts.DenganUrutan("tanggal", true), &mutasi)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mutasi, total, nil
+}
+
+func (r *mongoMutasiStokRepository) SumPerBarang(ctx context.Context) (map[primitive.ObjectID]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$barang_id",
+			"total": bson.M{"$sum": "$delta"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var hasil []struct {
+		BarangID primitive.ObjectID `bson:"_id"`
+		Total    int                `bson:"total"`
+	}
+	if err := cursor.All(ctx, &hasil); err != nil {
+		return nil, err
+	}
+
+	total := map[primitive.ObjectID]int{}
+	for _, h := range hasil {
+		total[h.BarangID] = h.Total
+	}
+	return total, nil
+}
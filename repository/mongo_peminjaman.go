@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoPeminjamanRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoPeminjamanRepository) FindAll(ctx context.Context, filter PeminjamanFilter, opts ListOptions) ([]models.Peminjaman, int64, error) {
+	query := bson.M{}
+	if !filter.TermasukTerhapus {
+		belumDihapus(query)
+	}
+	// Kondisi $or lebih dari satu digabung dengan $and
+	var semua bson.A
+
+	if filter.Cari.Teks != "" {
+		pola := regexCari(filter.Cari)
+		atau := bson.A{
+			bson.M{"nama_peminjam": pola},
+			bson.M{"email_peminjam": pola},
+			bson.M{"telepon_peminjam": pola},
+		}
+		barangIDs, err := r.cariBarang(ctx, pola)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(barangIDs) > 0 {
+			atau = append(atau,
+				bson.M{"items.barang_id": bson.M{"$in": barangIDs}},
+				bson.M{"barang_id": bson.M{"$in": barangIDs}},
+			)
+		}
+		semua = append(semua, bson.M{"$or": atau})
+	}
+	if filter.UserID != nil {
+		query["user_id"] = *filter.UserID
+	}
+	if len(filter.Status) > 0 {
+		query["status"] = bson.M{"$in": filter.Status}
+	}
+	if len(filter.BarangID) > 0 {
+		// Data format lama menyimpan barang_id di luar items
+		semua = append(semua, bson.M{"$or": bson.A{
+			bson.M{"items.barang_id": bson.M{"$in": filter.BarangID}},
+			bson.M{"barang_id": bson.M{"$in": filter.BarangID}},
+		}})
+	}
+	if filter.EmailPeminjam != "" {
+		query["email_peminjam"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.EmailPeminjam) + "$", Options: "i"}
+	}
+	tanggal := bson.M{}
+	if filter.TanggalDari != "" {
+		tanggal["$gte"] = filter.TanggalDari
+	}
+	if filter.TanggalSampai != "" {
+		// tanggal_pinjam berformat "YYYY-MM-DD HH:MM:SS", jadi dibandingkan sebagai string
+		tanggal["$lte"] = filter.TanggalSampai + " 23:59:59"
+	}
+	if len(tanggal) > 0 {
+		query["tanggal_pinjam"] = tanggal
+	}
+	if len(semua) > 0 {
+		query["$and"] = semua
+	}
+
+	peminjaman := []models.Peminjaman{}
+	total, err := findHalaman(ctx, r.collection, query, opts, &peminjaman)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range peminjaman {
+		peminjaman[i].Normalisasi()
+	}
+	return peminjaman, total, nil
+}
+
+// cariBarang mengembalikan ID barang yang namanya cocok dengan pola
+func (r *mongoPeminjamanRepository) cariBarang(ctx context.Context, pola primitive.Regex) ([]primitive.ObjectID, error) {
+	cursor, err := r.collection.Database().Collection("barang").Find(ctx,
+		bson.M{"nama": pola},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var hasil []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &hasil); err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(hasil))
+	for _, b := range hasil {
+		ids = append(ids, b.ID)
+	}
+	return ids, nil
+}
+
+func (r *mongoPeminjamanRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Peminjaman, error) {
+	var peminjaman models.Peminjaman
+	err := r.collection.FindOne(ctx, belumDihapus(bson.M{"_id": id})).Decode(&peminjaman)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	peminjaman.Normalisasi()
+	return &peminjaman, nil
+}
+
+func (r *mongoPeminjamanRepository) Create(ctx context.Context, peminjaman *models.Peminjaman) error {
+	_, err := r.collection.InsertOne(ctx, peminjaman)
+	return err
+}
+
+func (r *mongoPeminjamanRepository) Update(ctx context.Context, peminjaman *models.Peminjaman) error {
+	result, err := r.collection.ReplaceOne(ctx, belumDihapus(bson.M{"_id": peminjaman.ID}), peminjaman)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPeminjamanRepository) Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error {
+	return tandaiTerhapus(ctx, r.collection, id, oleh)
+}
+
+func (r *mongoPeminjamanRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return pulihkanDokumen(ctx, r.collection, id)
+}
+
+func (r *mongoPeminjamanRepository) FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Peminjaman, int64, error) {
+	peminjaman := []models.Peminjaman{}
+	total, err := findTerhapus(ctx, r.collection, sebelum, opts, &peminjaman)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range peminjaman {
+		peminjaman[i].Normalisasi()
+	}
+	return peminjaman, total, nil
+}
+
+func (r *mongoPeminjamanRepository) HapusPermanen(ctx context.Context, id primitive.ObjectID) error {
+	return hapusPermanen(ctx, r.collection, id)
+}
+
+func (r *mongoPeminjamanRepository) FindTerlambat(ctx context.Context, hariIni string) ([]models.Peminjaman, error) {
+	filter := belumDihapus(bson.M{
+		"status":              models.StatusDipinjam,
+		"tanggal_jatuh_tempo": bson.M{"$gt": "", "$lt": hariIni},
+	})
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"tanggal_jatuh_tempo": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	peminjaman := []models.Peminjaman{}
+	if err := cursor.All(ctx, &peminjaman); err != nil {
+		return nil, err
+	}
+	for i := range peminjaman {
+		peminjaman[i].Normalisasi()
+	}
+	return peminjaman, nil
+}
+
+func (r *mongoPeminjamanRepository) Laporan(ctx context.Context) ([]bson.M, error) {
+	// Pipeline aggregation untuk join ke barang dan kategori, satu baris per item
+	pipeline := mongo.Pipeline{
+		// Peminjaman di tempat sampah tidak ikut laporan
+		{{Key: "$match", Value: belumDihapus(bson.M{})}},
+		// Peminjaman format lama belum punya items
+		{{Key: "$addFields", Value: bson.M{
+			"items": bson.M{"$ifNull": bson.A{"$items", bson.A{bson.M{
+				"barang_id":      "$barang_id",
+				"jumlah":         "$jumlah",
+				"jumlah_kembali": 0,
+			}}}},
+		}}},
+		// Pecah items menjadi satu dokumen per baris
+		{{Key: "$unwind", Value: "$items"}},
+		// barang_id dan jumlah di level atas tetap ada seperti format lama
+		{{Key: "$addFields", Value: bson.M{
+			"barang_id": "$items.barang_id",
+			"jumlah":    "$items.jumlah",
+		}}},
+		// Join dengan koleksi barang
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "barang",
+			"localField":   "barang_id",
+			"foreignField": "_id",
+			"as":           "barang_info",
+		}}},
+		// Unwind array barang_info jadi objek tunggal. Item yang barangnya sudah
+		// tidak ada tetap ikut laporan tanpa barang_info.
+		{{Key: "$unwind", Value: bson.M{"path": "$barang_info", "preserveNullAndEmptyArrays": true}}},
+		// Join dengan kategori
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "kategori",
+			"localField":   "barang_info.kategori_id",
+			"foreignField": "_id",
+			"as":           "kategori_info",
+		}}},
+		// Unwind kategori, baris tanpa kategori tetap ikut
+		{{Key: "$unwind", Value: bson.M{"path": "$kategori_info", "preserveNullAndEmptyArrays": true}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	hasil := []bson.M{}
+	if err := cursor.All(ctx, &hasil); err != nil {
+		return nil, err
+	}
+	return hasil, nil
+}
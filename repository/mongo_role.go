@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRoleRepository struct {
+	collection *mongo.Collection
+	// kunci menyimpan dokumen penanda yang ditulis KunciRole
+	kunci *mongo.Collection
+}
+
+func (r *mongoRoleRepository) FindAll(ctx context.Context) ([]models.Role, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "nama", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []models.Role{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *mongoRoleRepository) findOne(ctx context.Context, filter bson.M) (*models.Role, error) {
+	var role models.Role
+	err := r.collection.FindOne(ctx, filter).Decode(&role)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *mongoRoleRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Role, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoRoleRepository) FindByNama(ctx context.Context, nama string) (*models.Role, error) {
+	return r.findOne(ctx, bson.M{"nama": nama})
+}
+
+func (r *mongoRoleRepository) Create(ctx context.Context, role *models.Role) error {
+	if role.ID.IsZero() {
+		role.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, role)
+	return err
+}
+
+func (r *mongoRoleRepository) Update(ctx context.Context, role *models.Role) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": role.ID}, role)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoRoleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *mongoRoleRepository) KunciRole(ctx context.Context, nama string) error {
+	// Membaca dokumen role tidak membuat transaksi bentrok, jadi pemberian dan
+	// penghapusan role sama-sama menulis dokumen penanda yang sama
+	_, err := r.kunci.UpdateOne(ctx,
+		bson.M{"_id": "role:" + nama},
+		bson.M{"$inc": bson.M{"versi": 1}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSessionRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *mongoSessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *mongoSessionRepository) FindAktifByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error) {
+	filter := bson.M{"user_id": userID, "revoked_at": nil, "expires_at": bson.M{"$gt": time.Now()}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *mongoSessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, hashLama, hashBaru string, expiresAt time.Time) error {
+	// Hash lama dicek di filter agar dua refresh paralel dengan token yang sama
+	// tidak bisa sama-sama berhasil
+	filter := bson.M{"_id": id, "refresh_token_hash": hashLama, "revoked_at": nil}
+	update := bson.M{
+		"$set": bson.M{
+			"refresh_token_hash": hashBaru,
+			"expires_at":         expiresAt,
+			"last_used_at":       time.Now(),
+		},
+		// Hanya MaksRefreshTokenLama hash terakhir yang disimpan
+		"$push": bson.M{"refresh_token_lama": bson.M{
+			"$each":  bson.A{hashLama},
+			"$slice": -models.MaksRefreshTokenLama,
+		}},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoSessionRepository) Revoke(ctx context.Context, id primitive.ObjectID, alasan string) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now(), "alasan_revoke": alasan}},
+	)
+	return err
+}
+
+func (r *mongoSessionRepository) RevokeByUser(ctx context.Context, userID primitive.ObjectID, kecuali *primitive.ObjectID, alasan string) (int64, error) {
+	filter := bson.M{"user_id": userID, "revoked_at": nil}
+	if kecuali != nil {
+		filter["_id"] = bson.M{"$ne": *kecuali}
+	}
+
+	result, err := r.collection.UpdateMany(ctx, filter,
+		bson.M{"$set": bson.M{"revoked_at": time.Now(), "alasan_revoke": alasan}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
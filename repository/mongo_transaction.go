@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mongoTransactor memakai multi-document transaction MongoDB.
+// Membutuhkan replica set (MongoDB Atlas sudah memenuhinya).
+type mongoTransactor struct {
+	client *mongo.Client
+}
+
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// WithTransaction otomatis mengulang transaksi yang bentrok (write conflict)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// belumDihapus menambahkan syarat dokumen tidak ada di tempat sampah ke query
+func belumDihapus(query bson.M) bson.M {
+	query["deleted_at"] = nil
+	return query
+}
+
+// tandaiTerhapus memindahkan satu dokumen ke tempat sampah. Mengembalikan
+// ErrNotFound jika dokumen tidak ada atau sudah terhapus.
+func tandaiTerhapus(ctx context.Context, collection *mongo.Collection, id, oleh primitive.ObjectID) error {
+	result, err := collection.UpdateOne(ctx,
+		belumDihapus(bson.M{"_id": id}),
+		bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": oleh}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// pulihkanDokumen mengeluarkan dokumen dari tempat sampah. Mengembalikan
+// ErrNotFound jika dokumen tidak ada di tempat sampah.
+func pulihkanDokumen(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID) error {
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// findTerhapus mengambil dokumen di tempat sampah yang dihapus pada atau
+// sebelum batas, terbaru lebih dulu kecuali opts menentukan urutan lain
+func findTerhapus(ctx context.Context, collection *mongo.Collection, sebelum time.Time, opts ListOptions, hasil interface{}) (int64, error) {
+	query := bson.M{"deleted_at": bson.M{"$ne": nil, "$lte": sebelum}}
+	return findHalaman(ctx, collection, query, opts.DenganUrutan("deleted_at", true), hasil)
+}
+
+// hapusPermanen menghapus dokumen yang sudah ada di tempat sampah
+func hapusPermanen(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID) error {
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUserRepository struct {
+	collection *mongo.Collection
+	// kunci menyimpan dokumen penanda yang ditulis KunciAdminAktif
+	kunci *mongo.Collection
+}
+
+const (
+	indexUsernameUnik = "username_unik"
+	indexEmailUnik    = "email_unik"
+)
+
+// collationTanpaKapital membandingkan string tanpa membedakan huruf besar/kecil
+var collationTanpaKapital = &options.Collation{Locale: "en", Strength: 2}
+
+// errAkunDipakai mengubah duplicate key error dari index unik users menjadi
+// ErrUsernameDipakai atau ErrEmailDipakai
+func errAkunDipakai(err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	if strings.Contains(err.Error(), indexEmailUnik) {
+		return ErrEmailDipakai
+	}
+	if strings.Contains(err.Error(), indexUsernameUnik) {
+		return ErrUsernameDipakai
+	}
+	return err
+}
+
+func (r *mongoUserRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, filter, opts...).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *mongoUserRepository) FindAll(ctx context.Context, filter UserFilter, opts ListOptions) ([]models.User, int64, error) {
+	query := bson.M{}
+	if filter.Q != "" {
+		// Input user di-escape agar tidak dibaca sebagai regex
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Q), Options: "i"}
+		query["$or"] = bson.A{
+			bson.M{"username": pattern},
+			bson.M{"email": pattern},
+		}
+	}
+	if filter.Role != "" {
+		query["role"] = filter.Role
+	}
+	if filter.Nonaktif != nil {
+		if *filter.Nonaktif {
+			query["nonaktif"] = true
+		} else {
+			// User lama belum punya field nonaktif
+			query["nonaktif"] = bson.M{"$ne": true}
+		}
+	}
+
+	users := []models.User{}
+	total, err := findHalaman(ctx, r.collection, query, opts, &users)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *mongoUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	// Collation sama dengan index email_unik, sehingga pencarian memakai index
+	// dan cocok dengan aturan keunikan email
+	return r.findOne(ctx, bson.M{"email": email}, options.FindOne().SetCollation(collationTanpaKapital))
+}
+
+func (r *mongoUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	pattern := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(username) + "$", Options: "i"}
+	return r.findOne(ctx, bson.M{"username": pattern})
+}
+
+func (r *mongoUserRepository) Create(ctx context.Context, user *models.User) error {
+	_, err := r.collection.InsertOne(ctx, user)
+	return errAkunDipakai(err)
+}
+
+func (r *mongoUserRepository) Update(ctx context.Context, id primitive.ObjectID, ubah UserUpdate) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": setUserUpdate(ubah)},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, errAkunDipakai(err)
+	}
+	return &user, nil
+}
+
+// setUserUpdate menyusun isi $set dari field UserUpdate yang diisi
+func setUserUpdate(ubah UserUpdate) bson.M {
+	set := bson.M{"updated_at": time.Now()}
+	for field, nilai := range map[string]*string{
+		"username":     ubah.Username,
+		"email":        ubah.Email,
+		"nama_lengkap": ubah.NamaLengkap,
+		"telepon":      ubah.Telepon,
+		"password":     ubah.Password,
+		"role":         ubah.Role,
+		"oidc_subject": ubah.OIDCSubject,
+	} {
+		if nilai != nil {
+			set[field] = *nilai
+		}
+	}
+	for field, nilai := range map[string]*bool{
+		"nonaktif":             ubah.Nonaktif,
+		"harus_ganti_password": ubah.HarusGantiPassword,
+		"belum_verifikasi":     ubah.BelumVerifikasi,
+	} {
+		if nilai != nil {
+			set[field] = *nilai
+		}
+	}
+	return set
+}
+
+func (r *mongoUserRepository) PakaiLangkahTOTP(ctx context.Context, id primitive.ObjectID, langkah int64) (bool, error) {
+	// totp_langkah kosong (omitempty) diperlakukan sebagai 0
+	result, err := r.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"totp_langkah": bson.M{"$lt": langkah}},
+			bson.M{"totp_langkah": bson.M{"$exists": false}},
+		},
+	}, bson.M{"$set": bson.M{"totp_langkah": langkah, "updated_at": time.Now()}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (r *mongoUserRepository) PakaiKodePemulihan(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "kode_pemulihan": hash},
+		bson.M{
+			"$pull": bson.M{"kode_pemulihan": hash},
+			"$set":  bson.M{"updated_at": time.Now()},
+		})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (r *mongoUserRepository) SimpanSecretTOTP(ctx context.Context, id primitive.ObjectID, secret string) error {
+	return r.ubah2FA(ctx, bson.M{"_id": id, "totp_aktif": bson.M{"$ne": true}}, bson.M{
+		"$set":   bson.M{"totp_secret": secret, "updated_at": time.Now()},
+		"$unset": bson.M{"totp_langkah": ""},
+	})
+}
+
+func (r *mongoUserRepository) AktifkanTOTP(ctx context.Context, id primitive.ObjectID, kodePemulihan []string) error {
+	return r.ubah2FA(ctx, bson.M{"_id": id, "totp_aktif": bson.M{"$ne": true}}, bson.M{
+		"$set": bson.M{"totp_aktif": true, "kode_pemulihan": kodePemulihan, "updated_at": time.Now()},
+	})
+}
+
+func (r *mongoUserRepository) GantiKodePemulihan(ctx context.Context, id primitive.ObjectID, kodePemulihan []string) error {
+	return r.ubah2FA(ctx, bson.M{"_id": id, "totp_aktif": true}, bson.M{
+		"$set": bson.M{"kode_pemulihan": kodePemulihan, "updated_at": time.Now()},
+	})
+}
+
+func (r *mongoUserRepository) Hapus2FA(ctx context.Context, id primitive.ObjectID) error {
+	return r.ubah2FA(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"totp_aktif": false, "updated_at": time.Now()},
+		"$unset": bson.M{"totp_secret": "", "totp_langkah": "", "kode_pemulihan": ""},
+	})
+}
+
+// ubah2FA menjalankan update field 2FA dan mengembalikan ErrNotFound jika
+// tidak ada user yang cocok dengan filter
+func (r *mongoUserRepository) ubah2FA(ctx context.Context, filter, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *mongoUserRepository) KunciAdminAktif(ctx context.Context) error {
+	// Snapshot transaksi tidak mencegah write skew antar dokumen user yang
+	// berbeda, jadi semua transaksi menulis dokumen yang sama agar MongoDB
+	// menolak salah satunya dengan write conflict
+	_, err := r.kunci.UpdateOne(ctx,
+		bson.M{"_id": "admin_aktif"},
+		bson.M{"$inc": bson.M{"versi": 1}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MutasiStokRepository interface {
+	Create(ctx context.Context, mutasi *models.MutasiStok) error
+	// FindByBarang mengembalikan mutasi terbaru lebih dulu beserta jumlah totalnya
+	FindByBarang(ctx context.Context, barangID primitive.ObjectID, opts ListOptions) ([]models.MutasiStok, int64, error)
+	// SumPerBarang menjumlahkan delta mutasi untuk setiap barang
+	SumPerBarang(ctx context.Context) (map[primitive.ObjectID]int, error)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PeminjamanFilter membatasi hasil FindAll. Field kosong berarti tidak difilter.
+type PeminjamanFilter struct {
+	// Cari dicocokkan ke nama, email dan telepon peminjam serta nama barang yang dipinjam
+	Cari Pencarian
+	// UserID membatasi hasil ke peminjaman milik satu akun
+	UserID *primitive.ObjectID
+	// Status berisi status yang diterima; kosong berarti semua status
+	Status []string
+	// BarangID membatasi hasil ke peminjaman yang memuat salah satu barang tersebut
+	BarangID []primitive.ObjectID
+	// EmailPeminjam dicocokkan secara exact match dan case-insensitive
+	EmailPeminjam string
+	// TanggalDari dan TanggalSampai (format YYYY-MM-DD, inklusif) membatasi tanggal pinjam
+	TanggalDari   string
+	TanggalSampai string
+	// TermasukTerhapus ikut mengembalikan peminjaman yang ada di tempat sampah
+	TermasukTerhapus bool
+}
+
+type PeminjamanRepository interface {
+	FindAll(ctx context.Context, filter PeminjamanFilter, opts ListOptions) ([]models.Peminjaman, int64, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Peminjaman, error)
+	Create(ctx context.Context, peminjaman *models.Peminjaman) error
+	// Update menyimpan ulang seluruh dokumen peminjaman
+	Update(ctx context.Context, peminjaman *models.Peminjaman) error
+	// Delete memindahkan peminjaman ke tempat sampah (soft delete)
+	Delete(ctx context.Context, id primitive.ObjectID, oleh primitive.ObjectID) error
+	// Restore mengeluarkan peminjaman dari tempat sampah
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	// FindTerhapus mengambil isi tempat sampah yang dihapus pada atau sebelum waktu sebelum
+	FindTerhapus(ctx context.Context, sebelum time.Time, opts ListOptions) ([]models.Peminjaman, int64, error)
+	// HapusPermanen menghapus peminjaman yang sudah ada di tempat sampah
+	HapusPermanen(ctx context.Context, id primitive.ObjectID) error
+	// FindTerlambat mengembalikan peminjaman berstatus dipinjam yang jatuh temponya
+	// sebelum tanggal hariIni (format YYYY-MM-DD)
+	FindTerlambat(ctx context.Context, hariIni string) ([]models.Peminjaman, error)
+	// Laporan mengembalikan satu baris per item peminjaman yang digabung dengan
+	// barang_info dan kategori_info
+	Laporan(ctx context.Context) ([]bson.M, error)
+}
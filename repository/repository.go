@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNotFound dikembalikan oleh semua repository ketika dokumen tidak ditemukan
+var ErrNotFound = errors.New("data tidak ditemukan")
+
+// ErrStokTidakCukup dikembalikan ketika pengurangan stok akan membuat stok negatif
+var ErrStokTidakCukup = errors.New("stok barang tidak mencukupi")
+
+// ErrUsernameDipakai dan ErrEmailDipakai dikembalikan UserRepository ketika
+// username atau email (case-insensitive) sudah dipakai user lain
+var (
+	ErrUsernameDipakai = errors.New("username sudah dipakai")
+	ErrEmailDipakai    = errors.New("email sudah terdaftar")
+)
+
+// Transactor menjalankan beberapa operasi repository secara atomik.
+// Repository yang dipanggil di dalam fn wajib memakai ctx yang diberikan.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ErrCursorTidakValid dikembalikan ketika dokumen pada ListOptions.After tidak
+// ada di hasil query
+var ErrCursorTidakValid = errors.New("dokumen after tidak ditemukan")
+
+// ListOptions mengatur urutan dan halaman hasil query list. Limit 0 berarti
+// semua hasil dikembalikan.
+type ListOptions struct {
+	Page  int
+	Limit int
+	// Sort adalah nama field bson untuk pengurutan; kosong berarti urut _id.
+	// Dokumen dengan nilai sama selalu diurutkan lagi berdasarkan _id.
+	Sort string
+	Desc bool
+	// After adalah ID dokumen terakhir halaman sebelumnya. Jika diisi, hasil
+	// dimulai tepat setelah dokumen itu dan Page diabaikan (keyset pagination).
+	After *primitive.ObjectID
+}
+
+// UrutRelevansi dipakai sebagai ListOptions.Sort untuk mengurutkan hasil
+// pencarian teks dari yang paling relevan. Urutan ini tidak mendukung After.
+const UrutRelevansi = "$relevansi"
+
+// Pencarian adalah teks pencarian parsial yang cocok di bagian mana pun dari
+// nilai field, atau hanya di awal nilai jika Awalan bernilai true. Teks selalu
+// dicocokkan apa adanya dan tanpa membedakan huruf besar/kecil.
+type Pencarian struct {
+	Teks   string
+	Awalan bool
+}
+
+// DenganUrutan mengisi urutan bawaan jika opts belum menentukan Sort
+func (o ListOptions) DenganUrutan(field string, desc bool) ListOptions {
+	if o.Sort == "" {
+		o.Sort = field
+		o.Desc = desc
+	}
+	return o
+}
+
+// Skip mengembalikan jumlah dokumen yang dilewati untuk halaman saat ini
+func (o ListOptions) Skip() int {
+	if o.Page < 1 || o.After != nil {
+		return 0
+	}
+	return (o.Page - 1) * o.Limit
+}
+
+// Repositories mengelompokkan semua repository yang dipakai controller
+type Repositories struct {
+	Tx           Transactor
+	Barang       BarangRepository
+	Kategori     KategoriRepository
+	Peminjaman   PeminjamanRepository
+	User         UserRepository
+	MutasiStok   MutasiStokRepository
+	Session      SessionRepository
+	Role         RoleRepository
+	AuditLog     AuditLogRepository
+	AuthToken    AuthTokenRepository
+	LoginAttempt LoginAttemptRepository
+	APIKey       APIKeyRepository
+	LoginHistory LoginHistoryRepository
+}
+
+// NewMongoRepositories membuat repository yang tersimpan di MongoDB
+func NewMongoRepositories(db *mongo.Database) *Repositories {
+	return &Repositories{
+		Tx:           &mongoTransactor{client: db.Client()},
+		Barang:       &mongoBarangRepository{collection: db.Collection("barang")},
+		Kategori:     &mongoKategoriRepository{collection: db.Collection("kategori")},
+		Peminjaman:   &mongoPeminjamanRepository{collection: db.Collection("peminjaman")},
+		User:         &mongoUserRepository{collection: db.Collection("users"), kunci: db.Collection("kunci")},
+		MutasiStok:   &mongoMutasiStokRepository{collection: db.Collection("stock_movements")},
+		Session:      &mongoSessionRepository{collection: db.Collection("sessions")},
+		Role:         &mongoRoleRepository{collection: db.Collection("roles"), kunci: db.Collection("kunci")},
+		AuditLog:     &mongoAuditLogRepository{collection: db.Collection("audit_logs")},
+		AuthToken:    &mongoAuthTokenRepository{collection: db.Collection("auth_tokens")},
+		LoginAttempt: &mongoLoginAttemptRepository{collection: db.Collection("login_attempts")},
+		APIKey:       &mongoAPIKeyRepository{collection: db.Collection("api_keys")},
+		LoginHistory: &mongoLoginHistoryRepository{collection: db.Collection("login_history")},
+	}
+}
+
+// NewMemoryRepositories membuat repository in-memory untuk testing dan demo.
+// Data hilang ketika proses berhenti.
+func NewMemoryRepositories() *Repositories {
+	store := newMemoryStore()
+	return &Repositories{
+		Tx:           store,
+		Barang:       &memoryBarangRepository{store: store},
+		Kategori:     &memoryKategoriRepository{store: store},
+		Peminjaman:   &memoryPeminjamanRepository{store: store},
+		User:         &memoryUserRepository{store: store},
+		MutasiStok:   &memoryMutasiStokRepository{store: store},
+		Session:      &memorySessionRepository{store: store},
+		Role:         &memoryRoleRepository{store: store},
+		AuditLog:     &memoryAuditLogRepository{store: store},
+		AuthToken:    &memoryAuthTokenRepository{store: store},
+		LoginAttempt: &memoryLoginAttemptRepository{store: store},
+		APIKey:       &memoryAPIKeyRepository{store: store},
+		LoginHistory: &memoryLoginHistoryRepository{store: store},
+	}
+}
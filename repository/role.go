@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RoleRepository interface {
+	FindAll(ctx context.Context) ([]models.Role, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Role, error)
+	FindByNama(ctx context.Context, nama string) (*models.Role, error)
+	Create(ctx context.Context, role *models.Role) error
+	// Update menyimpan ulang seluruh dokumen role
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// KunciRole dipanggil di dalam transaksi yang memberikan role nama ke user
+	// atau menghapus role tersebut. Kedua transaksi saling bentrok sehingga
+	// role tidak bisa dihapus bersamaan dengan diberikan ke user.
+	KunciRole(ctx context.Context, nama string) error
+}
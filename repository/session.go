@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+	// FindAktifByUser mengembalikan sesi user yang belum dicabut dan belum
+	// kedaluwarsa, yang terakhir dipakai lebih dulu
+	FindAktifByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error)
+	// Rotate mengganti refresh token aktif secara atomik dan menyimpan hash lama
+	// (hanya models.MaksRefreshTokenLama hash terakhir yang disimpan).
+	// Mengembalikan ErrNotFound jika hashLama sudah bukan token aktif atau sesi
+	// sudah dicabut, sehingga satu refresh token hanya bisa dipakai sekali.
+	Rotate(ctx context.Context, id primitive.ObjectID, hashLama, hashBaru string, expiresAt time.Time) error
+	// Revoke mencabut satu sesi; sesi yang sudah dicabut dibiarkan
+	Revoke(ctx context.Context, id primitive.ObjectID, alasan string) error
+	// RevokeByUser mencabut semua sesi aktif milik user kecuali sesi kecuali
+	// (boleh nil) dan mengembalikan jumlah sesi yang dicabut
+	RevokeByUser(ctx context.Context, userID primitive.ObjectID, kecuali *primitive.ObjectID, alasan string) (int64, error)
+}
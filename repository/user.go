@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"inventory-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserFilter membatasi hasil FindAll. Field kosong berarti tidak difilter.
+type UserFilter struct {
+	// Q dicari sebagai potongan teks di username atau email (case-insensitive)
+	Q        string
+	Role     string
+	Nonaktif *bool
+}
+
+// UserUpdate berisi field user yang diubah oleh UserRepository.Update. Field
+// nil tidak diubah. Field 2FA sengaja tidak ada di sini agar salinan user yang
+// sudah usang tidak pernah menimpanya; gunakan method 2FA khusus.
+type UserUpdate struct {
+	Username           *string
+	Email              *string
+	NamaLengkap        *string
+	Telepon            *string
+	Password           *string
+	Role               *string
+	Nonaktif           *bool
+	HarusGantiPassword *bool
+	BelumVerifikasi    *bool
+	OIDCSubject        *string
+}
+
+type UserRepository interface {
+	FindAll(ctx context.Context, filter UserFilter, opts ListOptions) ([]models.User, int64, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	// FindByEmail mencocokkan email secara case-insensitive, sama seperti
+	// aturan keunikan email
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindByUsername mencocokkan username secara case-insensitive
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	// Update hanya mengubah field yang diisi di ubah beserta updated_at, lalu
+	// mengembalikan user sesudah diubah
+	Update(ctx context.Context, id primitive.ObjectID, ubah UserUpdate) (*models.User, error)
+	// PakaiLangkahTOTP menyimpan langkah TOTP secara atomik hanya jika lebih
+	// besar dari langkah terakhir. false berarti kode sudah pernah dipakai.
+	PakaiLangkahTOTP(ctx context.Context, id primitive.ObjectID, langkah int64) (bool, error)
+	// PakaiKodePemulihan menghapus hash kode pemulihan secara atomik. false
+	// berarti kode tidak ada atau sudah dipakai request lain.
+	PakaiKodePemulihan(ctx context.Context, id primitive.ObjectID, hash string) (bool, error)
+	// Method 2FA berikut hanya mengubah field 2FA, sehingga tidak menimpa
+	// perubahan lain yang terjadi bersamaan. SimpanSecretTOTP dan AktifkanTOTP
+	// mengembalikan ErrNotFound jika 2FA sudah aktif, GantiKodePemulihan jika
+	// 2FA belum aktif.
+	SimpanSecretTOTP(ctx context.Context, id primitive.ObjectID, secret string) error
+	AktifkanTOTP(ctx context.Context, id primitive.ObjectID, kodePemulihan []string) error
+	GantiKodePemulihan(ctx context.Context, id primitive.ObjectID, kodePemulihan []string) error
+	Hapus2FA(ctx context.Context, id primitive.ObjectID) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// KunciAdminAktif dipanggil di dalam transaksi yang bisa mengurangi jumlah
+	// admin aktif, sebelum jumlahnya dihitung. Dua transaksi seperti itu saling
+	// bentrok sehingga salah satunya diulang dan membaca jumlah yang terbaru.
+	KunciAdminAktif(ctx context.Context) error
+}
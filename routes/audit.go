@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"inventory-backend/controllers"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterAuditRoutes(router fiber.Router) {
+	router.Get("/audit", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermAuditRead), controllers.GetAuditLogs)
+}
@@ -14,7 +14,37 @@ func RegisterAuthRoutes(router fiber.Router) {
 	// Public routes (tidak perlu authentication)
 	auth.Post("/register", validators.ValidateRegister, controllers.Register)
 	auth.Post("/login", validators.ValidateLogin, controllers.Login)
+	auth.Post("/refresh", controllers.RefreshToken)
+	auth.Post("/forgot-password", controllers.ForgotPassword)
+	auth.Post("/reset-password", controllers.ResetPassword)
+	auth.Post("/verify-email", controllers.VerifyEmail)
+	auth.Post("/2fa/verify", controllers.Verify2FA)
+	auth.Get("/oidc/login", controllers.OIDCLogin)
+	auth.Get("/oidc/callback", controllers.OIDCCallback)
 
-	// Protected routes (perlu authentication)
+	// Protected routes (perlu authentication, boleh dengan API key)
 	auth.Get("/profile", middlewares.JWTMiddleware, controllers.GetProfile)
+
+	// Pengelolaan akun hanya dari sesi login, tidak dengan API key
+	auth.Put("/profile", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.UpdateProfile)
+	auth.Put("/password", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.ChangePassword)
+	auth.Post("/logout", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.Logout)
+	auth.Post("/logout-all", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.LogoutAll)
+	auth.Post("/verify-email/resend", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.ResendVerifyEmail)
+
+	// Sesi aktif dan riwayat login milik user yang sedang login
+	auth.Get("/sessions", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.GetSessions)
+	auth.Delete("/sessions/:id", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.RevokeSession)
+	auth.Get("/login-history", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.GetLoginHistory)
+
+	// Pendaftaran dan pengelolaan 2FA milik user yang sedang login
+	auth.Post("/2fa/setup", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.Setup2FA)
+	auth.Post("/2fa/enable", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.Enable2FA)
+	auth.Post("/2fa/disable", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.Disable2FA)
+	auth.Post("/2fa/recovery-codes", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.RegenerateKodePemulihan)
+
+	// API key untuk script dan integrasi
+	auth.Get("/api-keys", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.GetAPIKeys)
+	auth.Post("/api-keys", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.CreateAPIKey)
+	auth.Delete("/api-keys/:id", middlewares.JWTMiddleware, middlewares.RequireSesi, controllers.RevokeAPIKey)
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"inventory-backend/controllers"
 	"inventory-backend/middlewares"
+	"inventory-backend/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,9 +14,12 @@ func RegisterBarangRoutes(router fiber.Router) {
 	// Public endpoints (semua user bisa akses)
 	barang.Get("/", middlewares.JWTMiddleware, controllers.GetAllBarang)
 	barang.Get("/:id", middlewares.JWTMiddleware, controllers.GetBarangByID)
+	barang.Get("/:id/mutasi", middlewares.JWTMiddleware, controllers.GetMutasiBarang)
+	barang.Get("/:id/history", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermAuditRead), controllers.GetBarangHistory)
 	
-	// Protected endpoints (hanya admin yang bisa create/update/delete)
-	barang.Post("/", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.CreateBarang)
-	barang.Put("/:id", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.UpdateBarang)
-	barang.Delete("/:id", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.DeleteBarang)
+	// Protected endpoints (butuh permission barang:write)
+	barang.Post("/", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermBarangWrite), controllers.CreateBarang)
+	barang.Put("/:id", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermBarangWrite), controllers.UpdateBarang)
+	barang.Delete("/:id", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermBarangWrite), controllers.DeleteBarang)
+	barang.Post("/:id/restore", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermBarangWrite), controllers.RestoreBarang)
 }
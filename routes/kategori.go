@@ -3,6 +3,7 @@ package routes
 import (
 	"inventory-backend/controllers"
 	"inventory-backend/middlewares"
+	"inventory-backend/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -14,8 +15,9 @@ func RegisterKategoriRoutes(router fiber.Router) {
 	kategori.Get("/", middlewares.JWTMiddleware, controllers.GetAllKategori)
 	kategori.Get("/:id", middlewares.JWTMiddleware, controllers.GetKategoriByID)
 	
-	// Protected endpoints (hanya admin yang bisa create/update/delete)
-	kategori.Post("/", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.CreateKategori)
-	kategori.Put("/:id", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.UpdateKategori)
-	kategori.Delete("/:id", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.DeleteKategori)
+	// Protected endpoints (butuh permission kategori:write)
+	kategori.Post("/", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermKategoriWrite), controllers.CreateKategori)
+	kategori.Put("/:id", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermKategoriWrite), controllers.UpdateKategori)
+	kategori.Delete("/:id", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermKategoriWrite), controllers.DeleteKategori)
+	kategori.Post("/:id/restore", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermKategoriWrite), controllers.RestoreKategori)
 }
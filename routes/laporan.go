@@ -2,12 +2,15 @@ package routes
 
 import (
 	"inventory-backend/controllers"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// Route laporan untuk admin
+// Route laporan
 func RegisterLaporanRoutes(router fiber.Router) {
 	laporan := router.Group("/laporan")
-	laporan.Get("/peminjaman", controllers.GetLaporanPeminjaman)
+	laporan.Get("/peminjaman", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermLaporanRead), controllers.GetLaporanPeminjaman)
+	laporan.Get("/terlambat", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermLaporanRead), controllers.GetLaporanTerlambat)
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"inventory-backend/controllers"
 	"inventory-backend/middlewares"
+	"inventory-backend/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,13 +11,19 @@ import (
 func RegisterPeminjamanRoutes(router fiber.Router) {
 	peminjaman := router.Group("/peminjaman")
 	
-	// Semua user bisa lihat dan buat peminjaman
+	// Semua user bisa lihat peminjaman; tanpa peminjaman:read_all hanya miliknya sendiri
 	peminjaman.Get("/", middlewares.JWTMiddleware, controllers.GetAllPeminjaman)
+	peminjaman.Get("/saya", middlewares.JWTMiddleware, controllers.GetPeminjamanSaya)
 	peminjaman.Get("/:id", middlewares.JWTMiddleware, controllers.GetPeminjamanByID)
-	peminjaman.Post("/", middlewares.JWTMiddleware, controllers.CreatePeminjaman)
+	peminjaman.Post("/", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermPeminjamanCreate), middlewares.RequireEmailTerverifikasi, controllers.CreatePeminjaman)
 	
-	// Hanya admin yang bisa update status, jumlah, dan delete
-	peminjaman.Put("/:id", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.UpdateStatusPeminjaman)
-	peminjaman.Put("/:id/jumlah", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.UpdateJumlahPeminjaman)
-	peminjaman.Delete("/:id", middlewares.JWTMiddleware, middlewares.RequireAdmin, controllers.DeletePeminjaman)
+	// Permission update status dicek per perpindahan status di controller,
+	// karena pemilik boleh membatalkan pengajuannya sendiri
+	peminjaman.Put("/:id", middlewares.JWTMiddleware, controllers.UpdateStatusPeminjaman)
+
+	// Update jumlah, pengembalian dan delete butuh permission masing-masing
+	peminjaman.Put("/:id/jumlah", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermPeminjamanUpdate), controllers.UpdateJumlahPeminjaman)
+	peminjaman.Post("/:id/pengembalian", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermPeminjamanReturn), controllers.KembalikanItemPeminjaman)
+	peminjaman.Delete("/:id", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermPeminjamanDelete), controllers.DeletePeminjaman)
+	peminjaman.Post("/:id/restore", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermPeminjamanDelete), controllers.RestorePeminjaman)
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"inventory-backend/controllers"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterRoleRoutes(router fiber.Router) {
+	// Manajemen role dan permission
+	roles := router.Group("/roles", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermRolesManage))
+
+	roles.Get("/", controllers.GetAllRoles)
+	roles.Get("/permissions", controllers.GetAllPermissions)
+	roles.Post("/", controllers.CreateRole)
+	roles.Put("/:id", controllers.UpdateRole)
+	roles.Delete("/:id", controllers.DeleteRole)
+}
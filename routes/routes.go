@@ -1,20 +1,30 @@
 package routes
 
 import (
+	"inventory-backend/controllers"
+
 	"github.com/gofiber/fiber/v2"
 )
 
 func SetupRoutes(app *fiber.App) {
+	// Public key untuk verifikasi token oleh service lain
+	app.Get("/.well-known/jwks.json", controllers.GetJWKS)
+
 	api := app.Group("/api")
 
 	// Auth routes (login/register)
 	RegisterAuthRoutes(api)
+	RegisterUserRoutes(api)
+	RegisterRoleRoutes(api)
 	
 	// Resource routes
 	RegisterKategoriRoutes(api)
 	RegisterBarangRoutes(api)
 	RegisterPeminjamanRoutes(api)
 	RegisterLaporanRoutes(api)
+	RegisterStokRoutes(api)
+	RegisterTrashRoutes(api)
+	RegisterAuditRoutes(api)
 }
 
 
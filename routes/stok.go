@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"inventory-backend/controllers"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Route rekonsiliasi stok
+func RegisterStokRoutes(router fiber.Router) {
+	stok := router.Group("/stok")
+
+	stok.Get("/rekonsiliasi", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermStokRead), controllers.GetRekonsiliasiStok)
+	stok.Post("/rekonsiliasi", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermStokWrite), controllers.KoreksiStok)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"inventory-backend/controllers"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Route tempat sampah; pemulihan ada di route masing-masing resource
+func RegisterTrashRoutes(router fiber.Router) {
+	router.Get("/trash", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermTrashManage), controllers.GetTrash)
+}
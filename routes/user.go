@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"inventory-backend/controllers"
+	"inventory-backend/middlewares"
+	"inventory-backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterUserRoutes(router fiber.Router) {
+	// Manajemen user butuh permission users:manage
+	users := router.Group("/users", middlewares.JWTMiddleware, middlewares.RequirePermission(models.PermUsersManage))
+
+	users.Get("/", controllers.GetAllUsers)
+	users.Post("/", controllers.CreateUser)
+	users.Post("/unlock-ip", controllers.UnlockIP)
+	users.Get("/:id", controllers.GetUserByID)
+	users.Get("/:id/login-history", controllers.GetLoginHistoryUser)
+	users.Put("/:id/role", controllers.UpdateRoleUser)
+	users.Put("/:id/status", controllers.UpdateStatusUser)
+	users.Post("/:id/reset-password", controllers.ResetPasswordUser)
+	users.Post("/:id/unlock", controllers.UnlockUser)
+	users.Post("/:id/reset-2fa", controllers.Reset2FAUser)
+	users.Delete("/:id", controllers.DeleteUser)
+}
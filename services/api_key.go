@@ -0,0 +1,40 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// prefixAPIKey memudahkan API key dikenali, mis. oleh secret scanner
+const prefixAPIKey = "inv_"
+
+// BuatAPIKey membuat API key acak dengan format inv_<id>_<rahasia>. ID ikut di
+// dalam kunci agar kunci bisa dicari tanpa memindai semua hash.
+func BuatAPIKey(id primitive.ObjectID) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return prefixAPIKey + id.Hex() + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// IDDariAPIKey mengambil ID dari API key
+func IDDariAPIKey(key string) (primitive.ObjectID, error) {
+	rest, ok := strings.CutPrefix(key, prefixAPIKey)
+	if !ok || len(rest) < 25 || rest[24] != '_' {
+		return primitive.NilObjectID, errors.New("format API key tidak valid")
+	}
+	return primitive.ObjectIDFromHex(rest[:24])
+}
+
+// HashAPIKey menghitung hash SHA-256 API key yang disimpan di database
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
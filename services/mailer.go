@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Email adalah satu pesan yang dikirim ke user
+type Email struct {
+	Ke     string
+	Subjek string
+	Isi    string
+}
+
+// Mailer mengirim email. Implementasinya dipilih lewat MAIL_DRIVER.
+type Mailer interface {
+	Kirim(ctx context.Context, email Email) error
+}
+
+// NewMailerFromEnv memilih mailer dari MAIL_DRIVER:
+//
+//	smtp   kirim lewat SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD dan MAIL_FROM
+//	file   tulis email ke MAIL_FILE (default mail.log) untuk testing lokal
+//	memory simpan email di memori (default)
+//
+// Jika APP_ENV=production, MAIL_DRIVER wajib diisi smtp atau file agar email
+// reset password dan verifikasi tidak hilang diam-diam di memori.
+func NewMailerFromEnv() (Mailer, error) {
+	production := strings.EqualFold(os.Getenv("APP_ENV"), "production")
+	switch driver := strings.ToLower(os.Getenv("MAIL_DRIVER")); driver {
+	case "smtp":
+		m := &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}
+		if m.Host == "" || m.From == "" {
+			return nil, fmt.Errorf("SMTP_HOST dan MAIL_FROM wajib diisi jika MAIL_DRIVER=smtp")
+		}
+		if m.Port == "" {
+			m.Port = "587"
+		}
+		return m, nil
+	case "file":
+		path := os.Getenv("MAIL_FILE")
+		if path == "" {
+			path = "mail.log"
+		}
+		return &FileMailer{Path: path}, nil
+	case "", "memory":
+		if production {
+			return nil, fmt.Errorf("MAIL_DRIVER wajib diisi smtp atau file jika APP_ENV=production")
+		}
+		log.Printf("Warning: MAIL_DRIVER tidak diset ke smtp, email hanya disimpan di memori")
+		return &MemoryMailer{}, nil
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER %q tidak dikenal", driver)
+	}
+}
+
+// SMTPMailer mengirim email lewat server SMTP. STARTTLS dipakai otomatis jika
+// didukung server.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Kirim(ctx context.Context, email Email) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{email.Ke}, formatEmail(m.From, email))
+}
+
+// FileMailer menambahkan setiap email ke akhir file
+type FileMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (m *FileMailer) Kirim(ctx context.Context, email Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\n%s\n\n", formatEmail("", email), strings.Repeat("-", 60))
+	return err
+}
+
+// MemoryMailer menyimpan email di memori, dipakai untuk testing
+type MemoryMailer struct {
+	mu       sync.Mutex
+	terkirim []Email
+}
+
+func (m *MemoryMailer) Kirim(ctx context.Context, email Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.terkirim = append(m.terkirim, email)
+	return nil
+}
+
+// Terkirim mengembalikan salinan semua email yang sudah dikirim
+func (m *MemoryMailer) Terkirim() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Email(nil), m.terkirim...)
+}
+
+// formatEmail menyusun pesan plain text dengan header RFC 5322
+func formatEmail(from string, email Email) []byte {
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", email.Ke)
+	fmt.Fprintf(&b, "Subject: %s\r\n", email.Subjek)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(email.Isi, "\n", "\r\n"))
+	return []byte(b.String())
+}
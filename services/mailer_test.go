@@ -0,0 +1,27 @@
+package services
+
+import "testing"
+
+func TestNewMailerFromEnvProduction(t *testing.T) {
+	tests := []struct {
+		env    string
+		driver string
+		gagal  bool
+	}{
+		{"production", "", true},
+		{"production", "memory", true},
+		{"production", "file", false},
+		{"development", "", false},
+		{"", "memory", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("APP_ENV", tt.env)
+		t.Setenv("MAIL_DRIVER", tt.driver)
+		t.Setenv("MAIL_FILE", t.TempDir()+"/mail.log")
+
+		_, err := NewMailerFromEnv()
+		if (err != nil) != tt.gagal {
+			t.Errorf("APP_ENV=%q MAIL_DRIVER=%q: err = %v, ingin gagal %v", tt.env, tt.driver, err, tt.gagal)
+		}
+	}
+}
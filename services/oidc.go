@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCProvider menjalankan authorization code flow (dengan PKCE) ke identity
+// provider OpenID Connect dan memverifikasi ID token-nya. Endpoint provider
+// dibaca dari discovery document dan public key dari JWKS provider.
+type OIDCProvider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	// AnggapEmailTerverifikasi membuat ID token tanpa claim email_verified
+	// dianggap sudah memverifikasi email. Hanya aktifkan untuk provider yang
+	// memang tidak pernah mengirim claim itu tetapi selalu memverifikasi email.
+	AnggapEmailTerverifikasi bool
+
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]interface{}
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCClaims adalah data user dari ID token yang dipakai aplikasi
+type OIDCClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Nama          string
+	Username      string
+	Groups        []string
+}
+
+// NewOIDCProviderFromEnv membaca konfigurasi SSO dari environment. Mengembalikan
+// nil jika OIDC_ISSUER kosong (SSO tidak aktif).
+//
+//	OIDC_ISSUER         URL issuer, mis. https://login.example.com/realms/staff
+//	OIDC_CLIENT_ID      client ID aplikasi di provider
+//	OIDC_CLIENT_SECRET  client secret (boleh kosong untuk public client)
+//	OIDC_REDIRECT_URL   URL /api/auth/oidc/callback yang didaftarkan di provider
+//	OIDC_SCOPES         scope dipisah spasi (default "openid email profile")
+//	OIDC_GROUPS_CLAIM   nama claim berisi grup user (default "groups")
+//	OIDC_ASSUME_EMAIL_VERIFIED  true jika ID token tanpa email_verified boleh
+//	                    dianggap terverifikasi (default false)
+func NewOIDCProviderFromEnv() (*OIDCProvider, error) {
+	issuer := strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/")
+	if issuer == "" {
+		return nil, nil
+	}
+
+	p := &OIDCProvider{
+		Issuer:       issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+		GroupsClaim:  os.Getenv("OIDC_GROUPS_CLAIM"),
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+	p.AnggapEmailTerverifikasi, _ = strconv.ParseBool(os.Getenv("OIDC_ASSUME_EMAIL_VERIFIED"))
+	if p.ClientID == "" || p.RedirectURL == "" {
+		return nil, errors.New("OIDC_CLIENT_ID dan OIDC_REDIRECT_URL wajib diisi jika OIDC_ISSUER diset")
+	}
+	if len(p.Scopes) == 0 {
+		p.Scopes = []string{"openid", "email", "profile"}
+	}
+	if p.GroupsClaim == "" {
+		p.GroupsClaim = "groups"
+	}
+	return p, nil
+}
+
+// BuatPKCE membuat code verifier acak dan code challenge S256-nya (RFC 7636)
+func BuatPKCE() (string, string, error) {
+	verifier, err := acakURLSafe(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// acakURLSafe membuat string acak base64url dari n byte
+func acakURLSafe(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// BuatState membuat nilai acak untuk parameter state dan nonce
+func BuatState() (string, error) {
+	return acakURLSafe(24)
+}
+
+// AuthURL menyusun URL login di provider
+func (p *OIDCProvider) AuthURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	d, err := p.ambilDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// TukarKode menukar authorization code dengan token lalu memverifikasi ID token:
+// signature dari JWKS provider, issuer, audience, masa berlaku dan nonce
+func (p *OIDCProvider) TukarKode(ctx context.Context, code, codeVerifier, nonce string) (*OIDCClaims, error) {
+	d, err := p.ambilDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.ClientSecret != "" {
+		form.Set("client_secret", p.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := p.kirim(req, &token); err != nil {
+		return nil, fmt.Errorf("gagal menukar authorization code: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("provider tidak mengembalikan id_token")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token.IDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.kunciPublik(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id_token tidak valid: %w", err)
+	}
+	if claims["nonce"] != nonce {
+		return nil, errors.New("nonce id_token tidak cocok")
+	}
+
+	hasil := &OIDCClaims{
+		Groups: klaimDaftar(claims[p.GroupsClaim]),
+	}
+	hasil.Subject, _ = claims["sub"].(string)
+	hasil.Email, _ = claims["email"].(string)
+	hasil.Nama, _ = claims["name"].(string)
+	hasil.Username, _ = claims["preferred_username"].(string)
+	// Email dipakai untuk menautkan akun lokal, jadi tanpa claim email_verified
+	// email dianggap belum terverifikasi kecuali dikonfigurasi sebaliknya
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		hasil.EmailVerified = v
+	case string:
+		// Beberapa provider mengirim nilai boolean sebagai string
+		hasil.EmailVerified, _ = strconv.ParseBool(v)
+	default:
+		hasil.EmailVerified = p.AnggapEmailTerverifikasi
+	}
+	if hasil.Subject == "" {
+		return nil, errors.New("id_token tidak memiliki claim sub")
+	}
+	return hasil, nil
+}
+
+// klaimDaftar membaca claim yang berupa array string atau satu string
+func klaimDaftar(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		hasil := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				hasil = append(hasil, s)
+			}
+		}
+		return hasil
+	}
+	return nil
+}
+
+// ambilDiscovery membaca /.well-known/openid-configuration sekali lalu menyimpannya
+func (p *OIDCProvider) ambilDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var d oidcDiscovery
+	if err := p.kirim(req, &d); err != nil {
+		return nil, fmt.Errorf("gagal membaca discovery OIDC: %w", err)
+	}
+	if strings.TrimRight(d.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("issuer discovery %q tidak sama dengan OIDC_ISSUER", d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("discovery OIDC tidak lengkap")
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// kunciPublik mencari public key dari JWKS provider berdasarkan kid. JWKS
+// diambil ulang jika kid belum dikenal, karena provider bisa merotasi kunci.
+func (p *OIDCProvider) kunciPublik(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	d, err := p.ambilDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var jwks struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := p.kirim(req, &jwks); err != nil {
+		return nil, fmt.Errorf("gagal membaca JWKS provider: %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if pub, err := jwk.PublicKey(); err == nil {
+			keys[jwk.Kid] = pub
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	// Provider dengan satu kunci kadang tidak mengisi kid
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("kid %q tidak ada di JWKS provider", kid)
+}
+
+// kirim menjalankan request dan men-decode response JSON
+func (p *OIDCProvider) kirim(req *http.Request, hasil interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s membalas %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, hasil)
+}
+
+// PublicKey mengubah JWK RSA atau EC menjadi public key
+func (k JWK) PublicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("kurva %q tidak didukung", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("tipe kunci %q tidak didukung", k.Kty)
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultJWTSecret adalah secret bawaan untuk development. Server menolak
+// berjalan dengan secret ini jika APP_ENV=production.
+const DefaultJWTSecret = "your-secret-key-change-this-in-production"
+
+// Algoritma token yang didukung
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// kunciJWT adalah satu kunci yang dikenali dari kid-nya. signKey kosong untuk
+// kunci yang hanya dipakai memverifikasi token lama (public key saja).
+type kunciJWT struct {
+	kid       string
+	alg       string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// TokenService menandatangani dan memverifikasi access token. Token selalu
+// ditandatangani dengan kunci aktif, sedangkan semua kunci yang terdaftar
+// tetap dipakai untuk verifikasi sehingga rotasi kunci tidak memutus sesi.
+type TokenService struct {
+	issuer string
+	aktif  *kunciJWT
+	kunci  map[string]*kunciJWT
+	urutan []string
+}
+
+// NewTokenServiceFromEnv membaca konfigurasi JWT dari environment:
+//
+//	JWT_KEYS       daftar kunci "kid:alg:sumber" dipisah koma. Untuk HS256 sumber
+//	               adalah secret-nya, untuk RS256 dan EdDSA sumber adalah path file PEM
+//	               (private key untuk kunci aktif, public key cukup untuk kunci lama).
+//	JWT_ACTIVE_KID kid yang dipakai menandatangani token baru (default kunci pertama)
+//	JWT_SECRET     dipakai jika JWT_KEYS kosong, sebagai satu kunci HS256 dengan kid "default"
+//	JWT_ISSUER     nilai claim iss (default "inventory-backend")
+func NewTokenServiceFromEnv() (*TokenService, error) {
+	production := strings.EqualFold(os.Getenv("APP_ENV"), "production")
+
+	spec := os.Getenv("JWT_KEYS")
+	if spec == "" {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			if production {
+				return nil, errors.New("JWT_SECRET atau JWT_KEYS wajib diisi jika APP_ENV=production")
+			}
+			log.Printf("Warning: JWT_SECRET tidak diset, memakai secret bawaan (jangan dipakai di production)")
+			secret = DefaultJWTSecret
+		}
+		spec = "default:" + AlgHS256 + ":" + secret
+	}
+
+	s := &TokenService{
+		issuer: os.Getenv("JWT_ISSUER"),
+		kunci:  map[string]*kunciJWT{},
+	}
+	if s.issuer == "" {
+		s.issuer = "inventory-backend"
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		k, err := parseKunci(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, err
+		}
+		if _, ada := s.kunci[k.kid]; ada {
+			return nil, fmt.Errorf("kid JWT %q terdaftar lebih dari sekali", k.kid)
+		}
+		if k.alg == AlgHS256 {
+			secret := string(k.signKey.([]byte))
+			if production && secret == DefaultJWTSecret {
+				return nil, fmt.Errorf("kunci JWT %q memakai secret bawaan, tidak diizinkan jika APP_ENV=production", k.kid)
+			}
+			if len(secret) < 32 {
+				log.Printf("Warning: secret JWT %q kurang dari 32 karakter", k.kid)
+			}
+		}
+		s.kunci[k.kid] = k
+		s.urutan = append(s.urutan, k.kid)
+	}
+
+	aktif := os.Getenv("JWT_ACTIVE_KID")
+	if aktif == "" {
+		aktif = s.urutan[0]
+	}
+	s.aktif = s.kunci[aktif]
+	if s.aktif == nil {
+		return nil, fmt.Errorf("JWT_ACTIVE_KID %q tidak ada di JWT_KEYS", aktif)
+	}
+	if s.aktif.signKey == nil {
+		return nil, fmt.Errorf("kunci aktif %q tidak memiliki private key", aktif)
+	}
+	return s, nil
+}
+
+// parseKunci membaca satu entri "kid:alg:sumber"
+func parseKunci(entry string) (*kunciJWT, error) {
+	parts := strings.SplitN(entry, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("format JWT_KEYS tidak valid: %q (harus kid:alg:sumber)", entry)
+	}
+	k := &kunciJWT{kid: parts[0]}
+
+	switch {
+	case strings.EqualFold(parts[1], AlgHS256):
+		k.alg = AlgHS256
+		k.method = jwt.SigningMethodHS256
+		k.signKey = []byte(parts[2])
+		k.verifyKey = k.signKey
+		return k, nil
+	case strings.EqualFold(parts[1], AlgRS256):
+		k.alg = AlgRS256
+		k.method = jwt.SigningMethodRS256
+	case strings.EqualFold(parts[1], AlgEdDSA):
+		k.alg = AlgEdDSA
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("algoritma JWT %q untuk kid %q tidak didukung", parts[1], k.kid)
+	}
+
+	data, err := os.ReadFile(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca kunci JWT %q: %w", k.kid, err)
+	}
+	if err := k.muatPEM(data); err != nil {
+		return nil, fmt.Errorf("kunci JWT %q: %w", k.kid, err)
+	}
+	return k, nil
+}
+
+// muatPEM mengisi kunci dari private key (PKCS#8/PKCS#1) atau public key (PKIX)
+func (k *kunciJWT) muatPEM(data []byte) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return errors.New("file bukan PEM")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		k.signKey, k.verifyKey = key, &key.PublicKey
+	case *rsa.PublicKey:
+		k.verifyKey = key
+	case ed25519.PrivateKey:
+		k.signKey, k.verifyKey = key, key.Public()
+	case ed25519.PublicKey:
+		k.verifyKey = key
+	default:
+		return fmt.Errorf("tipe kunci %T tidak didukung", key)
+	}
+
+	_, rsaKey := k.verifyKey.(*rsa.PublicKey)
+	if (k.alg == AlgRS256) != rsaKey {
+		return fmt.Errorf("tipe kunci tidak cocok dengan algoritma %s", k.alg)
+	}
+	return nil
+}
+
+// Sign menandatangani claims dengan kunci aktif dan menambahkan iss serta kid
+func (s *TokenService) Sign(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = s.issuer
+	token := jwt.NewWithClaims(s.aktif.method, claims)
+	token.Header["kid"] = s.aktif.kid
+	return token.SignedString(s.aktif.signKey)
+}
+
+// Parse memverifikasi token dan mengembalikan claims-nya. Kunci dipilih dari
+// header kid dan algoritma token harus sama dengan algoritma kunci tersebut.
+func (s *TokenService) Parse(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		k, ok := s.kunci[kid]
+		if !ok {
+			return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+		}
+		if token.Method.Alg() != k.alg {
+			return nil, errors.New("metode signing token tidak valid")
+		}
+		return k.verifyKey, nil
+	}, jwt.WithIssuer(s.issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWK adalah satu public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS mengembalikan public key semua kunci asimetris. Kunci HS256 tidak
+// pernah dipublikasikan karena secret-nya sekaligus kunci penandatangan.
+func (s *TokenService) JWKS() []JWK {
+	keys := []JWK{}
+	for _, kid := range s.urutan {
+		k := s.kunci[kid]
+		jwk := JWK{Kid: k.kid, Alg: k.alg, Use: "sig"}
+
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	return keys
+}
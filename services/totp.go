@@ -0,0 +1,84 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameter TOTP (RFC 6238) yang dipakai: HMAC-SHA1, 6 digit dan periode 30
+// detik, sama dengan default Google Authenticator dan aplikasi sejenis
+const (
+	totpDigit   = 6
+	totpPeriode = 30
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// BuatSecretTOTP membuat secret acak 160 bit dalam format base32
+func BuatSecretTOTP() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// LangkahTOTP mengembalikan nomor langkah waktu (periode 30 detik) untuk t
+func LangkahTOTP(t time.Time) int64 {
+	return t.Unix() / totpPeriode
+}
+
+// KodeTOTP menghitung kode untuk satu langkah waktu (HOTP RFC 4226 dengan
+// counter = langkah)
+func KodeTOTP(secret string, langkah int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(langkah))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	kode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigit, kode%1000000), nil
+}
+
+// CocokkanTOTP mencocokkan kode dengan langkah waktu t serta satu langkah
+// sebelum dan sesudahnya untuk menoleransi selisih jam. Langkah yang cocok
+// dikembalikan agar pemanggil bisa menolak kode yang dipakai ulang.
+func CocokkanTOTP(secret, kode string, t time.Time) (int64, bool) {
+	sekarang := LangkahTOTP(t)
+	for _, langkah := range []int64{sekarang - 1, sekarang, sekarang + 1} {
+		expected, err := KodeTOTP(secret, langkah)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(kode)) {
+			return langkah, true
+		}
+	}
+	return 0, false
+}
+
+// URIOtpauth menyusun URI otpauth:// untuk didaftarkan ke aplikasi authenticator
+// (biasanya ditampilkan sebagai QR code)
+func URIOtpauth(issuer, akun, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigit))
+	params.Set("period", fmt.Sprint(totpPeriode))
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+akun) + "?" + params.Encode()
+}
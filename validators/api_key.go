@@ -0,0 +1,26 @@
+package validators
+
+import (
+	"errors"
+	"inventory-backend/models"
+	"strings"
+	"time"
+)
+
+// ValidateAPIKey memvalidasi nama, scope dan waktu kedaluwarsa API key baru
+func ValidateAPIKey(req models.CreateAPIKeyRequest) error {
+	nama := strings.TrimSpace(req.Nama)
+	if nama == "" || len(nama) > 50 {
+		return errors.New("Nama API key wajib diisi, maksimal 50 karakter")
+	}
+	if len(req.Scopes) == 0 {
+		return errors.New("Minimal satu scope wajib diisi")
+	}
+	if err := ValidatePermissions(req.Scopes); err != nil {
+		return err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at harus di masa depan")
+	}
+	return nil
+}
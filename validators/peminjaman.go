@@ -2,13 +2,17 @@ package validators
 
 import (
 	"errors"
+	"fmt"
+	"inventory-backend/models"
 	"regexp"
 	"strings"
+	"time"
 )
 
+// ValidateEmail memvalidasi email peminjam dengan emailRegex yang sama seperti
+// email akun, jadi email akun yang memakai huruf besar tetap diterima
 func ValidateEmail(email string) error {
-	re := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
-	if !re.MatchString(email) {
+	if !emailRegex.MatchString(email) {
 		return errors.New("format email tidak valid")
 	}
 	return nil
@@ -22,22 +26,77 @@ func ValidateTelepon(telp string) error {
 	return nil
 }
 
-func ValidatePeminjaman(nama string, email string, telp string, jumlah int, status string) error {
+// ValidatePeminjaman memvalidasi data peminjam dan item. Telepon hanya dicek
+// formatnya jika diisi; kewajiban telepon untuk peminjam tamu dicek controller.
+func ValidatePeminjaman(nama string, email string, telp string, items []models.ItemPeminjaman) error {
 	if strings.TrimSpace(nama) == "" {
 		return errors.New("nama peminjam wajib diisi")
 	}
 	if err := ValidateEmail(email); err != nil {
 		return err
 	}
-	if err := ValidateTelepon(telp); err != nil {
-		return err
+	if telp != "" {
+		if err := ValidateTelepon(telp); err != nil {
+			return err
+		}
+	}
+	if len(items) == 0 {
+		return errors.New("minimal satu barang harus dipinjam")
+	}
+	for _, item := range items {
+		if item.BarangID.IsZero() {
+			return errors.New("barang_id wajib diisi untuk setiap item")
+		}
+		if item.Jumlah <= 0 {
+			return errors.New("jumlah pinjam harus lebih dari 0")
+		}
+	}
+	return nil
+}
+
+// ValidateKondisi memastikan kondisi pengembalian salah satu dari baik,
+// rusak_ringan, rusak_berat atau hilang
+func ValidateKondisi(kondisi string) error {
+	switch kondisi {
+	case models.KondisiBaik, models.KondisiRusakRingan, models.KondisiRusakBerat, models.KondisiHilang:
+		return nil
+	}
+	return errors.New("kondisi harus 'baik', 'rusak_ringan', 'rusak_berat' atau 'hilang'")
+}
+
+// TransisiStatus adalah tabel perpindahan status peminjaman yang diizinkan.
+// Status yang tidak punya tujuan (ditolak, dikembalikan, dibatalkan) adalah status akhir.
+var TransisiStatus = map[string][]string{
+	models.StatusDiajukan:  {models.StatusDisetujui, models.StatusDitolak, models.StatusDibatalkan},
+	models.StatusDisetujui: {models.StatusDipinjam, models.StatusDibatalkan},
+	models.StatusDipinjam:  {models.StatusDikembalikan},
+}
+
+// ValidateTransisiStatus memastikan perpindahan status ada di TransisiStatus
+func ValidateTransisiStatus(dari, ke string) error {
+	for _, tujuan := range TransisiStatus[dari] {
+		if tujuan == ke {
+			return nil
+		}
+	}
+	if len(TransisiStatus[dari]) == 0 {
+		return fmt.Errorf("peminjaman berstatus '%s' tidak bisa diubah lagi", dari)
+	}
+	return fmt.Errorf("status tidak bisa diubah dari '%s' ke '%s'", dari, ke)
+}
+
+// ValidateJatuhTempo memastikan tanggal jatuh tempo diisi dengan format YYYY-MM-DD
+// dan tidak sebelum hari ini
+func ValidateJatuhTempo(tanggal string) error {
+	if strings.TrimSpace(tanggal) == "" {
+		return errors.New("tanggal jatuh tempo wajib diisi")
 	}
-	if jumlah <= 0 {
-		return errors.New("jumlah pinjam harus lebih dari 0")
+	jatuhTempo, err := time.ParseInLocation(models.FormatTanggal, tanggal, time.Local)
+	if err != nil {
+		return errors.New("format tanggal jatuh tempo harus YYYY-MM-DD")
 	}
-	// Hapus validasi status 'pending', hanya izinkan 'dipinjam' dan 'dikembalikan'
-	if status != "dipinjam" && status != "dikembalikan" {
-		return errors.New("status harus 'dipinjam' atau 'dikembalikan'")
+	if jatuhTempo.Format(models.FormatTanggal) < time.Now().Format(models.FormatTanggal) {
+		return errors.New("tanggal jatuh tempo tidak boleh sebelum hari ini")
 	}
 	return nil
 }
@@ -0,0 +1,33 @@
+package validators
+
+import (
+	"inventory-backend/models"
+	"testing"
+)
+
+func TestValidateTransisiStatus(t *testing.T) {
+	semua := []string{
+		models.StatusDiajukan,
+		models.StatusDisetujui,
+		models.StatusDitolak,
+		models.StatusDipinjam,
+		models.StatusDikembalikan,
+		models.StatusDibatalkan,
+	}
+	boleh := map[[2]string]bool{
+		{models.StatusDiajukan, models.StatusDisetujui}:    true,
+		{models.StatusDiajukan, models.StatusDitolak}:      true,
+		{models.StatusDiajukan, models.StatusDibatalkan}:   true,
+		{models.StatusDisetujui, models.StatusDipinjam}:    true,
+		{models.StatusDisetujui, models.StatusDibatalkan}:  true,
+		{models.StatusDipinjam, models.StatusDikembalikan}: true,
+	}
+	for _, dari := range semua {
+		for _, ke := range semua {
+			err := ValidateTransisiStatus(dari, ke)
+			if boleh[[2]string{dari, ke}] != (err == nil) {
+				t.Errorf("ValidateTransisiStatus(%s, %s) = %v, ingin boleh %v", dari, ke, err, boleh[[2]string{dari, ke}])
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package validators
+
+import (
+	"errors"
+	"inventory-backend/models"
+	"regexp"
+)
+
+// namaRoleRegex membatasi nama role ke huruf kecil, angka dan underscore
+var namaRoleRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{1,29}$`)
+
+// ValidateRole memvalidasi nama role dan memastikan semua permission dikenal
+func ValidateRole(nama string, permissions []string) error {
+	if !namaRoleRegex.MatchString(nama) {
+		return errors.New("Nama role harus 2-30 karakter huruf kecil, angka atau underscore dan diawali huruf")
+	}
+	return ValidatePermissions(permissions)
+}
+
+// ValidatePermissions memastikan semua permission ada di models.SemuaPermission
+func ValidatePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if _, ok := models.SemuaPermission[permission]; !ok {
+			return errors.New("Permission tidak dikenal: " + permission)
+		}
+	}
+	return nil
+}
@@ -1,49 +1,74 @@
 package validators
 
 import (
+	"errors"
 	"inventory-backend/models"
 	"regexp"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func ValidateRegister(c *fiber.Ctx) error {
-	var user models.RegisterRequest
+// emailRegex dipakai untuk validasi format email
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 
-	if err := c.BodyParser(&user); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+// ValidateUser memvalidasi data akun yang dipakai saat registrasi dan saat admin membuat user
+func ValidateUser(username, email, password string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+	if err := ValidateEmailAkun(email); err != nil {
+		return err
+	}
+	return ValidatePassword(password)
+}
+
+// ValidateUsername memastikan panjang username 2-50 karakter
+func ValidateUsername(username string) error {
+	if len(username) < 2 || len(username) > 50 {
+		return errors.New("Username harus antara 2-50 karakter")
 	}
+	return nil
+}
 
-	// Validate username
-	if len(user.Username) < 2 || len(user.Username) > 50 {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Username harus antara 2-50 karakter",
-		})
+// ValidateEmailAkun memvalidasi format email akun
+func ValidateEmailAkun(email string) error {
+	if !emailRegex.MatchString(email) {
+		return errors.New("Format email tidak valid")
 	}
+	return nil
+}
 
-	// Validate email format
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(user.Email) {
+// ValidatePassword memvalidasi password baru, dipakai juga saat reset dan ganti password
+func ValidatePassword(password string) error {
+	if len(password) < 6 {
+		return errors.New("Password minimal 6 karakter")
+	}
+	return nil
+}
+
+func ValidateRegister(c *fiber.Ctx) error {
+	var user models.RegisterRequest
+
+	if err := c.BodyParser(&user); err != nil {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "Format email tidak valid",
+			"error": "Invalid request body",
 		})
 	}
 
-	// Validate password
-	if len(user.Password) < 6 {
+	if err := ValidateUser(user.Username, user.Email, user.Password); err != nil {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "Password minimal 6 karakter",
+			"error": err.Error(),
 		})
 	}
 
-	// Validate role
-	if user.Role != "admin" && user.Role != "user" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Role hanya boleh 'admin' atau 'user'",
+	// Registrasi publik selalu membuat akun user biasa
+	if user.Role != "" && user.Role != models.RoleUser {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Registrasi publik hanya bisa membuat akun dengan role 'user'",
 		})
 	}
+	user.Role = models.RoleUser
 
 	// Store validated data in context
 	c.Locals("userData", user)
@@ -60,7 +85,6 @@ func ValidateLogin(c *fiber.Ctx) error {
 	}
 
 	// Validate email format
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	if !emailRegex.MatchString(loginReq.Email) {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Format email tidak valid",
@@ -78,3 +102,26 @@ func ValidateLogin(c *fiber.Ctx) error {
 	c.Locals("loginData", loginReq)
 	return c.Next()
 }
+
+// ValidateProfil memvalidasi field profile yang dikirim; field nil tidak divalidasi
+func ValidateProfil(req models.UpdateProfileRequest) error {
+	if req.Username != nil {
+		if err := ValidateUsername(strings.TrimSpace(*req.Username)); err != nil {
+			return err
+		}
+	}
+	if req.Email != nil {
+		if err := ValidateEmailAkun(strings.TrimSpace(*req.Email)); err != nil {
+			return err
+		}
+	}
+	if req.NamaLengkap != nil && len(*req.NamaLengkap) > 100 {
+		return errors.New("Nama lengkap maksimal 100 karakter")
+	}
+	if req.Telepon != nil && *req.Telepon != "" {
+		if err := ValidateTelepon(*req.Telepon); err != nil {
+			return err
+		}
+	}
+	return nil
+}